@@ -6,6 +6,8 @@ import (
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/pricing"
+	"github.com/aws/aws-sdk-go-v2/service/route53"
 	"github.com/felipemarinho97/dev-spaces/cli/commands"
 	"github.com/felipemarinho97/dev-spaces/cli/config"
 	"github.com/felipemarinho97/dev-spaces/cli/log"
@@ -36,6 +38,12 @@ func GetCLI() *cli.App {
 				Usage:   "AWS region",
 				EnvVars: []string{"AWS_REGION"},
 			},
+			&cli.StringFlag{
+				Name:    "output",
+				Aliases: []string{"o"},
+				Value:   "table",
+				Usage:   "Output format: table, json or yaml",
+			},
 		},
 		EnableBashCompletion: true,
 		Usage:                "CLI to help dev-spaces creation and management",
@@ -54,10 +62,11 @@ func GetCLI() *cli.App {
 		{
 			Name:        "start",
 			Description: "Starts the dev environment by placing a spot request.",
-			Usage:       "-n <name> [-c <min-cpus> -m <min-memory> --max-price <max-price> -t <timeout> --wait]",
+			Usage:       "-n <name> [-c <min-cpus> -m <min-memory> --max-price <max-price> -t <timeout> --capacity <capacity> --fallback-after <duration> --wait --ignore-budget -o <output>]",
 			Category:    LIFECYCLE,
 			Action:      commands.StartCommand,
 			Flags: []cli.Flag{
+				outputFlag(),
 				&cli.StringFlag{
 					Name:     "name",
 					Required: true,
@@ -85,22 +94,125 @@ func GetCLI() *cli.App {
 					Name:    "timeout",
 					Aliases: []string{"t"},
 					Value:   time.Hour * 1,
-					Usage:   "Timeout for the spot request",
+					Usage:   "Timeout for the spot request and for the instance to be running",
+				},
+				&cli.StringFlag{
+					Name:  "capacity",
+					Value: "spot",
+					Usage: "Capacity to request: spot, spot-then-on-demand or on-demand",
+				},
+				&cli.DurationFlag{
+					Name:  "fallback-after",
+					Value: time.Minute * 5,
+					Usage: "Time to wait for spot capacity before falling back to on-demand (with --capacity spot-then-on-demand)",
 				},
 				&cli.BoolFlag{
 					Name:  "wait",
 					Value: false,
 					Usage: "Wait for DevSpace instance to be ready for SSH",
 				},
+				&cli.BoolFlag{
+					Name:  "ignore-budget",
+					Value: false,
+					Usage: "Start even if the budgets from the config could be exceeded",
+				},
+			},
+		},
+		{
+			Name:        "price",
+			Description: "Shows the instance types that start would request for the given requirements, ranked by their current spot price in the dev space zone, compared with on-demand.",
+			Usage:       "-n <name> [-c <min-cpus> -m <min-memory> --max-price <max-price> -l <limit> --no-on-demand -o <output>]",
+			Category:    LIFECYCLE,
+			Action:      commands.PriceCommand,
+			Flags: []cli.Flag{
+				outputFlag(),
+				&cli.StringFlag{
+					Name:     "name",
+					Aliases:  []string{"n"},
+					Usage:    "The name of the dev-space",
+					Required: true,
+				},
+				&cli.IntFlag{
+					Name:    "min-cpus",
+					Aliases: []string{"c"},
+					Value:   0,
+					Usage:   "Minimum number of CPUs",
+				},
+				&cli.Float64Flag{
+					Name:    "min-memory",
+					Aliases: []string{"m"},
+					Value:   0,
+					Usage:   "Minimum amount of memory in GB",
+				},
+				&cli.StringFlag{
+					Name:  "max-price",
+					Value: "0.50",
+					Usage: "Maximum price per hour for the spot request",
+				},
+				&cli.IntFlag{
+					Name:    "limit",
+					Aliases: []string{"l"},
+					Value:   20,
+					Usage:   "Maximum number of instance types to show (0 shows all)",
+				},
+				&cli.BoolFlag{
+					Name:  "no-on-demand",
+					Value: false,
+					Usage: "Skip the on-demand price comparison (uses the AWS Price List API)",
+				},
+			},
+		},
+		{
+			Name:        "cost",
+			Description: "Reports what each dev space cost over a period: instance hours (from the fleet requests and the spot price history) and volume and snapshot storage.",
+			Usage:       "[-n <name> -s <since> --from <YYYY-MM-DD> --to <YYYY-MM-DD> -o <output>]",
+			Category:    LIFECYCLE,
+			Action:      commands.CostCommand,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:    "output",
+					Aliases: []string{"o"},
+					Usage:   "Output format: table, csv, json or yaml (defaults to the global --output)",
+				},
+				&cli.StringFlag{
+					Name:    "name",
+					Aliases: []string{"n"},
+					Usage:   "The name of the dev-space (all dev-spaces if omitted)",
+				},
+				&cli.DurationFlag{
+					Name:    "since",
+					Aliases: []string{"s"},
+					Value:   30 * 24 * time.Hour,
+					Usage:   "The length of the period, ending now",
+				},
+				&cli.StringFlag{
+					Name:  "from",
+					Usage: "The first day of the period (overrides --since)",
+				},
+				&cli.StringFlag{
+					Name:  "to",
+					Usage: "The last day of the period (defaults to now)",
+				},
+				&cli.Float64Flag{
+					Name:  "volume-price",
+					Value: core.DefaultVolumePrice,
+					Usage: "The volume storage price in USD per GB-month",
+				},
+				&cli.Float64Flag{
+					Name:  "snapshot-price",
+					Value: core.DefaultSnapshotPrice,
+					Usage: "The snapshot storage price in USD per GB-month",
+				},
 			},
 		},
 		{
 			Name:        "stop",
 			Description: "Stops the dev environment by canceling the spot request.",
-			Usage:       "[-n <name>]",
+			Usage:       "[-n <name> -o <output>]",
 			Category:    LIFECYCLE,
 			Action:      commands.StopCommand,
 			Flags: []cli.Flag{
+				outputFlag(),
 				&cli.StringFlag{
 					Name:    "name",
 					Aliases: []string{"n"},
@@ -108,13 +220,186 @@ func GetCLI() *cli.App {
 				},
 			},
 		},
+		{
+			Name:        "ssh",
+			Description: "Opens an interactive SSH session on the running dev space (port 2222), or on its host (port 22) with --host.",
+			Usage:       "-n <name> [-i <identity-file> -l <user> --host]",
+			Category:    LIFECYCLE,
+			Action:      commands.SSHCommand,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:     "name",
+					Aliases:  []string{"n"},
+					Usage:    "The name of the dev-space",
+					Required: true,
+				},
+				&cli.StringFlag{
+					Name:    "identity-file",
+					Aliases: []string{"i"},
+					Usage:   "The path to the SSH identity file (defaults to identity_file from config)",
+				},
+				&cli.StringFlag{
+					Name:    "user",
+					Aliases: []string{"l"},
+					Value:   "root",
+					Usage:   "The user to log in as on the dev-space",
+				},
+				&cli.BoolFlag{
+					Name:  "host",
+					Value: false,
+					Usage: "Connect to the host on port 22 instead of the dev-space",
+				},
+			},
+		},
+		{
+			Name:        "exec",
+			Description: "Runs a command on the running dev space, streaming its output and exiting with its exit status.",
+			Usage:       "-n <name> [-i <identity-file> -l <user> -e <KEY=VALUE> --host] -- <cmd>",
+			Category:    LIFECYCLE,
+			Action:      commands.ExecCommand,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:     "name",
+					Aliases:  []string{"n"},
+					Usage:    "The name of the dev-space",
+					Required: true,
+				},
+				&cli.StringFlag{
+					Name:    "identity-file",
+					Aliases: []string{"i"},
+					Usage:   "The path to the SSH identity file (defaults to identity_file from config)",
+				},
+				&cli.StringFlag{
+					Name:    "user",
+					Aliases: []string{"l"},
+					Value:   "root",
+					Usage:   "The user to run the command as on the dev-space",
+				},
+				&cli.StringSliceFlag{
+					Name:    "env",
+					Aliases: []string{"e"},
+					Usage:   "Environment variables to set for the command. e.g. -e FOO=bar -e BAZ=qux",
+				},
+				&cli.BoolFlag{
+					Name:  "host",
+					Value: false,
+					Usage: "Run the command on the host on port 22 instead of the dev-space",
+				},
+			},
+		},
+		{
+			Name:        "logs",
+			Description: "Shows the log of the startup script of the dev space, or of its bootstrap task. The log is read from the console output until SSH is up, and over SSH afterwards.",
+			Usage:       "-n <name> [-f --bootstrap -i <identity-file>]",
+			Category:    LIFECYCLE,
+			Action:      commands.LogsCommand,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:     "name",
+					Aliases:  []string{"n"},
+					Usage:    "The name of the dev-space",
+					Required: true,
+				},
+				&cli.BoolFlag{
+					Name:    "follow",
+					Aliases: []string{"f"},
+					Value:   false,
+					Usage:   "Keep streaming the log until the instance stops",
+				},
+				&cli.BoolFlag{
+					Name:  "bootstrap",
+					Value: false,
+					Usage: "Show the log of the bootstrap task instead of the startup script",
+				},
+				&cli.StringFlag{
+					Name:    "identity-file",
+					Aliases: []string{"i"},
+					Usage:   "The path to the SSH identity file (defaults to identity_file from config)",
+				},
+			},
+		},
+		{
+			Name:        "forward",
+			Description: "Forwards local ports to the running dev space, reconnecting when its IP changes after a restart.",
+			Usage:       "-n <name> -L <[bind_address:]port:host:hostport> [-L ...] [-i <identity-file> -l <user> --host]",
+			Category:    LIFECYCLE,
+			Action:      commands.ForwardCommand,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:     "name",
+					Aliases:  []string{"n"},
+					Usage:    "The name of the dev-space",
+					Required: true,
+				},
+				&cli.StringSliceFlag{
+					Name:     "local",
+					Aliases:  []string{"L"},
+					Usage:    "The port to forward. e.g. -L 8080:localhost:8080 -L 127.0.0.1:5432:localhost:5432",
+					Required: true,
+				},
+				&cli.StringFlag{
+					Name:    "identity-file",
+					Aliases: []string{"i"},
+					Usage:   "The path to the SSH identity file (defaults to identity_file from config)",
+				},
+				&cli.StringFlag{
+					Name:    "user",
+					Aliases: []string{"l"},
+					Value:   "root",
+					Usage:   "The user to connect as on the dev-space",
+				},
+				&cli.BoolFlag{
+					Name:  "host",
+					Value: false,
+					Usage: "Forward through the host on port 22 instead of the dev-space",
+				},
+				&cli.DurationFlag{
+					Name:  "check-interval",
+					Value: 30 * time.Second,
+					Usage: "The interval to check if the dev-space IP changed",
+				},
+			},
+		},
+		{
+			Name:        "cp",
+			Description: "Copies files between the local machine and the running dev space over SFTP. Dev space paths are written as <name>:<path>.",
+			Usage:       "[-r -i <identity-file> -l <user> --host -o <output>] <source> <destination>",
+			Category:    LIFECYCLE,
+			Action:      commands.CpCommand,
+			Flags: []cli.Flag{
+				outputFlag(),
+				&cli.BoolFlag{
+					Name:    "recursive",
+					Aliases: []string{"r"},
+					Value:   false,
+					Usage:   "Copy directories recursively",
+				},
+				&cli.StringFlag{
+					Name:    "identity-file",
+					Aliases: []string{"i"},
+					Usage:   "The path to the SSH identity file (defaults to identity_file from config)",
+				},
+				&cli.StringFlag{
+					Name:    "user",
+					Aliases: []string{"l"},
+					Value:   "root",
+					Usage:   "The user to copy the files as on the dev-space",
+				},
+				&cli.BoolFlag{
+					Name:  "host",
+					Value: false,
+					Usage: "Copy to or from the host on port 22 instead of the dev-space",
+				},
+			},
+		},
 		{
 			Name:        "status",
 			Description: "Shows the status of the most recent dev-space requests.",
-			Usage:       "[-n <name>]",
+			Usage:       "[-n <name> -o <output>]",
 			Category:    LIFECYCLE,
 			Action:      commands.StatusCommand,
 			Flags: []cli.Flag{
+				outputFlag(),
 				&cli.StringFlag{
 					Name:    "name",
 					Aliases: []string{"n"},
@@ -122,6 +407,253 @@ func GetCLI() *cli.App {
 				},
 			},
 		},
+		{
+			Name:        "watch",
+			Description: "Watches the dev spaces, relaunching them with the same specs when their spot instance is interrupted and stopping them when they are idle.",
+			Usage:       "[-n <name> --interval <interval> --identity-file <identity-file> -o <output>]",
+			Category:    LIFECYCLE,
+			Action:      commands.WatchCommand,
+			Flags: []cli.Flag{
+				outputFlag(),
+				&cli.StringFlag{
+					Name:    "name",
+					Aliases: []string{"n"},
+					Usage:   "The name of the dev-space (all dev-spaces if omitted)",
+				},
+				&cli.DurationFlag{
+					Name:  "interval",
+					Value: time.Second * 30,
+					Usage: "Interval between interruption checks",
+				},
+				&cli.StringFlag{
+					Name:  "identity-file",
+					Usage: "The path to the SSH identity file used to check for idle dev-spaces (defaults to idle.identity_file from config)",
+				},
+			},
+		},
+		{
+			Name:        "schedule",
+			Description: "Manage the working hours of the dev spaces. The run sub-command starts and stops the dev spaces when their windows open and close.",
+			Category:    LIFECYCLE,
+			Subcommands: []*cli.Command{
+				{
+					Name:        "add",
+					Description: "Add a start/stop window to the dev space",
+					Action:      commands.ScheduleAddCommand,
+					Flags: []cli.Flag{
+						outputFlag(),
+						&cli.StringFlag{
+							Name:     "name",
+							Aliases:  []string{"n"},
+							Usage:    "The name of the dev-space",
+							Required: true,
+						},
+						&cli.StringFlag{
+							Name:     "days",
+							Aliases:  []string{"d"},
+							Usage:    "Days of the week the window opens. e.g. \"mon-fri\" or \"mon,wed,fri\"",
+							Required: true,
+						},
+						&cli.StringFlag{
+							Name:     "start",
+							Usage:    "Time of the day (HH:MM) the dev-space is started",
+							Required: true,
+						},
+						&cli.StringFlag{
+							Name:     "stop",
+							Usage:    "Time of the day (HH:MM) the dev-space is stopped",
+							Required: true,
+						},
+						&cli.StringFlag{
+							Name:  "tz",
+							Usage: "Time zone of the window, e.g. \"America/Sao_Paulo\" (defaults to the local time zone)",
+						},
+						&cli.IntFlag{
+							Name:    "min-cpus",
+							Aliases: []string{"c"},
+							Value:   0,
+							Usage:   "Minimum number of CPUs",
+						},
+						&cli.Float64Flag{
+							Name:    "min-memory",
+							Aliases: []string{"m"},
+							Value:   0,
+							Usage:   "Minimum amount of memory in GB",
+						},
+						&cli.StringFlag{
+							Name:  "max-price",
+							Value: "0.50",
+							Usage: "Maximum price per hour for the spot request",
+						},
+					},
+					Usage: "-n <name> -d <days> --start <HH:MM> --stop <HH:MM> [--tz <time-zone> -c <min-cpus> -m <min-memory> --max-price <max-price> -o <output>]",
+				},
+				{
+					Name:        "list",
+					Description: "List the start/stop windows of the dev spaces",
+					Action:      commands.ScheduleListCommand,
+					Flags: []cli.Flag{
+						outputFlag(),
+						&cli.StringFlag{
+							Name:    "name",
+							Aliases: []string{"n"},
+							Usage:   "The name of the dev-space",
+						},
+					},
+					Usage: "[-n <name> -o <output>]",
+				},
+				{
+					Name:        "remove",
+					Description: "Remove a start/stop window from the dev space",
+					Action:      commands.ScheduleRemoveCommand,
+					Flags: []cli.Flag{
+						&cli.StringFlag{
+							Name:     "name",
+							Aliases:  []string{"n"},
+							Usage:    "The name of the dev-space",
+							Required: true,
+						},
+						&cli.IntFlag{
+							Name:     "id",
+							Usage:    "The ID of the window, see schedule list",
+							Required: true,
+						},
+					},
+					Usage: "-n <name> --id <id>",
+				},
+				{
+					Name:        "run",
+					Description: "Start and stop the dev spaces when their windows open and close",
+					Action:      commands.ScheduleRunCommand,
+					Flags: []cli.Flag{
+						outputFlag(),
+						&cli.StringFlag{
+							Name:    "name",
+							Aliases: []string{"n"},
+							Usage:   "The name of the dev-space (all dev-spaces if omitted)",
+						},
+						&cli.DurationFlag{
+							Name:    "interval",
+							Aliases: []string{"i"},
+							Value:   time.Minute,
+							Usage:   "Interval between schedule checks",
+						},
+					},
+					Usage: "[-n <name> -i <interval> -o <output>]",
+				},
+			},
+		},
+		{
+			Name:        "backup",
+			Description: "Manage the backups (snapshots) of the dev space volumes.",
+			Category:    ADM,
+			Subcommands: []*cli.Command{
+				{
+					Name:        "create",
+					Description: "Back up the volume of the dev space",
+					Action:      commands.BackupCreateCommand,
+					Flags: []cli.Flag{
+						outputFlag(),
+						&cli.StringFlag{
+							Name:     "name",
+							Aliases:  []string{"n"},
+							Usage:    "The name of the dev-space",
+							Required: true,
+						},
+						&cli.StringFlag{
+							Name:    "description",
+							Aliases: []string{"d"},
+							Usage:   "A description of the backup",
+						},
+						&cli.BoolFlag{
+							Name:  "wait",
+							Usage: "Wait for the backup to complete",
+						},
+					},
+					Usage: "-n <name> [-d <description> --wait -o <output>]",
+				},
+				{
+					Name:        "list",
+					Description: "List the backups, newest first",
+					Action:      commands.BackupListCommand,
+					Flags: []cli.Flag{
+						outputFlag(),
+						&cli.StringFlag{
+							Name:    "name",
+							Aliases: []string{"n"},
+							Usage:   "The name of the dev-space (all dev-spaces if omitted)",
+						},
+					},
+					Usage: "[-n <name> -o <output>]",
+				},
+				{
+					Name:        "restore",
+					Description: "Replace the volume of a stopped dev space with a volume created from a backup",
+					Action:      commands.BackupRestoreCommand,
+					Flags: []cli.Flag{
+						outputFlag(),
+						&cli.StringFlag{
+							Name:     "name",
+							Aliases:  []string{"n"},
+							Usage:    "The name of the dev-space",
+							Required: true,
+						},
+						&cli.StringFlag{
+							Name:     "snapshot-id",
+							Aliases:  []string{"s"},
+							Usage:    "The snapshot ID of the backup, see backup list",
+							Required: true,
+						},
+						&cli.BoolFlag{
+							Name:  "delete-old-volume",
+							Usage: "Delete the replaced volume (it is kept until the dev-space is destroyed otherwise)",
+						},
+					},
+					Usage: "-n <name> -s <snapshot-id> [--delete-old-volume -o <output>]",
+				},
+				{
+					Name:        "prune",
+					Description: "Delete all but the most recent backups of the dev space",
+					Action:      commands.BackupPruneCommand,
+					Flags: []cli.Flag{
+						outputFlag(),
+						&cli.StringFlag{
+							Name:     "name",
+							Aliases:  []string{"n"},
+							Usage:    "The name of the dev-space",
+							Required: true,
+						},
+						&cli.IntFlag{
+							Name:     "keep",
+							Aliases:  []string{"k"},
+							Usage:    "The number of backups to keep",
+							Required: true,
+						},
+					},
+					Usage: "-n <name> -k <keep> [-o <output>]",
+				},
+				{
+					Name:        "policy",
+					Description: "Back up the dev space on every stop, keeping its last backups",
+					Action:      commands.BackupPolicyCommand,
+					Flags: []cli.Flag{
+						&cli.StringFlag{
+							Name:     "name",
+							Aliases:  []string{"n"},
+							Usage:    "The name of the dev-space",
+							Required: true,
+						},
+						&cli.IntFlag{
+							Name:     "keep",
+							Aliases:  []string{"k"},
+							Usage:    "The number of backups to keep (0 disables the policy)",
+							Required: true,
+						},
+					},
+					Usage: "-n <name> -k <keep>",
+				},
+			},
+		},
 		{
 			Name:        "create",
 			Description: "Create a the dev space environment automatically.",
@@ -129,6 +661,7 @@ func GetCLI() *cli.App {
 			Category: ADM,
 			Action:   commands.CreateCommand,
 			Flags: []cli.Flag{
+				outputFlag(),
 				&cli.StringFlag{
 					Name:     "name",
 					Aliases:  []string{"n"},
@@ -181,7 +714,104 @@ func GetCLI() *cli.App {
 					Usage: "A list of security group IDs to use. e.g. --security-group-ids sg-123456789 sg-987654321",
 				},
 			},
-			Usage: "-n <name> -k <key-name> -i <ami> [-p <instance-profile-arn> -s <storage-size> -t <prefered-instance-type>]",
+			Usage: "-n <name> -k <key-name> -i <ami> [-p <instance-profile-arn> -s <storage-size> -t <prefered-instance-type> -o <output>]",
+		},
+		{
+			Name:        "apply",
+			Description: "Create or update a dev space from a spec file, creating a new launch template version when the spec changed.",
+			Category:    ADM,
+			Action:      commands.ApplyCommand,
+			Flags: []cli.Flag{
+				outputFlag(),
+				&cli.StringFlag{
+					Name:     "file",
+					Aliases:  []string{"f"},
+					Usage:    "The path or URL of the dev space spec",
+					Required: true,
+				},
+				&cli.BoolFlag{
+					Name:  "dry-run",
+					Usage: "Print the changes without applying them",
+				},
+				&cli.StringFlag{
+					Name:    "identity-file",
+					Aliases: []string{"i"},
+					Usage:   "The SSH key used to grow the filesystem of a running dev space (defaults to the identity_file of the config)",
+				},
+			},
+			Usage: "-f <file> [--dry-run -i <identity-file> -o <output>]",
+		},
+		{
+			Name:        "bootstrap",
+			Description: "Bootstrap a dev space from a template, running its bootstrap script on a new volume.",
+			Category:    ADM,
+			Action:      commands.BootstrapCommand,
+			Flags: []cli.Flag{
+				outputFlag(),
+				&cli.StringFlag{
+					Name:    "template",
+					Aliases: []string{"t"},
+					// not required, so that it is not required by the render subcommand
+					Usage: "The path or URL of the bootstrap template, or builtin:<name> for a built-in template (see templates list)",
+				},
+				&cli.StringFlag{
+					Name:    "name",
+					Aliases: []string{"n"},
+					Usage:   "The name of the dev-space (defaults to the template_name of the template)",
+				},
+				&cli.StringFlag{
+					Name:    "key-name",
+					Aliases: []string{"k"},
+					Usage:   "The name of the key pair (defaults to the key_name of the template)",
+				},
+				&cli.StringFlag{
+					Name:  "arch",
+					Usage: "The architecture of the dev-space: x86_64 or arm64 (defaults to the one of the template, or x86_64)",
+				},
+				templateSetFlag(),
+				templateEnvFileFlag(),
+			},
+			Usage: "-t <template> [-n <name> -k <key-name> --arch <arch> --set <KEY=VALUE> --env-file <env-file> -o <output>]",
+			Subcommands: []*cli.Command{
+				{
+					Name:        "render",
+					Description: "Print the bootstrap template rendered with its variables, without touching AWS",
+					Action:      commands.BootstrapRenderCommand,
+					Flags: []cli.Flag{
+						&cli.StringFlag{
+							Name:     "template",
+							Aliases:  []string{"t"},
+							Usage:    "The path or URL of the bootstrap template, or builtin:<name> for a built-in template",
+							Required: true,
+						},
+						templateSetFlag(),
+						templateEnvFileFlag(),
+					},
+					Usage: "-t <template> [--set <KEY=VALUE> --env-file <env-file>]",
+				},
+			},
+		},
+		{
+			Name:        "templates",
+			Description: "Inspect the built-in bootstrap templates.",
+			Category:    ADM,
+			Subcommands: []*cli.Command{
+				{
+					Name:        "list",
+					Description: "List the built-in bootstrap templates",
+					Action:      commands.TemplatesListCommand,
+					Flags: []cli.Flag{
+						outputFlag(),
+					},
+					Usage: "[-o <output>]",
+				},
+				{
+					Name:        "show",
+					Description: "Print a built-in bootstrap template, to save and customize it",
+					Action:      commands.TemplatesShowCommand,
+					Usage:       "<name>",
+				},
+			},
 		},
 		{
 			Name:        "list",
@@ -191,9 +821,8 @@ func GetCLI() *cli.App {
 			Flags: []cli.Flag{
 				&cli.StringFlag{
 					Name:    "output",
-					Usage:   "Output format: short or wide",
+					Usage:   "Output format: short, wide, json or yaml (defaults to the global --output)",
 					Aliases: []string{"o"},
-					Value:   "short",
 				},
 			},
 			Usage: "[-o <output>]",
@@ -224,6 +853,7 @@ func GetCLI() *cli.App {
 					Description: "Scale-up or scale-down specifications of the dev space",
 					Action:      commands.EditSpecCommand,
 					Flags: []cli.Flag{
+						outputFlag(),
 						&cli.StringFlag{
 							Name:     "name",
 							Aliases:  []string{"n"},
@@ -253,14 +883,115 @@ func GetCLI() *cli.App {
 							Usage: "The max price to use for the instance",
 							Value: "0.5",
 						},
+						&cli.BoolFlag{
+							Name:  "ignore-budget",
+							Value: false,
+							Usage: "Scale even if the budgets from the config could be exceeded",
+						},
+						&cli.DurationFlag{
+							Name:    "timeout",
+							Aliases: []string{"t"},
+							Value:   15 * time.Minute,
+							Usage:   "Timeout for the current and the new instance to be running",
+						},
+					},
+					Usage: "-n <name> -i <identity-file> [-c <min-cpus> -m <min-memory> -p <max-price> -t <timeout> --ignore-budget -o <output>]",
+				},
+				{
+					Name:        "resize",
+					Description: "Grow the volume of a dev space. If the dev space is running, the partition and filesystem are grown over SSH.",
+					Action:      commands.ResizeCommand,
+					Flags: []cli.Flag{
+						outputFlag(),
+						&cli.StringFlag{
+							Name:     "name",
+							Aliases:  []string{"n"},
+							Usage:    "The name of the dev-space",
+							Required: true,
+						},
+						&cli.IntFlag{
+							Name:     "storage-size",
+							Aliases:  []string{"s"},
+							Usage:    "The new size of the volume in GB",
+							Required: true,
+						},
+						&cli.StringFlag{
+							Name:    "identity-file",
+							Aliases: []string{"i"},
+							Usage:   "The path to the SSH identity file, needed when the dev-space is running (defaults to identity_file from config)",
+						},
+					},
+					Usage: "-n <name> -s <size-in-GB> [-i <identity-file> -o <output>]",
+				},
+				{
+					Name:        "idle",
+					Description: "Set the idle policy of the dev space. The watch command stops the dev space after it is idle for the given time.",
+					Action:      commands.IdlePolicyCommand,
+					Flags: []cli.Flag{
+						&cli.StringFlag{
+							Name:     "name",
+							Aliases:  []string{"n"},
+							Usage:    "The name of the dev-space",
+							Required: true,
+						},
+						&cli.DurationFlag{
+							Name:    "timeout",
+							Aliases: []string{"t"},
+							Usage:   "Time without SSH input before the dev-space is stopped",
+							Value:   time.Hour,
+						},
+						&cli.Float64Flag{
+							Name:  "max-load",
+							Usage: "The 1-minute load average under which the dev-space is considered idle",
+							Value: core.DefaultIdleMaxLoad,
+						},
+						&cli.BoolFlag{
+							Name:  "disable",
+							Usage: "Remove the idle policy of the dev-space",
+						},
 					},
-					Usage: "-n <name> -i <identity-file> [-c <min-cpus> -m <min-memory> -p <max-price>]",
+					Usage: "-n <name> [-t <timeout> --max-load <max-load> --disable]",
+				},
+				{
+					Name:        "archive",
+					Description: "Move a stopped dev space to cold storage. The volume is snapshotted and deleted, and restored on the next start or with unarchive.",
+					Action:      commands.ArchiveCommand,
+					Flags: []cli.Flag{
+						outputFlag(),
+						&cli.StringFlag{
+							Name:     "name",
+							Aliases:  []string{"n"},
+							Usage:    "The name of the dev-space",
+							Required: true,
+						},
+					},
+					Usage: "-n <name> [-o <output>]",
+				},
+				{
+					Name:        "unarchive",
+					Description: "Restore the volume of an archived dev space from its snapshot, in the dev space zone.",
+					Action:      commands.UnarchiveCommand,
+					Flags: []cli.Flag{
+						outputFlag(),
+						&cli.StringFlag{
+							Name:     "name",
+							Aliases:  []string{"n"},
+							Usage:    "The name of the dev-space",
+							Required: true,
+						},
+						&cli.BoolFlag{
+							Name:  "keep-snapshot",
+							Usage: "Keep the snapshot after the volume is restored",
+						},
+					},
+					Usage: "-n <name> [--keep-snapshot -o <output>]",
 				},
 				{
 					Name:        "copy",
 					Description: "Copy a dev space to a new region",
 					Action:      commands.CopyCommand,
 					Flags: []cli.Flag{
+						outputFlag(),
 						&cli.StringFlag{
 							Name:     "name",
 							Aliases:  []string{"n"},
@@ -280,7 +1011,115 @@ func GetCLI() *cli.App {
 							Required: true,
 						},
 					},
-					Usage: "-n <name> -r <region> -z <availability-zone>",
+					Usage: "-n <name> -r <region> -z <availability-zone> [-o <output>]",
+				},
+				{
+					Name:        "versions",
+					Description: "Manage the launch template versions of the dev space, to try a new host AMI or startup script and roll back",
+					Subcommands: []*cli.Command{
+						{
+							Name:        "list",
+							Description: "List the launch template versions, newest first",
+							Action:      commands.VersionsListCommand,
+							Flags: []cli.Flag{
+								outputFlag(),
+								&cli.StringFlag{
+									Name:     "name",
+									Aliases:  []string{"n"},
+									Usage:    "The name of the dev-space",
+									Required: true,
+								},
+							},
+							Usage: "-n <name> [-o <output>]",
+						},
+						{
+							Name:        "show",
+							Description: "Show a launch template version with its startup script",
+							Action:      commands.VersionsShowCommand,
+							Flags: []cli.Flag{
+								outputFlag(),
+								&cli.StringFlag{
+									Name:     "name",
+									Aliases:  []string{"n"},
+									Usage:    "The name of the dev-space, with an optional version e.g. MySpace/3 (defaults to the default version)",
+									Required: true,
+								},
+							},
+							Usage: "-n <name>[/<version>] [-o <output>]",
+						},
+						{
+							Name:        "diff",
+							Description: "Show the changes between two launch template versions",
+							Action:      commands.VersionsDiffCommand,
+							Flags: []cli.Flag{
+								outputFlag(),
+								&cli.StringFlag{
+									Name:     "name",
+									Aliases:  []string{"n"},
+									Usage:    "The name of the dev-space",
+									Required: true,
+								},
+								&cli.StringFlag{
+									Name:  "from",
+									Usage: "The old version (defaults to the version before --to)",
+								},
+								&cli.StringFlag{
+									Name:  "to",
+									Usage: "The new version, a number or $Latest (defaults to the default version)",
+								},
+							},
+							Usage: "-n <name> [--from <version> --to <version> -o <output>]",
+						},
+						{
+							Name:        "set-default",
+							Description: "Set the version used when the dev space is started without a version",
+							Action:      commands.VersionsSetDefaultCommand,
+							Flags: []cli.Flag{
+								&cli.StringFlag{
+									Name:     "name",
+									Aliases:  []string{"n"},
+									Usage:    "The name of the dev-space with the version, e.g. MySpace/3",
+									Required: true,
+								},
+							},
+							Usage: "-n <name>/<version>",
+						},
+						{
+							Name:        "rollback",
+							Description: "Set the version before the default one as the default version",
+							Action:      commands.VersionsRollbackCommand,
+							Flags: []cli.Flag{
+								&cli.StringFlag{
+									Name:     "name",
+									Aliases:  []string{"n"},
+									Usage:    "The name of the dev-space",
+									Required: true,
+								},
+							},
+							Usage: "-n <name>",
+						},
+						{
+							Name:        "prune",
+							Description: "Delete all but the most recent launch template versions, the default version is always kept",
+							Action:      commands.VersionsPruneCommand,
+							Flags: []cli.Flag{
+								outputFlag(),
+								&cli.StringFlag{
+									Name:     "name",
+									Aliases:  []string{"n"},
+									Usage:    "The name of the dev-space",
+									Required: true,
+								},
+								&cli.IntFlag{
+									Name:     "keep",
+									Aliases:  []string{"k"},
+									Usage:    "The number of versions to keep",
+									Required: true,
+								},
+							},
+							Usage: "-n <name> -k <keep> [-o <output>]",
+						},
+					},
 				},
 			},
 		},
@@ -289,6 +1128,29 @@ func GetCLI() *cli.App {
 	return app
 }
 
+// outputFlag lets the commands with a result override the global --output
+func outputFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:    "output",
+		Aliases: []string{"o"},
+		Usage:   "Output format: table, json or yaml (defaults to the global --output)",
+	}
+}
+
+func templateSetFlag() cli.Flag {
+	return &cli.StringSliceFlag{
+		Name:  "set",
+		Usage: "Variables of the bootstrap template, overriding the env file and its defaults. e.g. --set key_name=MyKey --set storage_size=20",
+	}
+}
+
+func templateEnvFileFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:  "env-file",
+		Usage: "The path of an env file (KEY=VALUE lines) with variables of the bootstrap template",
+	}
+}
+
 func loadClients(c *cli.Context) error {
 	cfg, err := awsUtil.LoadAWSConfig()
 	if err != nil {
@@ -301,9 +1163,37 @@ func loadClients(c *cli.Context) error {
 	cfg.Region = config.AppConfig.DefaultRegion
 
 	client := ec2.NewFromConfig(cfg)
+	// the Price List API is only available in a few regions
+	pricingClient := pricing.NewFromConfig(cfg, func(o *pricing.Options) {
+		o.Region = "us-east-1"
+	})
 	logger := log.NewCLILogger()
 
-	handler := core.NewHandler(core.Config{DefaultRegion: cfg.Region}, client, logger)
+	var dns core.DNSConfig
+	if route53Cfg := config.AppConfig.Route53; route53Cfg.HostedZoneID != "" {
+		dns = core.DNSConfig{
+			Domain:   route53Cfg.Domain,
+			Provider: core.NewRoute53DNS(route53.NewFromConfig(cfg), route53Cfg.HostedZoneID),
+		}
+	} else if dynamicDNS := config.AppConfig.DNS; dynamicDNS.Endpoint != "" {
+		dns = core.DNSConfig{
+			Domain:   dynamicDNS.Domain,
+			Provider: core.NewWebhookDNS(dynamicDNS.Endpoint, dynamicDNS.Token),
+		}
+	}
+
+	budget := config.AppConfig.Budget
+	handler := core.NewHandler(core.Config{
+		DefaultRegion: cfg.Region,
+		Budget: core.Budget{
+			Monthly:   budget.Monthly,
+			DevSpace:  budget.DevSpace,
+			DevSpaces: budget.DevSpaces,
+			WarnOnly:  budget.Action == "warn",
+		},
+		DNS: dns,
+	}, client, pricingClient, logger)
+	handler.Subscribe(logger)
 
 	// inject the handler into the context
 	c.Context = context.WithValue(c.Context, "handler", handler)
@@ -0,0 +1,98 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/felipemarinho97/dev-spaces/cli/config"
+	"github.com/felipemarinho97/dev-spaces/cli/util"
+	"github.com/felipemarinho97/dev-spaces/core"
+	"github.com/urfave/cli/v2"
+)
+
+func ApplyCommand(c *cli.Context) error {
+	h := c.Context.Value("handler").(*core.Handler)
+	cfg := c.Context.Value("config").(*config.Config)
+	log := h.Logger
+
+	spec, err := core.LoadDevSpaceSpec(c.String("file"))
+	if err != nil {
+		return err
+	}
+	dryRun := c.Bool("dry-run")
+
+	identityFile := c.String("identity-file")
+	if identityFile == "" {
+		identityFile = cfg.IdentityFile
+	}
+
+	output := util.GetOutputFormat(c)
+
+	var ub *util.UnknownBar
+	if !dryRun {
+		ub = util.NewUnknownBar("Applying...")
+		ub.Start()
+	}
+	out, err := h.Apply(c.Context, core.ApplyOptions{
+		Spec:   spec,
+		DryRun: dryRun,
+		SSHKey: identityFile,
+	})
+	if ub != nil {
+		ub.Stop()
+	}
+	if err != nil {
+		if out.Action == core.ApplyCreate && !dryRun && !strings.Contains(err.Error(), "already exists") {
+			// clean up even when the creation was cancelled with CTRL+C
+			h.Destroy(context.WithoutCancel(c.Context), core.DestroyOptions{
+				Name: spec.Name,
+			})
+		}
+		return err
+	}
+
+	if util.IsStructuredOutput(output) {
+		return util.PrintOutput(output, out)
+	}
+
+	printSpecChanges(out.Changes, out.Action == core.ApplyCreate)
+
+	switch {
+	case out.Action == core.ApplyNone:
+		log.Info(fmt.Sprintf("DevSpace \"%s\" is up to date.", out.Name))
+	case dryRun:
+		log.Info(fmt.Sprintf("DevSpace \"%s\" would be %sd (dry run).", out.Name, out.Action))
+	case out.Action == core.ApplyCreate:
+		log.Info(fmt.Sprintf("DevSpace \"%s\" created successfully.", out.Name))
+	default:
+		log.Info(fmt.Sprintf("DevSpace \"%s\" updated successfully.", out.Name))
+	}
+
+	return nil
+}
+
+// printSpecChanges prints "+" for added fields, "-" for removed fields and
+// "~" for changed fields, with a line diff for multi-line values
+func printSpecChanges(changes []core.SpecChange, create bool) {
+	for _, change := range changes {
+		multiline := strings.Contains(change.Old, "\n") || strings.Contains(change.New, "\n")
+		switch {
+		case multiline:
+			symbol := "~"
+			if create {
+				symbol = "+"
+			}
+			fmt.Printf("%s %s:\n", symbol, change.Field)
+			for _, line := range util.LineDiff(change.Old, change.New) {
+				fmt.Printf("    %s\n", line)
+			}
+		case change.Old == "":
+			fmt.Printf("+ %s: %s\n", change.Field, change.New)
+		case change.New == "":
+			fmt.Printf("- %s: %s\n", change.Field, change.Old)
+		default:
+			fmt.Printf("~ %s: %s -> %s\n", change.Field, change.Old, change.New)
+		}
+	}
+}
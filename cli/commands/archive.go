@@ -0,0 +1,60 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/felipemarinho97/dev-spaces/cli/util"
+	"github.com/felipemarinho97/dev-spaces/core"
+	"github.com/urfave/cli/v2"
+)
+
+func ArchiveCommand(c *cli.Context) error {
+	h := c.Context.Value("handler").(*core.Handler)
+
+	ub := util.NewUnknownBar("Archiving..")
+	ub.Start()
+	defer ub.Stop()
+
+	out, err := h.Archive(c.Context, core.ArchiveOptions{
+		Name: c.String("name"),
+	})
+	if err != nil {
+		return err
+	}
+
+	output := util.GetOutputFormat(c)
+	if util.IsStructuredOutput(output) {
+		return util.PrintOutput(output, out)
+	}
+
+	fmt.Printf("snapshot-id=%s\n", out.SnapshotID)
+	fmt.Printf("deleted-volume-id=%s\n", out.VolumeID)
+
+	return nil
+}
+
+func UnarchiveCommand(c *cli.Context) error {
+	h := c.Context.Value("handler").(*core.Handler)
+
+	ub := util.NewUnknownBar("Unarchiving..")
+	ub.Start()
+	defer ub.Stop()
+
+	out, err := h.Unarchive(c.Context, core.UnarchiveOptions{
+		Name:         c.String("name"),
+		KeepSnapshot: c.Bool("keep-snapshot"),
+	})
+	if err != nil {
+		return err
+	}
+
+	output := util.GetOutputFormat(c)
+	if util.IsStructuredOutput(output) {
+		return util.PrintOutput(output, out)
+	}
+
+	fmt.Printf("volume-id=%s\n", out.VolumeID)
+	fmt.Printf("zone=%s\n", out.Zone)
+
+	return nil
+}
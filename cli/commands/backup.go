@@ -0,0 +1,165 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/felipemarinho97/dev-spaces/cli/util"
+	"github.com/felipemarinho97/dev-spaces/core"
+	"github.com/olekukonko/tablewriter"
+	"github.com/urfave/cli/v2"
+)
+
+func BackupCreateCommand(c *cli.Context) error {
+	h := c.Context.Value("handler").(*core.Handler)
+
+	ub := util.NewUnknownBar("Backing up..")
+	ub.Start()
+	defer ub.Stop()
+
+	backup, err := h.CreateBackup(c.Context, core.CreateBackupOptions{
+		Name:        c.String("name"),
+		Description: c.String("description"),
+		Wait:        c.Bool("wait"),
+	})
+	if err != nil {
+		return err
+	}
+
+	output := util.GetOutputFormat(c)
+	if util.IsStructuredOutput(output) {
+		return util.PrintOutput(output, backup)
+	}
+
+	fmt.Printf("snapshot-id=%s\n", backup.SnapshotID)
+	fmt.Printf("state=%s\n", backup.State)
+
+	return nil
+}
+
+func BackupListCommand(c *cli.Context) error {
+	h := c.Context.Value("handler").(*core.Handler)
+
+	backups, err := h.ListBackups(c.Context, core.ListBackupsOptions{
+		Name: c.String("name"),
+	})
+	if err != nil {
+		return err
+	}
+
+	output := util.GetOutputFormat(c)
+	if util.IsStructuredOutput(output) {
+		return util.PrintOutput(output, backups)
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Space Name", "Snapshot ID", "Volume ID", "Size", "State", "Start Time", "Description"})
+	table.SetAutoWrapText(false)
+	table.SetAutoFormatHeaders(true)
+	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetCenterSeparator("")
+	table.SetColumnSeparator("")
+	table.SetRowSeparator("")
+	table.SetHeaderLine(true)
+	table.SetBorder(false)
+	table.SetTablePadding("\t") // pad with tabs
+	table.SetNoWhiteSpace(true)
+
+	for _, backup := range backups {
+		state := backup.State
+		if backup.State == "pending" && backup.Progress != "" {
+			state = fmt.Sprintf("%s (%s)", backup.State, backup.Progress)
+		}
+
+		table.Append([]string{
+			backup.Name,
+			backup.SnapshotID,
+			backup.VolumeID,
+			fmt.Sprintf("%d GB", backup.Size),
+			state,
+			backup.StartTime.Local().Format("2006-01-02 15:04:05"),
+			backup.Description,
+		})
+	}
+
+	table.Render()
+
+	return nil
+}
+
+func BackupRestoreCommand(c *cli.Context) error {
+	h := c.Context.Value("handler").(*core.Handler)
+
+	ub := util.NewUnknownBar("Restoring..")
+	ub.Start()
+	defer ub.Stop()
+
+	out, err := h.RestoreBackup(c.Context, core.RestoreBackupOptions{
+		Name:            c.String("name"),
+		SnapshotID:      c.String("snapshot-id"),
+		DeleteOldVolume: c.Bool("delete-old-volume"),
+	})
+	if err != nil {
+		return err
+	}
+
+	output := util.GetOutputFormat(c)
+	if util.IsStructuredOutput(output) {
+		return util.PrintOutput(output, out)
+	}
+
+	fmt.Printf("volume-id=%s\n", out.VolumeID)
+	if !c.Bool("delete-old-volume") && out.OldVolumeID != "" {
+		fmt.Printf("old-volume-id=%s\n", out.OldVolumeID)
+	}
+
+	return nil
+}
+
+func BackupPruneCommand(c *cli.Context) error {
+	h := c.Context.Value("handler").(*core.Handler)
+
+	pruned, err := h.PruneBackups(c.Context, core.PruneBackupsOptions{
+		Name: c.String("name"),
+		Keep: c.Int("keep"),
+	})
+	if err != nil {
+		return err
+	}
+
+	output := util.GetOutputFormat(c)
+	if util.IsStructuredOutput(output) {
+		return util.PrintOutput(output, pruned)
+	}
+
+	for _, backup := range pruned {
+		fmt.Printf("deleted %s (%s)\n", backup.SnapshotID, backup.StartTime.Local().Format("2006-01-02 15:04:05"))
+	}
+
+	return nil
+}
+
+func BackupPolicyCommand(c *cli.Context) error {
+	h := c.Context.Value("handler").(*core.Handler)
+	log := h.Logger
+
+	name := c.String("name")
+	keep := c.Int("keep")
+
+	err := h.SetBackupPolicy(c.Context, core.SetBackupPolicyOptions{
+		Name: name,
+		Keep: keep,
+	})
+	if err != nil {
+		return err
+	}
+
+	if keep == 0 {
+		log.Info(fmt.Sprintf("Removed backup policy of %s", name))
+	} else {
+		log.Info(fmt.Sprintf("%s will be backed up on every stop, keeping the last %d backups", name, keep))
+	}
+
+	return nil
+}
@@ -0,0 +1,108 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/felipemarinho97/dev-spaces/cli/util"
+	"github.com/felipemarinho97/dev-spaces/core"
+	coreUtil "github.com/felipemarinho97/dev-spaces/core/util"
+	"github.com/urfave/cli/v2"
+)
+
+func BootstrapCommand(c *cli.Context) error {
+	h := c.Context.Value("handler").(*core.Handler)
+	log := h.Logger
+
+	name := c.String("name")
+	templatePath := c.String("template")
+	if templatePath == "" {
+		return errors.New("no template provided, usage: bootstrap -t <template>")
+	}
+
+	vars, err := templateVariables(c)
+	if err != nil {
+		return err
+	}
+
+	template, err := core.LoadBootstrapTemplate(templatePath, vars)
+	if err != nil {
+		return err
+	}
+	if name == "" {
+		name = template.TemplateName
+	}
+
+	ub := util.NewUnknownBar("Bootstrapping...")
+	ub.Start()
+	defer ub.Stop()
+
+	out, err := h.Bootstrap(c.Context, core.BootstrapOptions{
+		Name:     name,
+		Template: &template,
+		Arch:     c.String("arch"),
+		KeyName:  c.String("key-name"),
+	})
+	if err != nil {
+		// the resources of a failed bootstrap are cleaned up by core
+		if strings.Contains(err.Error(), "already exists") {
+			log.Warn(fmt.Sprintf("DevSpace \"%s\" already exists.", name))
+		}
+		return err
+	}
+
+	log.Info(fmt.Sprintf("DevSpace bootstrapped successfully with launch template %s.", out.LaunchTemplateID))
+
+	output := util.GetOutputFormat(c)
+	if util.IsStructuredOutput(output) {
+		return util.PrintOutput(output, out)
+	}
+
+	return nil
+}
+
+func BootstrapRenderCommand(c *cli.Context) error {
+	vars, err := templateVariables(c)
+	if err != nil {
+		return err
+	}
+
+	rendered, err := core.RenderBootstrapTemplate(c.String("template"), vars)
+	if err != nil {
+		return err
+	}
+
+	// only print templates that would load
+	_, err = core.LoadBootstrapTemplate(c.String("template"), vars)
+	if err != nil {
+		return err
+	}
+
+	fmt.Print(rendered)
+
+	return nil
+}
+
+// templateVariables returns the variables of the template from the env file,
+// overridden by the ones given with --set
+func templateVariables(c *cli.Context) (map[string]string, error) {
+	vars := map[string]string{}
+	if envFile := c.String("env-file"); envFile != "" {
+		env, err := coreUtil.LoadEnvFile(envFile)
+		if err != nil {
+			return nil, err
+		}
+		vars = env
+	}
+
+	for _, v := range c.StringSlice("set") {
+		key, value, ok := strings.Cut(v, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid variable: %s, expected KEY=VALUE", v)
+		}
+		vars[key] = value
+	}
+
+	return vars, nil
+}
@@ -3,18 +3,20 @@ package commands
 import (
 	"fmt"
 
+	"github.com/felipemarinho97/dev-spaces/cli/util"
 	"github.com/felipemarinho97/dev-spaces/core"
 	"github.com/urfave/cli/v2"
 )
 
 func CopyCommand(c *cli.Context) error {
 	h := c.Context.Value("handler").(*core.Handler)
+	output := util.GetOutputFormat(c)
 
 	name := c.String("name")
 	region := c.String("new-region")
 	availabilityZone := c.String("availability-zone")
 
-	fmt.Printf("Copying %s to %s\n", name, region)
+	h.Logger.Info(fmt.Sprintf("Copying %s to %s", name, region))
 
 	out, err := h.Copy(c.Context, core.CopyOptions{
 		Name:             name,
@@ -25,6 +27,10 @@ func CopyCommand(c *cli.Context) error {
 		return err
 	}
 
+	if util.IsStructuredOutput(output) {
+		return util.PrintOutput(output, out)
+	}
+
 	fmt.Printf("launch-template-id=%s\n", out.LaunchTemplateID)
 	fmt.Printf("volume-id=%s\n", out.VolumeID)
 
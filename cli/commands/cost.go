@@ -0,0 +1,143 @@
+package commands
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/felipemarinho97/dev-spaces/cli/util"
+	"github.com/felipemarinho97/dev-spaces/core"
+	"github.com/olekukonko/tablewriter"
+	"github.com/urfave/cli/v2"
+)
+
+// costReport is the structured output of cost
+type costReport struct {
+	From  time.Time       `json:"from" yaml:"from"`
+	To    time.Time       `json:"to" yaml:"to"`
+	Items []core.CostItem `json:"items" yaml:"items"`
+	Total core.CostItem   `json:"total" yaml:"total"`
+}
+
+func CostCommand(c *cli.Context) error {
+	h := c.Context.Value("handler").(*core.Handler)
+
+	to := time.Now()
+	from := to.Add(-c.Duration("since"))
+	var err error
+	if c.String("from") != "" {
+		from, err = time.ParseInLocation("2006-01-02", c.String("from"), time.Local)
+		if err != nil {
+			return fmt.Errorf("invalid from date, expected YYYY-MM-DD: %w", err)
+		}
+	}
+	if c.String("to") != "" {
+		to, err = time.ParseInLocation("2006-01-02", c.String("to"), time.Local)
+		if err != nil {
+			return fmt.Errorf("invalid to date, expected YYYY-MM-DD: %w", err)
+		}
+		// the end date is inclusive
+		to = to.AddDate(0, 0, 1)
+	}
+
+	ub := util.NewUnknownBar("Computing costs..")
+	ub.Start()
+
+	items, err := h.Cost(c.Context, core.CostOptions{
+		Name:          c.String("name"),
+		From:          from,
+		To:            to,
+		VolumePrice:   c.Float64("volume-price"),
+		SnapshotPrice: c.Float64("snapshot-price"),
+	})
+	ub.Stop()
+	if err != nil {
+		return err
+	}
+
+	total := core.CostItem{Name: "TOTAL"}
+	for _, item := range items {
+		total.InstanceHours += item.InstanceHours
+		total.InstanceCost += item.InstanceCost
+		total.VolumeGBMonths += item.VolumeGBMonths
+		total.VolumeCost += item.VolumeCost
+		total.SnapshotGBMonths += item.SnapshotGBMonths
+		total.SnapshotCost += item.SnapshotCost
+		total.Total += item.Total
+		total.Incomplete = total.Incomplete || item.Incomplete
+	}
+
+	output := util.GetOutputFormat(c)
+	if util.IsStructuredOutput(output) {
+		return util.PrintOutput(output, costReport{
+			From:  from,
+			To:    to,
+			Items: items,
+			Total: total,
+		})
+	}
+
+	if output == core.OutputFormatCSV {
+		w := csv.NewWriter(os.Stdout)
+		w.Write([]string{"name", "from", "to", "instance_hours", "instance_cost", "volume_gb_months", "volume_cost", "snapshot_gb_months", "snapshot_cost", "total", "incomplete"})
+		for _, item := range items {
+			w.Write([]string{
+				item.Name,
+				from.Format(time.RFC3339),
+				to.Format(time.RFC3339),
+				fmt.Sprintf("%.2f", item.InstanceHours),
+				fmt.Sprintf("%.4f", item.InstanceCost),
+				fmt.Sprintf("%.2f", item.VolumeGBMonths),
+				fmt.Sprintf("%.4f", item.VolumeCost),
+				fmt.Sprintf("%.2f", item.SnapshotGBMonths),
+				fmt.Sprintf("%.4f", item.SnapshotCost),
+				fmt.Sprintf("%.4f", item.Total),
+				fmt.Sprint(item.Incomplete),
+			})
+		}
+		w.Flush()
+
+		return w.Error()
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Space Name", "Instance Hours", "Instance Cost", "Volume GB-Months", "Volume Cost", "Snapshot GB-Months", "Snapshot Cost", "Total"})
+	table.SetAutoWrapText(false)
+	table.SetAutoFormatHeaders(true)
+	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetCenterSeparator("")
+	table.SetColumnSeparator("")
+	table.SetRowSeparator("")
+	table.SetHeaderLine(true)
+	table.SetBorder(false)
+	table.SetTablePadding("\t") // pad with tabs
+	table.SetNoWhiteSpace(true)
+
+	for _, item := range append(items, total) {
+		instanceCost := fmt.Sprintf("$%.2f", item.InstanceCost)
+		if item.Incomplete {
+			instanceCost += "*"
+		}
+
+		table.Append([]string{
+			item.Name,
+			fmt.Sprintf("%.1f", item.InstanceHours),
+			instanceCost,
+			fmt.Sprintf("%.1f", item.VolumeGBMonths),
+			fmt.Sprintf("$%.2f", item.VolumeCost),
+			fmt.Sprintf("%.1f", item.SnapshotGBMonths),
+			fmt.Sprintf("$%.2f", item.SnapshotCost),
+			fmt.Sprintf("$%.2f", item.Total),
+		})
+	}
+
+	fmt.Printf("Costs from %s to %s\n\n", from.Format("2006-01-02 15:04"), to.Format("2006-01-02 15:04"))
+	table.Render()
+	if total.Incomplete {
+		fmt.Println("\n* the price of some instances could not be found, see the warnings above")
+	}
+
+	return nil
+}
@@ -0,0 +1,65 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/felipemarinho97/dev-spaces/cli/config"
+	"github.com/felipemarinho97/dev-spaces/cli/util"
+	"github.com/felipemarinho97/dev-spaces/core"
+	"github.com/urfave/cli/v2"
+)
+
+func CpCommand(c *cli.Context) error {
+	h := c.Context.Value("handler").(*core.Handler)
+	cfg := c.Context.Value("config").(*config.Config)
+
+	if c.NArg() != 2 {
+		return errors.New("usage: cp [-r] <source> <destination>, one of them as <name>:<path>")
+	}
+
+	identityFile := c.String("identity-file")
+	if identityFile == "" {
+		identityFile = cfg.IdentityFile
+	}
+	if identityFile == "" {
+		return errors.New("flag identity-file or identity_file config must be provided")
+	}
+
+	srcName, src, srcRemote := util.ParseRemotePath(c.Args().Get(0))
+	dstName, dst, dstRemote := util.ParseRemotePath(c.Args().Get(1))
+	if srcRemote == dstRemote {
+		return errors.New("exactly one of source or destination must be a dev-space path (<name>:<path>)")
+	}
+	name := dstName
+	if srcRemote {
+		name = srcName
+	}
+
+	out, err := h.Transfer(c.Context, core.TransferOptions{
+		Name:        name,
+		SSHKey:      identityFile,
+		Host:        c.Bool("host"),
+		User:        c.String("user"),
+		Source:      src,
+		Destination: dst,
+		Download:    srcRemote,
+		Recursive:   c.Bool("recursive"),
+		Progress: func(path string, size int64) io.Writer {
+			return util.NewFileBar(path, size)
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	output := util.GetOutputFormat(c)
+	if util.IsStructuredOutput(output) {
+		return util.PrintOutput(output, out)
+	}
+
+	fmt.Printf("Copied %d file(s), %d bytes\n", out.Files, out.Bytes)
+
+	return nil
+}
@@ -1,9 +1,8 @@
 package commands
 
 import (
+	"context"
 	"fmt"
-	"os"
-	"os/signal"
 	"strings"
 
 	"github.com/felipemarinho97/dev-spaces/cli/util"
@@ -12,9 +11,6 @@ import (
 )
 
 func CreateCommand(c *cli.Context) error {
-	// if there is a signal CTRL+C, destroy the devspace
-	handleSignal(c)
-
 	h := c.Context.Value("handler").(*core.Handler)
 	log := h.Logger
 
@@ -52,7 +48,7 @@ func CreateCommand(c *cli.Context) error {
 	ub.Start()
 	defer ub.Stop()
 
-	_, err = h.Create(c.Context, core.CreateOptions{
+	out, err := h.Create(c.Context, core.CreateOptions{
 		Name:               name,
 		KeyName:            keyName,
 		InstanceProfileArn: instanceProfileArn,
@@ -77,22 +73,19 @@ func CreateCommand(c *cli.Context) error {
 			log.Warn(fmt.Sprintf("DevSpace \"%s\" already exists.", name))
 			return err
 		} else {
+			// clean up even when the creation was cancelled with CTRL+C
+			c.Context = context.WithoutCancel(c.Context)
 			DestroyCommand(c)
 		}
 		return err
 	}
 
 	log.Info(fmt.Sprintf("DevSpace \"%s\" created successfully.", name))
-	return nil
-}
 
-func handleSignal(c *cli.Context) {
-	s := make(chan os.Signal, 1)
-	signal.Notify(s, os.Interrupt)
-	go func() {
-		for range s {
-			DestroyCommand(c)
-			os.Exit(0)
-		}
-	}()
+	output := util.GetOutputFormat(c)
+	if util.IsStructuredOutput(output) {
+		return util.PrintOutput(output, out)
+	}
+
+	return nil
 }
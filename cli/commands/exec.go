@@ -0,0 +1,72 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/felipemarinho97/dev-spaces/cli/config"
+	"github.com/felipemarinho97/dev-spaces/core"
+	"github.com/felipemarinho97/dev-spaces/core/util/ssh"
+	"github.com/urfave/cli/v2"
+	"golang.org/x/term"
+)
+
+func ExecCommand(c *cli.Context) error {
+	h := c.Context.Value("handler").(*core.Handler)
+	cfg := c.Context.Value("config").(*config.Config)
+
+	command := strings.Join(c.Args().Slice(), " ")
+	if command == "" {
+		return errors.New("no command provided, usage: exec -n <name> -- <cmd>")
+	}
+
+	identityFile := c.String("identity-file")
+	if identityFile == "" {
+		identityFile = cfg.IdentityFile
+	}
+	if identityFile == "" {
+		return errors.New("flag identity-file or identity_file config must be provided")
+	}
+
+	env := map[string]string{}
+	for _, e := range c.StringSlice("env") {
+		key, value, ok := strings.Cut(e, "=")
+		if !ok {
+			return fmt.Errorf("invalid environment variable: %s, expected KEY=VALUE", e)
+		}
+		env[key] = value
+	}
+
+	sshClient, err := h.Connect(c.Context, core.ConnectOptions{
+		Name:   c.String("name"),
+		SSHKey: identityFile,
+		Host:   c.Bool("host"),
+		User:   c.String("user"),
+	})
+	if err != nil {
+		return err
+	}
+	defer sshClient.Close()
+
+	opts := ssh.ExecOptions{
+		Env:    env,
+		Stdout: os.Stdout,
+		Stderr: os.Stderr,
+	}
+	// only forward stdin when it is piped
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		opts.Stdin = os.Stdin
+	}
+
+	status, err := sshClient.Exec(c.Context, command, opts)
+	if err != nil {
+		return err
+	}
+	if status != 0 {
+		return cli.Exit("", status)
+	}
+
+	return nil
+}
@@ -0,0 +1,44 @@
+package commands
+
+import (
+	"errors"
+
+	"github.com/felipemarinho97/dev-spaces/cli/config"
+	"github.com/felipemarinho97/dev-spaces/cli/util"
+	"github.com/felipemarinho97/dev-spaces/core"
+	"github.com/urfave/cli/v2"
+)
+
+func ForwardCommand(c *cli.Context) error {
+	h := c.Context.Value("handler").(*core.Handler)
+	cfg := c.Context.Value("config").(*config.Config)
+
+	identityFile := c.String("identity-file")
+	if identityFile == "" {
+		identityFile = cfg.IdentityFile
+	}
+	if identityFile == "" {
+		return errors.New("flag identity-file or identity_file config must be provided")
+	}
+
+	forwards := []core.Forward{}
+	for _, spec := range c.StringSlice("local") {
+		forward, err := util.ParseForwardSpec(spec)
+		if err != nil {
+			return err
+		}
+		forwards = append(forwards, core.Forward{
+			LocalAddr:  forward.LocalAddr,
+			RemoteAddr: forward.RemoteAddr,
+		})
+	}
+
+	return h.Forward(c.Context, core.ForwardOptions{
+		Name:          c.String("name"),
+		SSHKey:        identityFile,
+		Host:          c.Bool("host"),
+		User:          c.String("user"),
+		Forwards:      forwards,
+		CheckInterval: c.Duration("check-interval"),
+	})
+}
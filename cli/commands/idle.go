@@ -0,0 +1,44 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/felipemarinho97/dev-spaces/cli/util"
+	"github.com/felipemarinho97/dev-spaces/core"
+	"github.com/urfave/cli/v2"
+)
+
+func IdlePolicyCommand(c *cli.Context) error {
+	h := c.Context.Value("handler").(*core.Handler)
+	log := h.Logger
+
+	name := c.String("name")
+
+	ub := util.NewUnknownBar("Updating idle policy..")
+	ub.Start()
+	defer ub.Stop()
+
+	var policy *core.IdlePolicy
+	if !c.Bool("disable") {
+		policy = &core.IdlePolicy{
+			Timeout: c.Duration("timeout"),
+			MaxLoad: c.Float64("max-load"),
+		}
+	}
+
+	err := h.SetIdlePolicy(c.Context, core.SetIdlePolicyOptions{
+		Name:   name,
+		Policy: policy,
+	})
+	if err != nil {
+		return err
+	}
+
+	if policy == nil {
+		log.Info(fmt.Sprintf("Removed idle policy of %s", name))
+	} else {
+		log.Info(fmt.Sprintf("%s will be stopped after %s idle (load < %.2f)", name, policy.Timeout, policy.MaxLoad))
+	}
+
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/felipemarinho97/dev-spaces/cli/util"
 	"github.com/felipemarinho97/dev-spaces/core"
 	"github.com/olekukonko/tablewriter"
 	"github.com/urfave/cli/v2"
@@ -11,15 +12,19 @@ import (
 
 func ListCommand(ctx *cli.Context) error {
 	h := ctx.Context.Value("handler").(*core.Handler)
-	output := core.OutputFormat(ctx.String("output"))
+	output := util.GetOutputFormat(ctx)
 
 	items, err := h.ListSpaces(ctx.Context, core.ListOptions{})
 	if err != nil {
 		return err
 	}
 
+	if util.IsStructuredOutput(output) {
+		return util.PrintOutput(output, items)
+	}
+
 	table := tablewriter.NewWriter(os.Stdout)
-	header := []string{"Space Name", "Ver", "ID", "Create Time"}
+	header := []string{"Space Name", "Ver", "ID", "Create Time", "Storage"}
 	if output == "wide" {
 		extra_headers := []string{"Instance ID", "Instance Type", "Instance State", "Public DNS", "Public IP", "Key Name", "Zone"}
 		header = append(header, extra_headers...)
@@ -38,12 +43,21 @@ func ListCommand(ctx *cli.Context) error {
 	table.SetNoWhiteSpace(true)
 
 	for _, item := range items {
+		storage := "volume"
+		if item.SnapshotID != "" {
+			storage = "ARCHIVED"
+			if output == "wide" {
+				storage = fmt.Sprintf("ARCHIVED (%s)", item.SnapshotID)
+			}
+		}
+
 		row := []string{}
 		row = append(row,
 			item.Name,
 			fmt.Sprint(item.Version),
 			item.LaunchTemplateID,
 			item.CreateTime,
+			storage,
 		)
 
 		if output == "wide" {
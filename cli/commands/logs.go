@@ -0,0 +1,28 @@
+package commands
+
+import (
+	"os"
+
+	"github.com/felipemarinho97/dev-spaces/cli/config"
+	"github.com/felipemarinho97/dev-spaces/core"
+	"github.com/urfave/cli/v2"
+)
+
+func LogsCommand(c *cli.Context) error {
+	h := c.Context.Value("handler").(*core.Handler)
+	cfg := c.Context.Value("config").(*config.Config)
+
+	// without an identity file the log is only read from the console output
+	identityFile := c.String("identity-file")
+	if identityFile == "" {
+		identityFile = cfg.IdentityFile
+	}
+
+	return h.Logs(c.Context, core.LogsOptions{
+		Name:      c.String("name"),
+		Bootstrap: c.Bool("bootstrap"),
+		Follow:    c.Bool("follow"),
+		SSHKey:    identityFile,
+		Output:    os.Stdout,
+	})
+}
@@ -0,0 +1,84 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/felipemarinho97/dev-spaces/cli/util"
+	"github.com/felipemarinho97/dev-spaces/core"
+	"github.com/olekukonko/tablewriter"
+	"github.com/urfave/cli/v2"
+)
+
+func PriceCommand(c *cli.Context) error {
+	h := c.Context.Value("handler").(*core.Handler)
+
+	ub := util.NewUnknownBar("Fetching prices..")
+	ub.Start()
+
+	out, err := h.Price(c.Context, core.PriceOptions{
+		Name:      c.String("name"),
+		MinCPUs:   c.Int("min-cpus"),
+		MinMemory: int(float64(1024) * c.Float64("min-memory")),
+		MaxPrice:  c.String("max-price"),
+		Limit:     c.Int("limit"),
+		OnDemand:  !c.Bool("no-on-demand"),
+	})
+	ub.Stop()
+	if err != nil {
+		return err
+	}
+
+	output := util.GetOutputFormat(c)
+	if util.IsStructuredOutput(output) {
+		return util.PrintOutput(output, out)
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"#", "Instance Type", "vCPUs", "Memory", "Spot Price", "On-Demand Price", "Savings", "Within Max Price"})
+	table.SetAutoWrapText(false)
+	table.SetAutoFormatHeaders(true)
+	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetCenterSeparator("")
+	table.SetColumnSeparator("")
+	table.SetRowSeparator("")
+	table.SetHeaderLine(true)
+	table.SetBorder(false)
+	table.SetTablePadding("\t") // pad with tabs
+	table.SetNoWhiteSpace(true)
+
+	for i, item := range out.Items {
+		onDemand, savings := "-", "-"
+		if item.OnDemandPrice > 0 {
+			onDemand = fmt.Sprintf("$%.4f", item.OnDemandPrice)
+			savings = fmt.Sprintf("%.0f%%", item.Savings*100)
+		}
+		within := "no"
+		if item.WithinMaxPrice {
+			within = "yes"
+		}
+
+		table.Append([]string{
+			fmt.Sprint(i + 1),
+			item.InstanceType,
+			fmt.Sprint(item.VCPUs),
+			fmt.Sprintf("%.1f GB", float64(item.MemoryMiB)/1024),
+			fmt.Sprintf("$%.4f", item.SpotPrice),
+			onDemand,
+			savings,
+			within,
+		})
+	}
+
+	table.Render()
+
+	fmt.Println()
+	if out.Fulfillable {
+		fmt.Printf("A spot request with --max-price %s would be fulfilled in %s.\n", c.String("max-price"), out.Zone)
+	} else {
+		fmt.Printf("No instance type is under --max-price %s in %s, the spot request would not be fulfilled.\n", c.String("max-price"), out.Zone)
+	}
+
+	return nil
+}
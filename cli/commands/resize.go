@@ -0,0 +1,46 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/felipemarinho97/dev-spaces/cli/config"
+	"github.com/felipemarinho97/dev-spaces/cli/util"
+	"github.com/felipemarinho97/dev-spaces/core"
+	"github.com/urfave/cli/v2"
+)
+
+func ResizeCommand(c *cli.Context) error {
+	h := c.Context.Value("handler").(*core.Handler)
+	cfg := c.Context.Value("config").(*config.Config)
+
+	identityFile := c.String("identity-file")
+	if identityFile == "" {
+		identityFile = cfg.IdentityFile
+	}
+
+	ub := util.NewUnknownBar("Resizing..")
+	ub.Start()
+	defer ub.Stop()
+
+	out, err := h.Resize(c.Context, core.ResizeOptions{
+		Name:   c.String("name"),
+		Size:   int32(c.Int("storage-size")),
+		SSHKey: identityFile,
+	})
+	if err != nil {
+		return err
+	}
+
+	output := util.GetOutputFormat(c)
+	if util.IsStructuredOutput(output) {
+		return util.PrintOutput(output, out)
+	}
+
+	fmt.Printf("volume-id=%s\n", out.VolumeID)
+	fmt.Printf("size=%dGB (was %dGB)\n", out.NewSize, out.OldSize)
+	if out.Usage != "" {
+		fmt.Printf("filesystem=%s\n", out.Usage)
+	}
+
+	return nil
+}
@@ -22,23 +22,30 @@ func EditSpecCommand(c *cli.Context) error {
 	defer ub.Stop()
 
 	newSpec, err := h.EditSpec(c.Context, core.EditSpecOptions{
-		Name:      name,
-		MinCPUs:   minCPUs,
-		MinMemory: 1024 * minMemory,
-		MaxPrice:  maxPrice,
-		SSHKey:    identityFile,
+		Name:         name,
+		MinCPUs:      minCPUs,
+		MinMemory:    1024 * minMemory,
+		MaxPrice:     maxPrice,
+		SSHKey:       identityFile,
+		IgnoreBudget: c.Bool("ignore-budget"),
+		Timeout:      c.Duration("timeout"),
 	})
 	if err != nil {
 		return err
 	}
 
 	// update SSH config entry
-	_, err = util.CreateSSHConfig(*cfg, newSpec.InstanceIP, name)
+	_, err = util.CreateSSHConfig(*cfg, newSpec.Host(), name)
 	if err != nil {
 		h.Logger.Warn("Error updating SSH config entry: %s", err)
 	} else {
 		h.Logger.Info("Updated SSH config entry")
 	}
 
+	output := util.GetOutputFormat(c)
+	if err == nil && util.IsStructuredOutput(output) {
+		return util.PrintOutput(output, newSpec)
+	}
+
 	return err
 }
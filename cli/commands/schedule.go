@@ -0,0 +1,178 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/felipemarinho97/dev-spaces/cli/config"
+	"github.com/felipemarinho97/dev-spaces/cli/util"
+	"github.com/felipemarinho97/dev-spaces/core"
+	"github.com/olekukonko/tablewriter"
+	"github.com/urfave/cli/v2"
+)
+
+func ScheduleAddCommand(c *cli.Context) error {
+	h := c.Context.Value("handler").(*core.Handler)
+
+	name := c.String("name")
+	days, err := util.ParseWeekdays(c.String("days"))
+	if err != nil {
+		return err
+	}
+
+	schedule, err := h.AddSchedule(c.Context, core.AddScheduleOptions{
+		Name: name,
+		Schedule: core.Schedule{
+			Days:      days,
+			Start:     c.String("start"),
+			Stop:      c.String("stop"),
+			Location:  c.String("tz"),
+			MinCPUs:   c.Int("min-cpus"),
+			MinMemory: int(float64(1024) * c.Float64("min-memory")),
+			MaxPrice:  c.String("max-price"),
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	output := util.GetOutputFormat(c)
+	if util.IsStructuredOutput(output) {
+		return util.PrintOutput(output, schedule)
+	}
+
+	fmt.Printf("schedule-id=%d\n", schedule.ID)
+
+	return nil
+}
+
+func ScheduleListCommand(c *cli.Context) error {
+	h := c.Context.Value("handler").(*core.Handler)
+
+	items, err := h.ListSchedules(c.Context, core.ListSchedulesOptions{
+		Name: c.String("name"),
+	})
+	if err != nil {
+		return err
+	}
+
+	output := util.GetOutputFormat(c)
+	if util.IsStructuredOutput(output) {
+		return util.PrintOutput(output, items)
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Space Name", "ID", "Days", "Start", "Stop", "Time Zone", "Min CPUs", "Min Memory", "Max Price"})
+	table.SetAutoWrapText(false)
+	table.SetAutoFormatHeaders(true)
+	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetCenterSeparator("")
+	table.SetColumnSeparator("")
+	table.SetRowSeparator("")
+	table.SetHeaderLine(true)
+	table.SetBorder(false)
+	table.SetTablePadding("\t") // pad with tabs
+	table.SetNoWhiteSpace(true)
+
+	for _, item := range items {
+		days := []string{}
+		for _, d := range item.Schedule.Days {
+			days = append(days, strings.ToLower(d.String()[:3]))
+		}
+		location := item.Schedule.Location
+		if location == "" {
+			location = "Local"
+		}
+
+		table.Append([]string{
+			item.Name,
+			fmt.Sprint(item.Schedule.ID),
+			strings.Join(days, ","),
+			item.Schedule.Start,
+			item.Schedule.Stop,
+			location,
+			fmt.Sprint(item.Schedule.MinCPUs),
+			fmt.Sprintf("%.1f GB", float64(item.Schedule.MinMemory)/1024),
+			item.Schedule.MaxPrice,
+		})
+	}
+
+	table.Render()
+
+	return nil
+}
+
+func ScheduleRemoveCommand(c *cli.Context) error {
+	h := c.Context.Value("handler").(*core.Handler)
+
+	return h.RemoveSchedule(c.Context, core.RemoveScheduleOptions{
+		Name: c.String("name"),
+		ID:   c.Int("id"),
+	})
+}
+
+func ScheduleRunCommand(c *cli.Context) error {
+	ctx := c.Context
+	h := ctx.Value("handler").(*core.Handler)
+	cfg := ctx.Value("config").(*config.Config)
+	log := h.Logger
+
+	name := c.String("name")
+	interval := c.Duration("interval")
+	output := util.GetOutputFormat(c)
+
+	ub := util.NewUnknownBar("Running schedules..")
+	ub.Start()
+	defer ub.Stop()
+
+	since := time.Now().Add(-interval)
+	for {
+		now := time.Now()
+		actions, err := h.RunSchedules(ctx, core.RunSchedulesOptions{
+			Name:  name,
+			Since: since,
+			Now:   now,
+		})
+		if err != nil {
+			log.Warn(fmt.Sprintf("Error running schedules: %s", err))
+		} else {
+			since = now
+		}
+
+		for _, action := range actions {
+			if util.IsStructuredOutput(output) {
+				err = printScheduleAction(output, action)
+				if err != nil {
+					return err
+				}
+			} else {
+				fmt.Printf("%s %s %s (schedule %d)\n", time.Now().Format(time.RFC3339), action.Action, action.Name, action.ScheduleID)
+			}
+
+			if action.Action == "start" {
+				// update SSH config entry
+				_, err = util.CreateSSHConfig(*cfg, action.Start.Host(), action.Name)
+				if err != nil {
+					log.Warn(fmt.Sprintf("Error updating SSH config entry for %s: %s", action.Name, err))
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(interval):
+		}
+	}
+}
+
+func printScheduleAction(output core.OutputFormat, action core.ScheduleAction) error {
+	if output == core.OutputFormatYAML {
+		fmt.Println("---")
+	}
+
+	return util.PrintOutput(output, action)
+}
@@ -0,0 +1,77 @@
+package commands
+
+import (
+	"errors"
+	"os"
+
+	"github.com/felipemarinho97/dev-spaces/cli/config"
+	"github.com/felipemarinho97/dev-spaces/cli/util"
+	"github.com/felipemarinho97/dev-spaces/core"
+	"github.com/felipemarinho97/dev-spaces/core/util/ssh"
+	"github.com/urfave/cli/v2"
+	"golang.org/x/term"
+)
+
+func SSHCommand(c *cli.Context) error {
+	h := c.Context.Value("handler").(*core.Handler)
+	cfg := c.Context.Value("config").(*config.Config)
+
+	identityFile := c.String("identity-file")
+	if identityFile == "" {
+		identityFile = cfg.IdentityFile
+	}
+	if identityFile == "" {
+		return errors.New("flag identity-file or identity_file config must be provided")
+	}
+
+	sshClient, err := h.Connect(c.Context, core.ConnectOptions{
+		Name:   c.String("name"),
+		SSHKey: identityFile,
+		Host:   c.Bool("host"),
+		User:   c.String("user"),
+	})
+	if err != nil {
+		return err
+	}
+	defer sshClient.Close()
+
+	fd := int(os.Stdin.Fd())
+	size := ssh.WindowSize{Width: 80, Height: 24}
+	if term.IsTerminal(fd) {
+		state, err := term.MakeRaw(fd)
+		if err != nil {
+			return err
+		}
+		defer term.Restore(fd, state)
+
+		width, height, err := term.GetSize(fd)
+		if err == nil {
+			size = ssh.WindowSize{Width: width, Height: height}
+		}
+	}
+
+	resize, stop := util.WatchTerminalSize(fd)
+	defer stop()
+
+	termType := os.Getenv("TERM")
+	if termType == "" {
+		termType = "xterm-256color"
+	}
+
+	status, err := sshClient.Shell(ssh.ShellOptions{
+		Term:   termType,
+		Size:   size,
+		Resize: resize,
+		Stdin:  os.Stdin,
+		Stdout: os.Stdout,
+		Stderr: os.Stderr,
+	})
+	if err != nil {
+		return err
+	}
+	if status != 0 {
+		return cli.Exit("", status)
+	}
+
+	return nil
+}
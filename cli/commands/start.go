@@ -27,26 +27,34 @@ func StartCommand(c *cli.Context) error {
 	timeout := c.Duration("timeout")
 	minMemory := int(float64(1024) * memorySpec)
 	wait := c.Bool("wait")
+	capacity := c.String("capacity")
+	fallbackAfter := c.Duration("fallback-after")
+	ignoreBudget := c.Bool("ignore-budget")
+	output := util.GetOutputFormat(c)
 
 	ub := util.NewUnknownBar("Starting..")
 	ub.Start()
 	defer ub.Stop()
 
 	out, err := h.Start(ctx, core.StartOptions{
-		Name:      name,
-		MinCPUs:   cpusSpec,
-		MinMemory: minMemory,
-		MaxPrice:  maxPrice,
-		Timeout:   timeout,
+		Name:          name,
+		MinCPUs:       cpusSpec,
+		MinMemory:     minMemory,
+		MaxPrice:      maxPrice,
+		Timeout:       timeout,
+		CapacityMode:  core.CapacityMode(capacity),
+		FallbackAfter: fallbackAfter,
+		IgnoreBudget:  ignoreBudget,
 	})
 	if err != nil {
 		return err
 	}
+	log.Info(fmt.Sprintf("Obtained %s instance %s (%s)", out.CapacityType, out.InstanceID, out.Type))
 
-	loginCommand := fmt.Sprintf("ssh -i <your-key.pem> -p 2222 -o StrictHostKeyChecking=no root@%s", out.PublicIP)
+	loginCommand := fmt.Sprintf("ssh -i <your-key.pem> -p 2222 -o StrictHostKeyChecking=no root@%s", out.Host())
 
 	// create SSH config entry
-	configPath, err := util.CreateSSHConfig(*cfg, out.PublicIP, name)
+	configPath, err := util.CreateSSHConfig(*cfg, out.Host(), name)
 	if err != nil {
 		log.Warn(fmt.Sprintf("Error creating SSH config entry for %s: %s", name, err))
 	} else {
@@ -58,13 +66,21 @@ func StartCommand(c *cli.Context) error {
 	if wait {
 		// wait until port 2222 is reachable
 		log.Info("Waiting for port 2222 (ssh) to be reachable. This can take a few minutes...")
-		err = helpers.WaitUntilReachable(out.PublicIP, out.Port)
+		err = helpers.WaitUntilReachable(c.Context, out.PublicIP, out.Port)
 		if err != nil {
 			return err
 		}
 
 		log.Info("You can now ssh into your dev space with the following command: ")
-		fmt.Printf("$ %s\n", loginCommand)
+		if util.IsStructuredOutput(output) {
+			log.Info(fmt.Sprintf("$ %s", loginCommand))
+		} else {
+			fmt.Printf("$ %s\n", loginCommand)
+		}
+	}
+
+	if util.IsStructuredOutput(output) {
+		return util.PrintOutput(output, out)
 	}
 
 	return nil
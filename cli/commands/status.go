@@ -4,6 +4,7 @@ import (
 	"os"
 	"strings"
 
+	"github.com/felipemarinho97/dev-spaces/cli/util"
 	"github.com/felipemarinho97/dev-spaces/core"
 	"github.com/olekukonko/tablewriter"
 	"github.com/urfave/cli/v2"
@@ -20,6 +21,11 @@ func StatusCommand(ctx *cli.Context) error {
 		return err
 	}
 
+	output := util.GetOutputFormat(ctx)
+	if util.IsStructuredOutput(output) {
+		return util.PrintOutput(output, items)
+	}
+
 	data := [][]string{}
 
 	for _, item := range items {
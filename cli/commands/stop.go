@@ -15,13 +15,18 @@ func StopCommand(ctx *cli.Context) error {
 	ub.Start()
 	defer ub.Stop()
 
-	_, err := h.Stop(ctx.Context, core.StopOptions{
+	out, err := h.Stop(ctx.Context, core.StopOptions{
 		Name: name,
 	})
 	if err != nil {
 		return err
 	}
 
+	output := util.GetOutputFormat(ctx)
+	if util.IsStructuredOutput(output) {
+		return util.PrintOutput(output, out)
+	}
+
 	log.Info("Stopped")
 	return nil
 }
@@ -0,0 +1,69 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/felipemarinho97/dev-spaces/cli/util"
+	"github.com/felipemarinho97/dev-spaces/core"
+	"github.com/olekukonko/tablewriter"
+	"github.com/urfave/cli/v2"
+)
+
+func TemplatesListCommand(c *cli.Context) error {
+	output := util.GetOutputFormat(c)
+
+	items, err := core.ListTemplates()
+	if err != nil {
+		return err
+	}
+
+	if util.IsStructuredOutput(output) {
+		return util.PrintOutput(output, items)
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Template", "Name", "Architectures", "Description"})
+	table.SetAutoWrapText(false)
+	table.SetAutoFormatHeaders(true)
+	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetCenterSeparator("")
+	table.SetColumnSeparator("")
+	table.SetRowSeparator("")
+	table.SetHeaderLine(true)
+	table.SetBorder(false)
+	table.SetTablePadding("\t") // pad with tabs
+	table.SetNoWhiteSpace(true)
+
+	for _, item := range items {
+		table.Append([]string{
+			core.BuiltinTemplatePrefix + item.Name,
+			item.TemplateName,
+			strings.Join(item.Architectures, ", "),
+			item.Description,
+		})
+	}
+
+	table.Render()
+
+	return nil
+}
+
+func TemplatesShowCommand(c *cli.Context) error {
+	name := strings.TrimPrefix(c.Args().First(), core.BuiltinTemplatePrefix)
+	if name == "" {
+		return errors.New("no template provided, usage: templates show <name>")
+	}
+
+	template, err := core.ShowTemplate(name)
+	if err != nil {
+		return err
+	}
+
+	fmt.Print(template)
+
+	return nil
+}
@@ -0,0 +1,186 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/felipemarinho97/dev-spaces/cli/util"
+	"github.com/felipemarinho97/dev-spaces/core"
+	"github.com/olekukonko/tablewriter"
+	"github.com/urfave/cli/v2"
+)
+
+func VersionsListCommand(c *cli.Context) error {
+	h := c.Context.Value("handler").(*core.Handler)
+
+	versions, err := h.ListTemplateVersions(c.Context, core.ListTemplateVersionsOptions{
+		Name: c.String("name"),
+	})
+	if err != nil {
+		return err
+	}
+
+	output := util.GetOutputFormat(c)
+	if util.IsStructuredOutput(output) {
+		return util.PrintOutput(output, versions)
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Version", "Default", "Created At", "Host AMI", "Key Name", "Description"})
+	table.SetAutoWrapText(false)
+	table.SetAutoFormatHeaders(true)
+	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetCenterSeparator("")
+	table.SetColumnSeparator("")
+	table.SetRowSeparator("")
+	table.SetHeaderLine(true)
+	table.SetBorder(false)
+	table.SetTablePadding("\t") // pad with tabs
+	table.SetNoWhiteSpace(true)
+
+	for _, version := range versions {
+		var isDefault string
+		if version.Default {
+			isDefault = "*"
+		}
+
+		table.Append([]string{
+			fmt.Sprint(version.Version),
+			isDefault,
+			version.CreatedAt.Local().Format("2006-01-02 15:04:05"),
+			version.HostAMI,
+			version.KeyName,
+			version.Description,
+		})
+	}
+
+	table.Render()
+
+	return nil
+}
+
+func VersionsShowCommand(c *cli.Context) error {
+	h := c.Context.Value("handler").(*core.Handler)
+
+	version, err := h.ShowTemplateVersion(c.Context, core.ShowTemplateVersionOptions{
+		Name: c.String("name"),
+	})
+	if err != nil {
+		return err
+	}
+
+	output := util.GetOutputFormat(c)
+	if util.IsStructuredOutput(output) {
+		return util.PrintOutput(output, version)
+	}
+
+	fmt.Printf("version=%d\n", version.Version)
+	fmt.Printf("default=%t\n", version.Default)
+	fmt.Printf("description=%s\n", version.Description)
+	fmt.Printf("created-at=%s\n", version.CreatedAt.Local().Format("2006-01-02 15:04:05"))
+	fmt.Printf("created-by=%s\n", version.CreatedBy)
+	fmt.Printf("host-ami=%s\n", version.HostAMI)
+	fmt.Printf("host-device=%s\n", version.HostDevice)
+	fmt.Printf("key-name=%s\n", version.KeyName)
+	fmt.Printf("security-group-ids=%s\n", strings.Join(version.SecurityGroupIds, ","))
+	fmt.Printf("instance-profile-arn=%s\n", version.InstanceProfileArn)
+	fmt.Printf("availability-zone=%s\n", version.AvailabilityZone)
+	keys := make([]string, 0, len(version.Tags))
+	for key := range version.Tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Printf("tags.%s=%s\n", key, version.Tags[key])
+	}
+	fmt.Printf("startup-script:\n%s", version.StartupScript)
+
+	return nil
+}
+
+func VersionsDiffCommand(c *cli.Context) error {
+	h := c.Context.Value("handler").(*core.Handler)
+	log := h.Logger
+
+	out, err := h.DiffTemplateVersions(c.Context, core.DiffTemplateVersionsOptions{
+		Name: c.String("name"),
+		From: c.String("from"),
+		To:   c.String("to"),
+	})
+	if err != nil {
+		return err
+	}
+
+	output := util.GetOutputFormat(c)
+	if util.IsStructuredOutput(output) {
+		return util.PrintOutput(output, out)
+	}
+
+	if len(out.Changes) == 0 {
+		log.Info(fmt.Sprintf("Versions %d and %d of %s are the same", out.From, out.To, out.Name))
+		return nil
+	}
+
+	fmt.Printf("--- %s/%d\n+++ %s/%d\n", out.Name, out.From, out.Name, out.To)
+	printSpecChanges(out.Changes, false)
+
+	return nil
+}
+
+func VersionsSetDefaultCommand(c *cli.Context) error {
+	h := c.Context.Value("handler").(*core.Handler)
+	log := h.Logger
+
+	version, err := h.SetDefaultTemplateVersion(c.Context, core.SetDefaultTemplateVersionOptions{
+		Name: c.String("name"),
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Info(fmt.Sprintf("Version %d is now the default version of %s", version.Version, version.Name))
+
+	return nil
+}
+
+func VersionsRollbackCommand(c *cli.Context) error {
+	h := c.Context.Value("handler").(*core.Handler)
+	log := h.Logger
+
+	version, err := h.RollbackTemplateVersion(c.Context, core.RollbackTemplateVersionOptions{
+		Name: c.String("name"),
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Info(fmt.Sprintf("Rolled back %s to version %d", version.Name, version.Version))
+
+	return nil
+}
+
+func VersionsPruneCommand(c *cli.Context) error {
+	h := c.Context.Value("handler").(*core.Handler)
+
+	pruned, err := h.PruneTemplateVersions(c.Context, core.PruneTemplateVersionsOptions{
+		Name: c.String("name"),
+		Keep: c.Int("keep"),
+	})
+	if err != nil {
+		return err
+	}
+
+	output := util.GetOutputFormat(c)
+	if util.IsStructuredOutput(output) {
+		return util.PrintOutput(output, pruned)
+	}
+
+	for _, version := range pruned {
+		fmt.Printf("deleted version %d (%s)\n", version.Version, version.CreatedAt.Local().Format("2006-01-02 15:04:05"))
+	}
+
+	return nil
+}
@@ -0,0 +1,116 @@
+package commands
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/felipemarinho97/dev-spaces/cli/config"
+	"github.com/felipemarinho97/dev-spaces/cli/util"
+	"github.com/felipemarinho97/dev-spaces/core"
+	"github.com/urfave/cli/v2"
+)
+
+// watchEvent is the structured output of watch, one document per event
+type watchEvent struct {
+	Time time.Time `json:"time" yaml:"time"`
+	// Action is either "recovered" or "stopped"
+	Action    string              `json:"action" yaml:"action"`
+	Recovered *core.RecoverOutput `json:"recovered,omitempty" yaml:"recovered,omitempty"`
+	Stopped   *core.IdleCheckItem `json:"stopped,omitempty" yaml:"stopped,omitempty"`
+}
+
+func printWatchEvent(output core.OutputFormat, event watchEvent) error {
+	if output == core.OutputFormatYAML {
+		fmt.Println("---")
+	}
+
+	return util.PrintOutput(output, event)
+}
+
+func WatchCommand(c *cli.Context) error {
+	ctx := c.Context
+	h := ctx.Value("handler").(*core.Handler)
+	cfg := ctx.Value("config").(*config.Config)
+	log := h.Logger
+
+	name := c.String("name")
+	interval := c.Duration("interval")
+	identityFile := c.String("identity-file")
+	if identityFile == "" {
+		identityFile = cfg.Idle.IdentityFile
+	}
+	if identityFile == "" {
+		identityFile = cfg.IdentityFile
+	}
+	output := util.GetOutputFormat(c)
+
+	ub := util.NewUnknownBar("Watching..")
+	ub.Start()
+	defer ub.Stop()
+
+	for {
+		recovered, err := h.Recover(ctx, core.RecoverOptions{
+			Name: name,
+		})
+		if err != nil {
+			log.Warn(fmt.Sprintf("Error checking for interruptions: %s", err))
+		}
+
+		for _, item := range recovered {
+			if util.IsStructuredOutput(output) {
+				item := item
+				err = printWatchEvent(output, watchEvent{Time: time.Now(), Action: "recovered", Recovered: &item})
+				if err != nil {
+					return err
+				}
+			} else {
+				fmt.Printf("%s recovered %s: interrupted-fleet=%s instance-id=%s type=%s capacity=%s public-ip=%s\n",
+					time.Now().Format(time.RFC3339), item.Name, item.InterruptedFleetID,
+					item.Start.InstanceID, item.Start.Type, item.Start.CapacityType, item.Start.PublicIP)
+			}
+
+			// refresh SSH config entry
+			_, err = util.CreateSSHConfig(*cfg, item.Start.Host(), item.Name)
+			if err != nil {
+				log.Warn(fmt.Sprintf("Error updating SSH config entry for %s: %s", item.Name, err))
+			} else {
+				log.Info(fmt.Sprintf("Updated SSH config entry for %s.", item.Name))
+			}
+		}
+
+		if identityFile != "" {
+			checked, err := h.IdleCheck(ctx, core.IdleCheckOptions{
+				Name:   name,
+				SSHKey: identityFile,
+				DefaultPolicy: core.IdlePolicy{
+					Timeout: cfg.Idle.Timeout,
+					MaxLoad: cfg.Idle.MaxLoad,
+				},
+			})
+			if err != nil {
+				log.Warn(fmt.Sprintf("Error checking for idle dev spaces: %s", err))
+			}
+
+			for _, item := range checked {
+				if !item.Stopped {
+					continue
+				}
+				if util.IsStructuredOutput(output) {
+					item := item
+					err = printWatchEvent(output, watchEvent{Time: time.Now(), Action: "stopped", Stopped: &item})
+					if err != nil {
+						return err
+					}
+				} else {
+					fmt.Printf("%s stopped %s: %s\n", time.Now().Format(time.RFC3339), item.Name, item.Reason)
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(interval):
+		}
+	}
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/knadh/koanf"
 	"github.com/knadh/koanf/parsers/toml"
@@ -12,7 +13,9 @@ import (
 type Config struct {
 	// DefaultRegion is the default AWS region to use when making AWS API calls.
 	DefaultRegion string `koanf:"default_region"`
-	DNS           struct {
+	// IdentityFile is the default path of the SSH key used to connect to the dev spaces.
+	IdentityFile string `koanf:"identity_file"`
+	DNS          struct {
 		// Endpoint is the endpoint to use for the DNS provider.
 		Endpoint string `koanf:"endpoint"`
 		// Token is the token to use for the DNS provider.
@@ -20,6 +23,30 @@ type Config struct {
 		// Domain is the domain to use for SLD.
 		Domain string `koanf:"domain"`
 	} `koanf:"dynamicdns"`
+	Route53 struct {
+		// HostedZoneID is the Route 53 hosted zone where the dev spaces records are created.
+		HostedZoneID string `koanf:"hosted_zone_id"`
+		// Domain is the domain of the dev spaces hostnames, in the hosted zone.
+		Domain string `koanf:"domain"`
+	} `koanf:"route53"`
+	Idle struct {
+		// Timeout is the default time a dev space can be idle before it is stopped (0 disables).
+		Timeout time.Duration `koanf:"timeout"`
+		// MaxLoad is the 1-minute load average under which a dev space is considered idle.
+		MaxLoad float64 `koanf:"max_load"`
+		// IdentityFile is the path of the SSH key used to check the dev spaces activity.
+		IdentityFile string `koanf:"identity_file"`
+	} `koanf:"idle"`
+	Budget struct {
+		// Monthly is the limit in USD of the spend of all dev spaces in a calendar month.
+		Monthly float64 `koanf:"monthly"`
+		// DevSpace is the default limit in USD of the monthly spend of each dev space.
+		DevSpace float64 `koanf:"devspace"`
+		// DevSpaces are the limits in USD of the monthly spend of specific dev spaces.
+		DevSpaces map[string]float64 `koanf:"devspaces"`
+		// Action is what to do when a start could exceed a budget: refuse (default) or warn.
+		Action string `koanf:"action"`
+	} `koanf:"budget"`
 }
 
 var (
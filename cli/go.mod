@@ -4,12 +4,16 @@ go 1.21
 
 require (
 	github.com/aws/aws-sdk-go-v2/service/ec2 v1.112.0
+	github.com/aws/aws-sdk-go-v2/service/pricing v1.21.3
+	github.com/aws/aws-sdk-go-v2/service/route53 v1.29.2
 	github.com/felipemarinho97/invest-path/util v1.0.1
 	github.com/knadh/koanf v1.4.2
 	github.com/olekukonko/tablewriter v0.0.5
 	github.com/satori/go.uuid v1.2.0
 	github.com/schollz/progressbar/v3 v3.8.6
 	github.com/urfave/cli/v2 v2.25.7
+	golang.org/x/term v0.0.0-20210927222741-03fcf44c2211
+	gopkg.in/yaml.v2 v2.2.8
 )
 
 require (
@@ -40,6 +44,5 @@ require (
 	github.com/xrash/smetrics v0.0.0-20201216005158-039620a65673 // indirect
 	golang.org/x/crypto v0.0.0-20220131195533-30dcbda58838 // indirect
 	golang.org/x/sys v0.0.0-20220128215802-99c3d69c2c27 // indirect
-	golang.org/x/term v0.0.0-20210927222741-03fcf44c2211 // indirect
 	gopkg.in/check.v1 v1.0.0-20201130134442-10cb98267c6c // indirect
 )
@@ -5,16 +5,23 @@ import (
 	"os"
 
 	"github.com/felipemarinho97/dev-spaces/cli/util"
+	"github.com/felipemarinho97/dev-spaces/core"
 	"github.com/felipemarinho97/dev-spaces/core/log"
 )
 
+// Logger logs the messages and renders the events of the core operations
+type Logger interface {
+	log.Logger
+	core.EventHandler
+}
+
 type cliLogger struct {
 	ub    *util.UnknownBar
 	level log.LogLevel
 }
 
 // NewCLILogger creates a new CLI logger implementation
-func NewCLILogger() log.Logger {
+func NewCLILogger() Logger {
 	ub := util.NewUnknownBar("---")
 	level := log.InfoLevel
 	if logDebug() {
@@ -65,3 +72,20 @@ func (l *cliLogger) Panic(args ...interface{}) {
 		l.ub.SetDescription(fmt.Sprint(args...))
 	}
 }
+
+// HandleEvent renders the events of the core operations, showing the phase
+// transitions only in debug mode
+func (l *cliLogger) HandleEvent(event core.Event) {
+	switch event.Type {
+	case core.EventPhaseFinished:
+		l.Debug(fmt.Sprintf("[%s] %s", event.Operation, event.Message))
+	case core.EventWarning:
+		if event.Err != nil {
+			l.Warn(fmt.Sprintf("%s: %s", event.Message, event.Err))
+			return
+		}
+		l.Warn(event.Message)
+	default:
+		l.Info(event.Message)
+	}
+}
@@ -1,9 +1,11 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
+	"os/signal"
 
 	"github.com/felipemarinho97/dev-spaces/cli/config"
 )
@@ -16,7 +18,15 @@ func main() {
 
 	app := GetCLI()
 
-	err = app.Run(os.Args)
+	// cancel the running command on CTRL+C, a second one exits right away
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	err = app.RunContext(ctx, os.Args)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -2,6 +2,7 @@ package util
 
 import (
 	"fmt"
+	"os"
 	"time"
 
 	"github.com/schollz/progressbar/v3"
@@ -41,5 +42,9 @@ func (u *UnknownBar) SetDescription(description string) {
 	time.Sleep(100 * time.Millisecond)
 	u.Bar.Describe(description)
 	// print unicode done on the start of the bar
-	fmt.Println("\r\u2713")
+	fmt.Fprintln(os.Stderr, "\r\u2713")
+}
+
+func NewFileBar(description string, size int64) *progressbar.ProgressBar {
+	return progressbar.DefaultBytes(size, description)
 }
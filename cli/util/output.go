@@ -0,0 +1,47 @@
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/felipemarinho97/dev-spaces/core"
+	"github.com/urfave/cli/v2"
+	"gopkg.in/yaml.v2"
+)
+
+// GetOutputFormat returns the output format set on the command or, when not
+// set, the global one
+func GetOutputFormat(c *cli.Context) core.OutputFormat {
+	for _, ctx := range c.Lineage() {
+		if output := ctx.String("output"); output != "" {
+			return core.OutputFormat(output)
+		}
+	}
+
+	return core.OutputFormatTable
+}
+
+// IsStructuredOutput returns whether the output format is json or yaml
+func IsStructuredOutput(format core.OutputFormat) bool {
+	return format == core.OutputFormatJSON || format == core.OutputFormatYAML
+}
+
+// PrintOutput writes v to stdout in the json or yaml output format
+func PrintOutput(format core.OutputFormat, v interface{}) error {
+	switch format {
+	case core.OutputFormatJSON:
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(v)
+	case core.OutputFormatYAML:
+		out, err := yaml.Marshal(v)
+		if err != nil {
+			return err
+		}
+		_, err = os.Stdout.Write(out)
+		return err
+	}
+
+	return fmt.Errorf("unsupported output format: %s", format)
+}
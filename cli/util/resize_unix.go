@@ -0,0 +1,37 @@
+//go:build !windows
+
+package util
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/felipemarinho97/dev-spaces/core/util/ssh"
+	"golang.org/x/term"
+)
+
+// WatchTerminalSize sends the new size of the terminal when it is resized
+func WatchTerminalSize(fd int) (<-chan ssh.WindowSize, func()) {
+	sizes := make(chan ssh.WindowSize, 1)
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGWINCH)
+
+	go func() {
+		for range sig {
+			width, height, err := term.GetSize(fd)
+			if err != nil {
+				continue
+			}
+			select {
+			case sizes <- ssh.WindowSize{Width: width, Height: height}:
+			default:
+			}
+		}
+	}()
+
+	return sizes, func() {
+		signal.Stop(sig)
+		close(sig)
+	}
+}
@@ -0,0 +1,43 @@
+//go:build windows
+
+package util
+
+import (
+	"time"
+
+	"github.com/felipemarinho97/dev-spaces/core/util/ssh"
+	"golang.org/x/term"
+)
+
+// WatchTerminalSize sends the new size of the terminal when it is resized,
+// windows has no SIGWINCH so the size is polled
+func WatchTerminalSize(fd int) (<-chan ssh.WindowSize, func()) {
+	sizes := make(chan ssh.WindowSize, 1)
+	done := make(chan struct{})
+
+	go func() {
+		width, height, _ := term.GetSize(fd)
+		ticker := time.NewTicker(500 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				w, h, err := term.GetSize(fd)
+				if err != nil || (w == width && h == height) {
+					continue
+				}
+				width, height = w, h
+				select {
+				case sizes <- ssh.WindowSize{Width: width, Height: height}:
+				default:
+				}
+			}
+		}
+	}()
+
+	return sizes, func() {
+		close(done)
+	}
+}
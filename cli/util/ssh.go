@@ -13,7 +13,9 @@ const (
 	customSSHConfigPath = "config.d/dev-spaces"
 )
 
-func CreateSSHConfig(config config.Config, ip, name string) (string, error) {
+// CreateSSHConfig writes the SSH config entry of a dev space, where host is
+// its stable hostname when dynamic DNS is enabled or its public IP
+func CreateSSHConfig(config config.Config, host, name string) (string, error) {
 	// get the custom ssh config path
 	sshConfigPath, err := getSSHConfigPath()
 	if err != nil {
@@ -25,7 +27,7 @@ func CreateSSHConfig(config config.Config, ip, name string) (string, error) {
 	name = re.ReplaceAllString(name, "$1")
 
 	// add entry to ssh config
-	err = putConfigEntry(sshConfigPath, name, ip)
+	err = putConfigEntry(sshConfigPath, name, host)
 	if err != nil {
 		return "", err
 	}
@@ -2,9 +2,11 @@ package util
 
 import (
 	"fmt"
+	"net"
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 )
 
 type InstanceSpec struct {
@@ -94,3 +96,137 @@ func ParseAMIFilter(filter string) (AMIFilter, error) {
 
 	return amiFilter, nil
 }
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+func ParseWeekdays(days string) ([]time.Weekday, error) {
+	// days format: "mon-fri", "mon,wed,fri" or "mon-wed,sat"
+	var out []time.Weekday
+	for _, part := range strings.Split(strings.ToLower(days), ",") {
+		from, to, isRange := strings.Cut(strings.TrimSpace(part), "-")
+		start, ok := weekdays[from]
+		if !ok {
+			return nil, fmt.Errorf("invalid weekday: %s", from)
+		}
+		if !isRange {
+			out = append(out, start)
+			continue
+		}
+
+		end, ok := weekdays[to]
+		if !ok {
+			return nil, fmt.Errorf("invalid weekday: %s", to)
+		}
+		for d := start; ; d = (d + 1) % 7 {
+			out = append(out, d)
+			if d == end {
+				break
+			}
+		}
+	}
+
+	return out, nil
+}
+
+type ForwardSpec struct {
+	LocalAddr  string
+	RemoteAddr string
+}
+
+func ParseForwardSpec(spec string) (ForwardSpec, error) {
+	// spec format: "8080:localhost:80" or "127.0.0.1:8080:localhost:80"
+	parts := strings.Split(spec, ":")
+	var bind, localPort, host, remotePort string
+	switch len(parts) {
+	case 3:
+		bind, localPort, host, remotePort = "127.0.0.1", parts[0], parts[1], parts[2]
+	case 4:
+		bind, localPort, host, remotePort = parts[0], parts[1], parts[2], parts[3]
+	default:
+		return ForwardSpec{}, fmt.Errorf("invalid forward spec: %s, expected [bind_address:]port:host:hostport", spec)
+	}
+
+	for _, port := range []string{localPort, remotePort} {
+		p, err := strconv.Atoi(port)
+		if err != nil || p < 0 || p > 65535 {
+			return ForwardSpec{}, fmt.Errorf("invalid port in forward spec: %s", port)
+		}
+	}
+	if host == "" {
+		return ForwardSpec{}, fmt.Errorf("invalid host in forward spec: %s", spec)
+	}
+
+	return ForwardSpec{
+		LocalAddr:  net.JoinHostPort(bind, localPort),
+		RemoteAddr: net.JoinHostPort(host, remotePort),
+	}, nil
+}
+
+func ParseRemotePath(arg string) (name string, path string, remote bool) {
+	// remote format: "<name>:<path>", windows drive letters (e.g. C:\) are local
+	name, path, found := strings.Cut(arg, ":")
+	if !found || name == "" || strings.ContainsAny(name, `/\`) || (len(name) == 1 && strings.HasPrefix(path, `\`)) {
+		return "", arg, false
+	}
+	if path == "" {
+		path = "."
+	}
+
+	return name, path, true
+}
+
+// LineDiff returns the lines removed from old prefixed with "- ", and the
+// lines added by new prefixed with "+ ", in the order they appear
+func LineDiff(old, new string) []string {
+	a := splitLines(old)
+	b := splitLines(new)
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var diff []string
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			i++
+			j++
+		case j == len(b) || (i < len(a) && lcs[i+1][j] >= lcs[i][j+1]):
+			diff = append(diff, "- "+a[i])
+			i++
+		default:
+			diff = append(diff, "+ "+b[j])
+			j++
+		}
+	}
+
+	return diff
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
@@ -3,6 +3,7 @@ package util
 import (
 	"reflect"
 	"testing"
+	"time"
 )
 
 func TestParseInstanceSpec(t *testing.T) {
@@ -193,3 +194,241 @@ func TestParseAMIFilter(t *testing.T) {
 		})
 	}
 }
+
+func TestParseWeekdays(t *testing.T) {
+	type args struct {
+		days string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    []time.Weekday
+		wantErr bool
+	}{
+		{
+			name: "a single day",
+			args: args{
+				days: "mon",
+			},
+			want:    []time.Weekday{time.Monday},
+			wantErr: false,
+		},
+		{
+			name: "a range of days",
+			args: args{
+				days: "mon-fri",
+			},
+			want:    []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+			wantErr: false,
+		},
+		{
+			name: "a range of days crossing the weekend",
+			args: args{
+				days: "fri-mon",
+			},
+			want:    []time.Weekday{time.Friday, time.Saturday, time.Sunday, time.Monday},
+			wantErr: false,
+		},
+		{
+			name: "a list of days and ranges with spaces",
+			args: args{
+				days: "Mon, wed-thu, sat",
+			},
+			want:    []time.Weekday{time.Monday, time.Wednesday, time.Thursday, time.Saturday},
+			wantErr: false,
+		},
+		{
+			name: "an invalid day",
+			args: args{
+				days: "mon-fry",
+			},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseWeekdays(tt.args.days)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseWeekdays() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseWeekdays() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseForwardSpec(t *testing.T) {
+	type args struct {
+		spec string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    ForwardSpec
+		wantErr bool
+	}{
+		{
+			name: "a port forward to localhost",
+			args: args{
+				spec: "8080:localhost:8080",
+			},
+			want: ForwardSpec{
+				LocalAddr:  "127.0.0.1:8080",
+				RemoteAddr: "localhost:8080",
+			},
+			wantErr: false,
+		},
+		{
+			name: "a port forward with a bind address",
+			args: args{
+				spec: "0.0.0.0:5432:db.internal:5432",
+			},
+			want: ForwardSpec{
+				LocalAddr:  "0.0.0.0:5432",
+				RemoteAddr: "db.internal:5432",
+			},
+			wantErr: false,
+		},
+		{
+			name: "a port forward without the remote host",
+			args: args{
+				spec: "8080:8080",
+			},
+			want:    ForwardSpec{},
+			wantErr: true,
+		},
+		{
+			name: "an invalid port",
+			args: args{
+				spec: "8080:localhost:http",
+			},
+			want:    ForwardSpec{},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseForwardSpec(tt.args.spec)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseForwardSpec() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseForwardSpec() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRemotePath(t *testing.T) {
+	type args struct {
+		arg string
+	}
+	tests := []struct {
+		name       string
+		args       args
+		wantName   string
+		wantPath   string
+		wantRemote bool
+	}{
+		{
+			name: "a remote path",
+			args: args{
+				arg: "MySpace:/root/data",
+			},
+			wantName:   "MySpace",
+			wantPath:   "/root/data",
+			wantRemote: true,
+		},
+		{
+			name: "a remote home directory",
+			args: args{
+				arg: "MySpace:",
+			},
+			wantName:   "MySpace",
+			wantPath:   ".",
+			wantRemote: true,
+		},
+		{
+			name: "a local path",
+			args: args{
+				arg: "./data:backup",
+			},
+			wantName:   "",
+			wantPath:   "./data:backup",
+			wantRemote: false,
+		},
+		{
+			name: "a windows local path",
+			args: args{
+				arg: `C:\data`,
+			},
+			wantName:   "",
+			wantPath:   `C:\data`,
+			wantRemote: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotName, gotPath, gotRemote := ParseRemotePath(tt.args.arg)
+			if gotName != tt.wantName || gotPath != tt.wantPath || gotRemote != tt.wantRemote {
+				t.Errorf("ParseRemotePath() = %v, %v, %v, want %v, %v, %v", gotName, gotPath, gotRemote, tt.wantName, tt.wantPath, tt.wantRemote)
+			}
+		})
+	}
+}
+
+func TestLineDiff(t *testing.T) {
+	type args struct {
+		old string
+		new string
+	}
+	tests := []struct {
+		name string
+		args args
+		want []string
+	}{
+		{
+			name: "equal",
+			args: args{
+				old: "a\nb",
+				new: "a\nb",
+			},
+			want: nil,
+		},
+		{
+			name: "a changed line",
+			args: args{
+				old: "a\nb\nc",
+				new: "a\nB\nc",
+			},
+			want: []string{"- b", "+ B"},
+		},
+		{
+			name: "added and removed lines",
+			args: args{
+				old: "a\nb\nc",
+				new: "b\nc\nd",
+			},
+			want: []string{"- a", "+ d"},
+		},
+		{
+			name: "from empty",
+			args: args{
+				old: "",
+				new: "a",
+			},
+			want: []string{"+ a"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := LineDiff(tt.args.old, tt.args.new); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("LineDiff() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,418 @@
+package core
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/felipemarinho97/dev-spaces/core/helpers"
+	"github.com/felipemarinho97/dev-spaces/core/util"
+)
+
+const (
+	// ApplyCreate is the action of Apply when the dev space does not exist
+	ApplyCreate = "create"
+	// ApplyUpdate is the action of Apply when the dev space differs from its spec
+	ApplyUpdate = "update"
+	// ApplyNone is the action of Apply when the dev space matches its spec
+	ApplyNone = "none"
+)
+
+// DevSpaceSpec describes a dev space, so it can be kept in git and applied
+type DevSpaceSpec struct {
+	// Name of the dev space
+	Name string `yaml:"name" validate:"required,min=3,max=128"`
+	// DevSpaceAMI is the image the volume is created from, only used on creation
+	DevSpaceAMI AMIFilter `yaml:"ami"`
+	// HostAMI is the image of the host (defaults to the minimal Amazon Linux
+	// on creation, and to the current one afterwards)
+	HostAMI *AMIFilter `yaml:"host_ami"`
+	// StorageSize is the size of the volume in GB, volumes can only grow
+	StorageSize int `yaml:"storage_size" validate:"min=0"`
+	// PreferedLaunchSpecs of the instance creating the volume, only used on creation
+	PreferedLaunchSpecs PreferedLaunchSpecs `yaml:"prefered_instance_specs"`
+	// KeyName is the name of the key pair
+	KeyName string `yaml:"key_name" validate:"required"`
+	// SecurityGroupIds are added to the security group of the dev space
+	SecurityGroupIds []string `yaml:"security_group_ids"`
+	// InstanceProfileArn of the instances (optional)
+	InstanceProfileArn string `yaml:"instance_profile_arn"`
+	// Tags of the launch template and the instances
+	Tags map[string]string `yaml:"tags"`
+	// StartupScript of the instances (defaults to DEFAULT_STARTUP_SCRIPT)
+	StartupScript string `yaml:"startup_script"`
+}
+
+// LoadDevSpaceSpec loads and validates a dev space spec from a path or URL
+func LoadDevSpaceSpec(path string) (DevSpaceSpec, error) {
+	var spec DevSpaceSpec
+	err := util.LoadYAML(path, &spec)
+	if err != nil {
+		return DevSpaceSpec{}, fmt.Errorf("error loading spec: %v", err)
+	}
+	err = util.Validator.Struct(spec)
+	if err != nil {
+		return DevSpaceSpec{}, fmt.Errorf("error validating spec: %v", err)
+	}
+
+	return spec, nil
+}
+
+type ApplyOptions struct {
+	// Spec of the dev space
+	Spec DevSpaceSpec
+	// DryRun only computes the changes, without applying them
+	DryRun bool
+	// SSHKey is the path of the SSH key, required to grow the filesystem
+	// when the dev space is running
+	SSHKey string
+}
+
+// SpecChange is a difference between a spec and its dev space
+type SpecChange struct {
+	// Field of the spec, e.g. key_name or tags.team
+	Field string `json:"field" yaml:"field"`
+	// Old is the current value, empty when the field is added
+	Old string `json:"old" yaml:"old"`
+	// New is the value of the spec, empty when the field is removed
+	New string `json:"new" yaml:"new"`
+}
+
+type ApplyOutput struct {
+	// Name of the dev space
+	Name string `json:"name" yaml:"name"`
+	// Action is either "create", "update" or "none"
+	Action string `json:"action" yaml:"action"`
+	// Changes applied, or to be applied on a dry run
+	Changes []SpecChange `json:"changes" yaml:"changes"`
+	// DryRun is true when the changes were not applied
+	DryRun bool `json:"dry_run" yaml:"dry_run"`
+	// LaunchTemplateID of the dev space, empty on a dry run creation
+	LaunchTemplateID string `json:"launch_template_id" yaml:"launch_template_id"`
+	// Version is the default launch template version, 0 when not changed
+	Version int64 `json:"version" yaml:"version"`
+}
+
+// Apply creates the dev space of a spec when it does not exist. Otherwise, a
+// new launch template version is created and set as default when the spec
+// differs from the current one, tags are updated and the volume is grown
+func (h *Handler) Apply(ctx context.Context, opts ApplyOptions) (_ ApplyOutput, err error) {
+	client := h.EC2Client
+	spec := opts.Spec
+
+	err = util.Validator.Struct(spec)
+	if err != nil {
+		return ApplyOutput{}, err
+	}
+	for key := range spec.Tags {
+		if key == "Name" || key == "managed-by" || strings.HasPrefix(key, "dev-spaces:") {
+			return ApplyOutput{}, fmt.Errorf("tag %s is reserved by dev-spaces", key)
+		}
+	}
+
+	startupScript := spec.StartupScript
+	if startupScript == "" {
+		startupScript = DEFAULT_STARTUP_SCRIPT
+	}
+
+	exists, err := helpers.TemplateExists(ctx, client, spec.Name)
+	if err != nil {
+		return ApplyOutput{}, err
+	}
+	if !exists {
+		return h.applyCreate(ctx, spec, startupScript, opts.DryRun)
+	}
+
+	name := spec.Name
+	t := h.track("apply", name)
+	defer func() { t.Done(err) }()
+
+	t.Phase("diff", "Comparing the spec with the launch template...")
+	template, err := helpers.GetLaunchTemplateByName(ctx, client, name)
+	if err != nil {
+		return ApplyOutput{}, err
+	}
+	current, err := helpers.GetDefaultLaunchTemplateVersion(ctx, client, *template.LaunchTemplateId)
+	if err != nil {
+		return ApplyOutput{}, err
+	}
+	data := current.LaunchTemplateData
+
+	var hostImage *types.Image
+	hostImageID := util.GetValue(data.ImageId)
+	if spec.HostAMI != nil {
+		// the new host image keeps the architecture of the current one
+		currentImage, err := helpers.GetImage(ctx, client, hostImageID)
+		if err != nil {
+			return ApplyOutput{}, err
+		}
+		hostFilter, err := spec.HostAMI.forArch(string(currentImage.Architecture))
+		if err != nil {
+			return ApplyOutput{}, fmt.Errorf("host_ami: %v", err)
+		}
+		hostImage, err = helpers.GetImageFromFilter(ctx, client, hostFilter)
+		if err != nil {
+			return ApplyOutput{}, err
+		}
+		hostImageID = *hostImage.ImageId
+	}
+
+	// the security group of the dev space is kept
+	securityGroupIds := append([]string{}, spec.SecurityGroupIds...)
+	groupID, err := helpers.GetSecurityGroupID(ctx, client, name)
+	if err != nil {
+		return ApplyOutput{}, err
+	}
+	if groupID != "" {
+		securityGroupIds = append(securityGroupIds, groupID)
+	}
+
+	var instanceProfileArn string
+	if data.IamInstanceProfile != nil {
+		instanceProfileArn = util.GetValue(data.IamInstanceProfile.Arn)
+	}
+	currentScript, err := base64.StdEncoding.DecodeString(util.GetValue(data.UserData))
+	if err != nil {
+		return ApplyOutput{}, err
+	}
+
+	var changes []SpecChange
+	diff := func(field, old, new string) {
+		if old != new {
+			changes = append(changes, SpecChange{Field: field, Old: old, New: new})
+		}
+	}
+	diff("host_image", util.GetValue(data.ImageId), hostImageID)
+	diff("key_name", util.GetValue(data.KeyName), spec.KeyName)
+	diff("security_group_ids", joinSorted(data.SecurityGroupIds), joinSorted(securityGroupIds))
+	diff("instance_profile_arn", instanceProfileArn, spec.InstanceProfileArn)
+	diff("startup_script", string(currentScript), startupScript)
+	versionChanges := len(changes)
+	versionChanged := versionChanges > 0
+
+	currentTags := specTags(template.Tags)
+	for _, key := range sortedKeys(currentTags, spec.Tags) {
+		diff("tags."+key, currentTags[key], spec.Tags[key])
+	}
+	tagsChanged := len(changes) > versionChanges
+
+	// volumes can only grow, and archived dev spaces have no volume
+	var volumeSize int32
+	volumeID := util.GetTag(template.Tags, "dev-spaces:volume-id")
+	if spec.StorageSize > 0 && volumeID != "" && util.GetTag(template.Tags, snapshotIDTag) == "" {
+		volume, err := helpers.GetEBSVolume(ctx, client, volumeID)
+		if err != nil {
+			return ApplyOutput{}, err
+		}
+		volumeSize = *volume.Size
+		if int32(spec.StorageSize) < volumeSize {
+			t.Warn(fmt.Sprintf("storage_size %d GB is smaller than the volume (%d GB), volumes can only grow", spec.StorageSize, volumeSize), nil)
+		} else {
+			diff("storage_size", fmt.Sprint(volumeSize), fmt.Sprint(spec.StorageSize))
+		}
+	}
+
+	out := ApplyOutput{
+		Name:             name,
+		Action:           ApplyNone,
+		Changes:          changes,
+		DryRun:           opts.DryRun,
+		LaunchTemplateID: *template.LaunchTemplateId,
+	}
+	if len(changes) > 0 {
+		out.Action = ApplyUpdate
+	}
+	if opts.DryRun || len(changes) == 0 {
+		return out, nil
+	}
+
+	// the tags are part of the launch template data too
+	if versionChanged || tagsChanged {
+		t.Phase("launch-template", "Creating a new launch template version...")
+		device := helpers.CreateLaunchTemplateHostDevice{}
+		if hostImage != nil && hostImageID != util.GetValue(data.ImageId) {
+			device = helpers.CreateLaunchTemplateHostDevice{
+				Name:       *hostImage.RootDeviceName,
+				Size:       *hostImage.BlockDeviceMappings[0].Ebs.VolumeSize,
+				Type:       string(hostImage.BlockDeviceMappings[0].Ebs.VolumeType),
+				IOPS:       hostImage.BlockDeviceMappings[0].Ebs.Iops,
+				Throughput: hostImage.BlockDeviceMappings[0].Ebs.Throughput,
+			}
+		} else if len(data.BlockDeviceMappings) > 0 && data.BlockDeviceMappings[0].Ebs != nil {
+			mapping := data.BlockDeviceMappings[0]
+			device = helpers.CreateLaunchTemplateHostDevice{
+				Name:       util.GetValue(mapping.DeviceName),
+				Size:       *mapping.Ebs.VolumeSize,
+				Type:       string(mapping.Ebs.VolumeType),
+				IOPS:       mapping.Ebs.Iops,
+				Throughput: mapping.Ebs.Throughput,
+			}
+		}
+
+		version, err := helpers.CreateLaunchTemplateVersion(ctx, client, *template.LaunchTemplateId, helpers.CreateLaunchTemplateInput{
+			Name:               name,
+			VolumeId:           volumeID,
+			VolumeZone:         util.GetTag(template.Tags, "dev-spaces:zone"),
+			StartupScript:      startupScript,
+			SecurityGroupIds:   securityGroupIds,
+			KeyName:            spec.KeyName,
+			InstanceProfileArn: &spec.InstanceProfileArn,
+			Tags:               spec.Tags,
+			Host: helpers.CreateLaunchTemplateHost{
+				AMIID:  hostImageID,
+				Device: device,
+			},
+		}, "dev-spaces apply")
+		if err != nil {
+			return out, err
+		}
+		out.Version = *version.VersionNumber
+		t.Created(ResourceLaunchTemplate, *template.LaunchTemplateId, fmt.Sprintf("Launch template version %d created and set as default", out.Version))
+	}
+
+	if tagsChanged {
+		t.Phase("tags", "Updating the tags of the launch template...")
+		var removed []string
+		for key := range currentTags {
+			if _, ok := spec.Tags[key]; !ok {
+				removed = append(removed, key)
+			}
+		}
+		if len(removed) > 0 {
+			err = helpers.RemoveLaunchTemplateTags(ctx, client, *template.LaunchTemplateId, removed...)
+			if err != nil {
+				return out, err
+			}
+		}
+		if len(spec.Tags) > 0 {
+			err = helpers.SetLaunchTemplateTags(ctx, client, *template.LaunchTemplateId, spec.Tags)
+			if err != nil {
+				return out, err
+			}
+		}
+	}
+
+	if volumeSize > 0 && int32(spec.StorageSize) > volumeSize {
+		t.Phase("volume", fmt.Sprintf("Growing the volume to %d GB...", spec.StorageSize))
+		_, err = h.Resize(ctx, ResizeOptions{
+			Name:   name,
+			Size:   int32(spec.StorageSize),
+			SSHKey: opts.SSHKey,
+		})
+		if err != nil {
+			return out, err
+		}
+	}
+
+	return out, nil
+}
+
+// applyCreate creates the dev space of a spec
+func (h *Handler) applyCreate(ctx context.Context, spec DevSpaceSpec, startupScript string, dryRun bool) (ApplyOutput, error) {
+	var changes []SpecChange
+	add := func(field, value string) {
+		if value != "" {
+			changes = append(changes, SpecChange{Field: field, New: value})
+		}
+	}
+	add("ami", spec.DevSpaceAMI.String())
+	if spec.HostAMI != nil {
+		add("host_ami", spec.HostAMI.String())
+	}
+	if spec.StorageSize > 0 {
+		add("storage_size", fmt.Sprint(spec.StorageSize))
+	}
+	add("key_name", spec.KeyName)
+	add("security_group_ids", joinSorted(spec.SecurityGroupIds))
+	add("instance_profile_arn", spec.InstanceProfileArn)
+	add("startup_script", startupScript)
+	for _, key := range sortedKeys(spec.Tags) {
+		add("tags."+key, spec.Tags[key])
+	}
+
+	out := ApplyOutput{
+		Name:    spec.Name,
+		Action:  ApplyCreate,
+		Changes: changes,
+		DryRun:  dryRun,
+	}
+	if dryRun {
+		return out, nil
+	}
+
+	created, err := h.Create(ctx, CreateOptions{
+		Name:                spec.Name,
+		DevSpaceAMI:         spec.DevSpaceAMI,
+		PreferedLaunchSpecs: spec.PreferedLaunchSpecs,
+		KeyName:             spec.KeyName,
+		InstanceProfileArn:  spec.InstanceProfileArn,
+		StartupScript:       startupScript,
+		SecurityGroupIds:    spec.SecurityGroupIds,
+		StorageSize:         spec.StorageSize,
+		HostAMI:             spec.HostAMI,
+		Tags:                spec.Tags,
+	})
+	if err != nil {
+		return out, err
+	}
+	out.LaunchTemplateID = *created.LaunchTemplateId
+	out.Version = 1
+
+	return out, nil
+}
+
+// String describes the filter, e.g. "name=debian-12-*, arch=x86_64, owner=amazon"
+func (f AMIFilter) String() string {
+	var parts []string
+	for _, part := range [][2]string{{"id", f.ID}, {"name", f.Name}, {"arch", f.Arch}, {"owner", f.Owner}} {
+		if part[1] != "" {
+			parts = append(parts, part[0]+"="+part[1])
+		}
+	}
+	for _, arch := range sortedKeys(f.Names) {
+		parts = append(parts, fmt.Sprintf("names.%s=%s", arch, f.Names[arch]))
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// specTags returns the tags of a launch template that are not managed by dev-spaces
+func specTags(tags []types.Tag) map[string]string {
+	values := map[string]string{}
+	for _, tag := range tags {
+		key := util.GetValue(tag.Key)
+		if key == "Name" || key == "managed-by" || strings.HasPrefix(key, "dev-spaces:") {
+			continue
+		}
+		values[key] = util.GetValue(tag.Value)
+	}
+
+	return values
+}
+
+// sortedKeys returns the keys of the maps, sorted and without duplicates
+func sortedKeys(maps ...map[string]string) []string {
+	seen := map[string]bool{}
+	var keys []string
+	for _, m := range maps {
+		for key := range m {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func joinSorted(values []string) string {
+	sorted := append([]string{}, values...)
+	sort.Strings(sorted)
+
+	return strings.Join(sorted, ",")
+}
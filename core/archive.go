@@ -0,0 +1,238 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/felipemarinho97/dev-spaces/core/helpers"
+	"github.com/felipemarinho97/dev-spaces/core/util"
+)
+
+const (
+	snapshotIDTag = "dev-spaces:snapshot-id"
+	archivedAtTag = "dev-spaces:archived-at"
+)
+
+type ArchiveOptions struct {
+	// Name of the dev space
+	Name string `validate:"required"`
+}
+
+type ArchiveOutput struct {
+	// SnapshotID of the snapshot that holds the dev space data
+	SnapshotID string `json:"snapshot_id" yaml:"snapshot_id"`
+	// VolumeID of the deleted volume
+	VolumeID string `json:"volume_id" yaml:"volume_id"`
+}
+
+// Archive moves a stopped dev space to cold storage: the volume is
+// snapshotted and deleted, and the snapshot ID is recorded on the launch template
+func (h *Handler) Archive(ctx context.Context, opts ArchiveOptions) (_ ArchiveOutput, err error) {
+	err = util.Validator.Struct(opts)
+	if err != nil {
+		return ArchiveOutput{}, err
+	}
+
+	client := h.EC2Client
+	name, _ := util.GetTemplateNameAndVersion(opts.Name)
+	t := h.track("archive", name)
+	defer func() { t.Done(err) }()
+
+	t.Phase("prepare", "Checking the dev space...")
+	template, err := helpers.GetLaunchTemplateByName(ctx, client, name)
+	if err != nil {
+		return ArchiveOutput{}, err
+	}
+
+	if snapshotID := util.GetTag(template.Tags, snapshotIDTag); snapshotID != "" {
+		return ArchiveOutput{}, fmt.Errorf("dev space %s is already archived in snapshot %s", name, snapshotID)
+	}
+
+	volumeID := util.GetTag(template.Tags, "dev-spaces:volume-id")
+	if volumeID == "" {
+		return ArchiveOutput{}, errors.New("unable to find volume ID")
+	}
+
+	isAttached, err := helpers.IsEBSAttached(ctx, client, volumeID)
+	if err != nil {
+		return ArchiveOutput{}, err
+	}
+	if isAttached {
+		return ArchiveOutput{}, errors.New("make sure the dev-space is not running")
+	}
+
+	t.Phase("snapshot", fmt.Sprintf("Creating a snapshot of volume %s...", volumeID))
+	snapshotID, err := helpers.CreateSnapshot(ctx, client, volumeID)
+	if err != nil {
+		return ArchiveOutput{}, err
+	}
+	t.Created(ResourceSnapshot, snapshotID, fmt.Sprintf("Created snapshot %s", snapshotID))
+
+	_, err = client.CreateTags(ctx, &ec2.CreateTagsInput{
+		Resources: []string{snapshotID},
+		Tags:      util.GenerateTags(name),
+	})
+	if err != nil {
+		return ArchiveOutput{}, err
+	}
+
+	t.Waiting(ResourceSnapshot, snapshotID, fmt.Sprintf("Waiting for snapshot %s to complete...", snapshotID))
+	err = helpers.WaitForSnapshot(ctx, client, snapshotID)
+	if err != nil {
+		return ArchiveOutput{}, err
+	}
+
+	// record the snapshot before deleting the volume, so the data is never unreferenced
+	err = helpers.SetLaunchTemplateTags(ctx, client, *template.LaunchTemplateId, map[string]string{
+		snapshotIDTag: snapshotID,
+		archivedAtTag: time.Now().UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		return ArchiveOutput{}, err
+	}
+
+	err = helpers.RemoveLaunchTemplateTags(ctx, client, *template.LaunchTemplateId, "dev-spaces:volume-id")
+	if err != nil {
+		return ArchiveOutput{}, err
+	}
+
+	t.Phase("delete-volume", fmt.Sprintf("Deleting volume %s...", volumeID))
+	err = helpers.DeleteEBSVolume(ctx, client, volumeID)
+	if err != nil {
+		return ArchiveOutput{}, err
+	}
+
+	return ArchiveOutput{
+		SnapshotID: snapshotID,
+		VolumeID:   volumeID,
+	}, nil
+}
+
+type UnarchiveOptions struct {
+	// Name of the dev space
+	Name string `validate:"required"`
+	// KeepSnapshot keeps the snapshot after the volume is restored
+	KeepSnapshot bool
+}
+
+type UnarchiveOutput struct {
+	// VolumeID of the restored volume
+	VolumeID string `json:"volume_id" yaml:"volume_id"`
+	// SnapshotID of the snapshot the volume was restored from
+	SnapshotID string `json:"snapshot_id" yaml:"snapshot_id"`
+	// Zone of the restored volume
+	Zone string `json:"zone" yaml:"zone"`
+}
+
+// Unarchive restores the volume of an archived dev space from its snapshot,
+// in the zone of the dev space
+func (h *Handler) Unarchive(ctx context.Context, opts UnarchiveOptions) (_ UnarchiveOutput, err error) {
+	err = util.Validator.Struct(opts)
+	if err != nil {
+		return UnarchiveOutput{}, err
+	}
+
+	name, _ := util.GetTemplateNameAndVersion(opts.Name)
+	t := h.track("unarchive", name)
+	defer func() { t.Done(err) }()
+
+	t.Phase("prepare", "Checking the dev space...")
+	template, err := helpers.GetLaunchTemplateByName(ctx, h.EC2Client, name)
+	if err != nil {
+		return UnarchiveOutput{}, err
+	}
+
+	if util.GetTag(template.Tags, snapshotIDTag) == "" {
+		return UnarchiveOutput{}, fmt.Errorf("dev space %s is not archived", name)
+	}
+
+	return h.unarchive(ctx, t, template, opts.KeepSnapshot)
+}
+
+// unarchive restores the volume of an archived dev space, reporting its
+// progress on the tracker of the running operation
+func (h *Handler) unarchive(ctx context.Context, t *tracker, template *types.LaunchTemplate, keepSnapshot bool) (_ UnarchiveOutput, err error) {
+	client := h.EC2Client
+	name := *template.LaunchTemplateName
+
+	snapshotID := util.GetTag(template.Tags, snapshotIDTag)
+	zone := util.GetTag(template.Tags, "dev-spaces:zone")
+	if zone == "" {
+		return UnarchiveOutput{}, errors.New("unable to find the dev space zone")
+	}
+
+	t.Phase("restore-volume", fmt.Sprintf("Dev space is archived, restoring its volume from snapshot %s in %s...", snapshotID, zone))
+	volume, err := helpers.CreateEBSVolumeFromSnapshot(ctx, client, name, snapshotID, zone)
+	if err != nil {
+		return UnarchiveOutput{}, err
+	}
+	t.Created(ResourceVolume, *volume.VolumeId, fmt.Sprintf("Created volume %s", *volume.VolumeId))
+
+	// the dev space stays archived until the snapshot tags are removed, so
+	// the new volume is deleted if that does not happen
+	var tagged, unarchived bool
+	defer func() {
+		if err == nil || unarchived {
+			return
+		}
+		cleanupCtx := context.WithoutCancel(ctx)
+		if tagged {
+			err := helpers.RemoveLaunchTemplateTags(cleanupCtx, client, *template.LaunchTemplateId, "dev-spaces:volume-id")
+			if err != nil {
+				t.Warn(fmt.Sprintf("Error removing volume %s from the launch template", *volume.VolumeId), err)
+				return
+			}
+		}
+		h.deleteRestoredVolume(cleanupCtx, t, *volume.VolumeId)
+	}()
+
+	t.Waiting(ResourceVolume, *volume.VolumeId, fmt.Sprintf("Waiting for volume %s to be available...", *volume.VolumeId))
+	err = helpers.WaitForEBSVolume(ctx, client, *volume.VolumeId, types.VolumeStateAvailable)
+	if err != nil {
+		return UnarchiveOutput{}, err
+	}
+
+	err = helpers.SetLaunchTemplateTags(ctx, client, *template.LaunchTemplateId, map[string]string{
+		"dev-spaces:volume-id": *volume.VolumeId,
+	})
+	if err != nil {
+		return UnarchiveOutput{}, err
+	}
+	tagged = true
+
+	err = helpers.RemoveLaunchTemplateTags(ctx, client, *template.LaunchTemplateId, snapshotIDTag, archivedAtTag)
+	if err != nil {
+		return UnarchiveOutput{}, err
+	}
+	unarchived = true
+
+	if !keepSnapshot {
+		t.Phase("delete-snapshot", fmt.Sprintf("Deleting snapshot %s...", snapshotID))
+		_, err = client.DeleteSnapshot(ctx, &ec2.DeleteSnapshotInput{
+			SnapshotId: aws.String(snapshotID),
+		})
+		if err != nil {
+			return UnarchiveOutput{}, err
+		}
+	}
+
+	return UnarchiveOutput{
+		VolumeID:   *volume.VolumeId,
+		SnapshotID: snapshotID,
+		Zone:       zone,
+	}, nil
+}
+
+// deleteRestoredVolume deletes a volume created from a snapshot by an
+// operation that failed before the dev space used it
+func (h *Handler) deleteRestoredVolume(ctx context.Context, t *tracker, volumeID string) {
+	err := helpers.DeleteEBSVolume(ctx, h.EC2Client, volumeID)
+	if err != nil {
+		t.Warn(fmt.Sprintf("Error deleting volume %s", volumeID), err)
+	}
+}
@@ -0,0 +1,389 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/felipemarinho97/dev-spaces/core/helpers"
+	"github.com/felipemarinho97/dev-spaces/core/util"
+)
+
+const (
+	backupTag     = "dev-spaces:backup"
+	backupKeepTag = "dev-spaces:backup-keep"
+)
+
+type BackupItem struct {
+	// Name of the dev space
+	Name string `json:"name" yaml:"name"`
+	// SnapshotID of the backup
+	SnapshotID string `json:"snapshot_id" yaml:"snapshot_id"`
+	// VolumeID of the volume the backup was taken from
+	VolumeID string `json:"volume_id" yaml:"volume_id"`
+	// State of the snapshot (pending, completed or error)
+	State string `json:"state" yaml:"state"`
+	// Progress of the snapshot (e.g. 100%)
+	Progress string `json:"progress" yaml:"progress"`
+	// Size of the volume in GB
+	Size int32 `json:"size" yaml:"size"`
+	// StartTime is when the backup was taken
+	StartTime time.Time `json:"start_time" yaml:"start_time"`
+	// Description of the backup
+	Description string `json:"description" yaml:"description"`
+}
+
+type CreateBackupOptions struct {
+	// Name of the dev space
+	Name string `validate:"required"`
+	// Description of the backup (optional)
+	Description string
+	// Wait for the snapshot to complete
+	Wait bool
+}
+
+// CreateBackup takes a snapshot of the volume of a dev space. Backups of a
+// running dev space are crash-consistent
+func (h *Handler) CreateBackup(ctx context.Context, opts CreateBackupOptions) (_ BackupItem, err error) {
+	err = util.Validator.Struct(opts)
+	if err != nil {
+		return BackupItem{}, err
+	}
+
+	name, _ := util.GetTemplateNameAndVersion(opts.Name)
+	t := h.track("backup", name)
+	defer func() { t.Done(err) }()
+
+	t.Phase("prepare", "Checking the dev space...")
+	template, err := helpers.GetLaunchTemplateByName(ctx, h.EC2Client, name)
+	if err != nil {
+		return BackupItem{}, err
+	}
+
+	return h.createBackup(ctx, t, template, opts.Description, opts.Wait)
+}
+
+// createBackup snapshots the volume of a dev space, reporting its progress on
+// the tracker of the running operation
+func (h *Handler) createBackup(ctx context.Context, t *tracker, template *types.LaunchTemplate, description string, wait bool) (BackupItem, error) {
+	client := h.EC2Client
+	name := *template.LaunchTemplateName
+
+	volumeID := util.GetTag(template.Tags, "dev-spaces:volume-id")
+	if volumeID == "" {
+		if util.GetTag(template.Tags, snapshotIDTag) != "" {
+			return BackupItem{}, fmt.Errorf("dev space %s is archived", name)
+		}
+		return BackupItem{}, errors.New("unable to find volume ID")
+	}
+
+	t.Phase("backup", fmt.Sprintf("Creating a backup of volume %s...", volumeID))
+	snapshotID, err := helpers.CreateSnapshot(ctx, client, volumeID)
+	if err != nil {
+		return BackupItem{}, err
+	}
+	t.Created(ResourceSnapshot, snapshotID, fmt.Sprintf("Created backup %s", snapshotID))
+
+	tags := append(util.GenerateTags(name), types.Tag{
+		Key:   aws.String(backupTag),
+		Value: aws.String("true"),
+	})
+	if description != "" {
+		tags = append(tags, types.Tag{
+			Key:   aws.String("dev-spaces:description"),
+			Value: aws.String(description),
+		})
+	}
+	_, err = client.CreateTags(ctx, &ec2.CreateTagsInput{
+		Resources: []string{snapshotID},
+		Tags:      tags,
+	})
+	if err != nil {
+		return BackupItem{}, err
+	}
+
+	state := types.SnapshotStatePending
+	if wait {
+		t.Waiting(ResourceSnapshot, snapshotID, fmt.Sprintf("Waiting for snapshot %s to complete...", snapshotID))
+		err = helpers.WaitForSnapshot(ctx, client, snapshotID)
+		if err != nil {
+			return BackupItem{}, err
+		}
+		state = types.SnapshotStateCompleted
+	}
+
+	return BackupItem{
+		Name:        name,
+		SnapshotID:  snapshotID,
+		VolumeID:    volumeID,
+		State:       string(state),
+		StartTime:   time.Now(),
+		Description: description,
+	}, nil
+}
+
+type ListBackupsOptions struct {
+	// Name of the dev space, empty lists the backups of all dev spaces
+	Name string
+}
+
+// ListBackups returns the backups from newest to oldest
+func (h *Handler) ListBackups(ctx context.Context, opts ListBackupsOptions) ([]BackupItem, error) {
+	name, _ := util.GetTemplateNameAndVersion(opts.Name)
+
+	snapshots, err := helpers.GetBackupSnapshots(ctx, h.EC2Client, name)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]BackupItem, 0, len(snapshots))
+	for _, snapshot := range snapshots {
+		items = append(items, BackupItem{
+			Name:        util.GetTag(snapshot.Tags, "dev-spaces:name"),
+			SnapshotID:  *snapshot.SnapshotId,
+			VolumeID:    util.GetValue(snapshot.VolumeId),
+			State:       string(snapshot.State),
+			Progress:    util.GetValue(snapshot.Progress),
+			Size:        aws.ToInt32(snapshot.VolumeSize),
+			StartTime:   aws.ToTime(snapshot.StartTime),
+			Description: util.GetTag(snapshot.Tags, "dev-spaces:description"),
+		})
+	}
+
+	return items, nil
+}
+
+type RestoreBackupOptions struct {
+	// Name of the dev space
+	Name string `validate:"required"`
+	// SnapshotID of the backup to restore
+	SnapshotID string `validate:"required"`
+	// DeleteOldVolume deletes the replaced volume, otherwise it is kept
+	// until the dev space is destroyed
+	DeleteOldVolume bool
+}
+
+type RestoreBackupOutput struct {
+	// VolumeID of the restored volume
+	VolumeID string `json:"volume_id" yaml:"volume_id"`
+	// OldVolumeID of the replaced volume
+	OldVolumeID string `json:"old_volume_id" yaml:"old_volume_id"`
+}
+
+// RestoreBackup replaces the volume of a stopped dev space with a volume
+// created from one of its backups
+func (h *Handler) RestoreBackup(ctx context.Context, opts RestoreBackupOptions) (_ RestoreBackupOutput, err error) {
+	err = util.Validator.Struct(opts)
+	if err != nil {
+		return RestoreBackupOutput{}, err
+	}
+
+	client := h.EC2Client
+	name, _ := util.GetTemplateNameAndVersion(opts.Name)
+	t := h.track("restore-backup", name)
+	defer func() { t.Done(err) }()
+
+	t.Phase("prepare", "Checking the dev space...")
+	template, err := helpers.GetLaunchTemplateByName(ctx, client, name)
+	if err != nil {
+		return RestoreBackupOutput{}, err
+	}
+
+	if util.GetTag(template.Tags, snapshotIDTag) != "" {
+		return RestoreBackupOutput{}, fmt.Errorf("dev space %s is archived, unarchive it first", name)
+	}
+
+	// only backups of this dev space can be restored
+	backups, err := h.ListBackups(ctx, ListBackupsOptions{Name: name})
+	if err != nil {
+		return RestoreBackupOutput{}, err
+	}
+	var backup *BackupItem
+	for i := range backups {
+		if backups[i].SnapshotID == opts.SnapshotID {
+			backup = &backups[i]
+		}
+	}
+	if backup == nil {
+		return RestoreBackupOutput{}, fmt.Errorf("backup %s not found for dev space %s", opts.SnapshotID, name)
+	}
+	if backup.State != string(types.SnapshotStateCompleted) {
+		return RestoreBackupOutput{}, fmt.Errorf("backup %s is %s", opts.SnapshotID, backup.State)
+	}
+
+	oldVolumeID := util.GetTag(template.Tags, "dev-spaces:volume-id")
+	if oldVolumeID != "" {
+		isAttached, err := helpers.IsEBSAttached(ctx, client, oldVolumeID)
+		if err != nil {
+			return RestoreBackupOutput{}, err
+		}
+		if isAttached {
+			return RestoreBackupOutput{}, errors.New("make sure the dev-space is not running")
+		}
+	}
+
+	zone := util.GetTag(template.Tags, "dev-spaces:zone")
+	t.Phase("restore-volume", fmt.Sprintf("Creating volume from backup %s in %s...", opts.SnapshotID, zone))
+	volume, err := helpers.CreateEBSVolumeFromSnapshot(ctx, client, name, opts.SnapshotID, zone)
+	if err != nil {
+		return RestoreBackupOutput{}, err
+	}
+	t.Created(ResourceVolume, *volume.VolumeId, fmt.Sprintf("Created volume %s", *volume.VolumeId))
+
+	// the new volume is deleted unless the launch template switched to it
+	var restored bool
+	defer func() {
+		if err != nil && !restored {
+			h.deleteRestoredVolume(context.WithoutCancel(ctx), t, *volume.VolumeId)
+		}
+	}()
+
+	t.Waiting(ResourceVolume, *volume.VolumeId, fmt.Sprintf("Waiting for volume %s to be available...", *volume.VolumeId))
+
+	err = helpers.WaitForEBSVolume(ctx, client, *volume.VolumeId, types.VolumeStateAvailable)
+	if err != nil {
+		return RestoreBackupOutput{}, err
+	}
+
+	err = helpers.SetLaunchTemplateTags(ctx, client, *template.LaunchTemplateId, map[string]string{
+		"dev-spaces:volume-id": *volume.VolumeId,
+	})
+	if err != nil {
+		return RestoreBackupOutput{}, err
+	}
+	restored = true
+
+	if opts.DeleteOldVolume && oldVolumeID != "" {
+		t.Phase("delete-volume", fmt.Sprintf("Deleting volume %s...", oldVolumeID))
+		err = helpers.DeleteEBSVolume(ctx, client, oldVolumeID)
+		if err != nil {
+			return RestoreBackupOutput{}, err
+		}
+	}
+
+	return RestoreBackupOutput{
+		VolumeID:    *volume.VolumeId,
+		OldVolumeID: oldVolumeID,
+	}, nil
+}
+
+type PruneBackupsOptions struct {
+	// Name of the dev space
+	Name string `validate:"required"`
+	// Keep is the number of most recent backups to keep
+	Keep int `validate:"min=0"`
+}
+
+// PruneBackups deletes all but the most recent Keep backups of a dev space,
+// and returns the deleted backups
+func (h *Handler) PruneBackups(ctx context.Context, opts PruneBackupsOptions) (_ []BackupItem, err error) {
+	err = util.Validator.Struct(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	name, _ := util.GetTemplateNameAndVersion(opts.Name)
+	t := h.track("prune-backups", name)
+	defer func() { t.Done(err) }()
+
+	return h.pruneBackups(ctx, t, name, opts.Keep)
+}
+
+// pruneBackups deletes all but the most recent keep backups of a dev space,
+// reporting its progress on the tracker of the running operation
+func (h *Handler) pruneBackups(ctx context.Context, t *tracker, name string, keep int) ([]BackupItem, error) {
+	backups, err := h.ListBackups(ctx, ListBackupsOptions{Name: name})
+	if err != nil {
+		return nil, err
+	}
+	if len(backups) <= keep {
+		return nil, nil
+	}
+
+	pruned := backups[keep:]
+	t.Phase("prune-backups", fmt.Sprintf("Deleting %d old backups of %s...", len(pruned), name))
+	for _, backup := range pruned {
+		err = helpers.DeleteSnapshot(ctx, h.EC2Client, backup.SnapshotID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return pruned, nil
+}
+
+type SetBackupPolicyOptions struct {
+	// Name of the dev space
+	Name string `validate:"required"`
+	// Keep is the number of backups kept when backing up on stop (0 disables)
+	Keep int `validate:"min=0"`
+}
+
+// SetBackupPolicy makes stop back up the dev space and keep its last Keep
+// backups. A Keep of 0 removes the policy
+func (h *Handler) SetBackupPolicy(ctx context.Context, opts SetBackupPolicyOptions) error {
+	err := util.Validator.Struct(opts)
+	if err != nil {
+		return err
+	}
+
+	client := h.EC2Client
+	name, _ := util.GetTemplateNameAndVersion(opts.Name)
+	template, err := helpers.GetLaunchTemplateByName(ctx, client, name)
+	if err != nil {
+		return err
+	}
+
+	if opts.Keep == 0 {
+		return helpers.RemoveLaunchTemplateTags(ctx, client, *template.LaunchTemplateId, backupKeepTag)
+	}
+
+	return helpers.SetLaunchTemplateTags(ctx, client, *template.LaunchTemplateId, map[string]string{
+		backupKeepTag: strconv.Itoa(opts.Keep),
+	})
+}
+
+// getBackupPolicy returns the number of backups to keep on stop, 0 if the
+// dev space has no backup policy
+func getBackupPolicy(template *types.LaunchTemplate) int {
+	keep, err := strconv.Atoi(util.GetTag(template.Tags, backupKeepTag))
+	if err != nil {
+		return 0
+	}
+
+	return keep
+}
+
+// backupOnStop backs up a stopped dev space, once its volume is detached,
+// and prunes its old backups according to its backup policy
+func (h *Handler) backupOnStop(ctx context.Context, t *tracker, template *types.LaunchTemplate) error {
+	keep := getBackupPolicy(template)
+	if keep == 0 {
+		return nil
+	}
+
+	volumeID := util.GetTag(template.Tags, "dev-spaces:volume-id")
+	if volumeID == "" {
+		return nil
+	}
+
+	t.Waiting(ResourceVolume, volumeID, fmt.Sprintf("Waiting for volume %s to be detached...", volumeID))
+	err := helpers.WaitUntilEBSUnattached(ctx, h.EC2Client, volumeID)
+	if err != nil {
+		return err
+	}
+
+	_, err = h.createBackup(ctx, t, template, "backup on stop", false)
+	if err != nil {
+		return err
+	}
+
+	_, err = h.pruneBackups(ctx, t, *template.LaunchTemplateName, keep)
+
+	return err
+}
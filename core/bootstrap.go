@@ -1,100 +1,298 @@
 package core
 
 import (
+	"context"
 	"fmt"
+	"strconv"
+	"strings"
+	texttemplate "text/template"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/felipemarinho97/dev-spaces/core/helpers"
+	"github.com/felipemarinho97/dev-spaces/core/templates"
 	"github.com/felipemarinho97/dev-spaces/core/util"
-	awsUtil "github.com/felipemarinho97/invest-path/util"
-	"github.com/urfave/cli/v2"
+	"gopkg.in/yaml.v2"
 )
 
+const (
+	// BootstrapScriptTimeout bounds the wait for the bootstrap_script to finish
+	BootstrapScriptTimeout = 2 * time.Hour
+
+	// bootstrapStatusPrefix prefixes the line with the exit status of the
+	// bootstrap_script, written to the console and to the user-data log
+	bootstrapStatusPrefix = "dev-spaces: bootstrap_script exited with status "
+
+	// bootstrapErrorLines is the number of lines of the log shown when the
+	// bootstrap_script fails
+	bootstrapErrorLines = 20
+
+	// bootstrapConsoleDelay is how long the console output of a terminated
+	// runner is waited for the exit status of the bootstrap_script
+	bootstrapConsoleDelay = 5 * time.Minute
+
+	// BuiltinTemplatePrefix selects a built-in template instead of a path or
+	// URL, e.g. builtin:ubuntu
+	BuiltinTemplatePrefix = "builtin:"
+
+	// DefaultArch is the architecture of the dev spaces when neither the
+	// options nor the template pick one
+	DefaultArch = "x86_64"
+)
+
+// Architectures are the architectures the dev spaces can be bootstrapped on
+var Architectures = []string{"x86_64", "arm64"}
+
 type BootstrapTemplate struct {
 	TemplateName          string              `yaml:"template_name"`
+	Description           string              `yaml:"description"`
+	Arch                  string              `yaml:"arch" validate:"omitempty,oneof=x86_64 arm64"`
 	HostAMI               AMIFilter           `yaml:"host_ami" validate:"required"`
 	BootstrapAMI          AMIFilter           `yaml:"bootstrap_ami" validate:"required"`
 	AvailabilityZone      string              `yaml:"availability_zone"`
 	PreferedInstanceSpecs PreferedLaunchSpecs `yaml:"prefered_instance_specs"`
 	BootstrapScript       string              `yaml:"bootstrap_script" validate:"required"`
-	StartupScript         string              `yaml:"startup_script" validate:"required"`
+	StartupScript         string              `yaml:"startup_script"`
 	InstanceProfileArn    string              `yaml:"instance_profile_arn"`
-	KeyName               string              `yaml:"key_name" validate:"required"`
+	KeyName               string              `yaml:"key_name"`
 	SecurityGroupIds      []string            `yaml:"security_group_ids"`
 	StorageSize           int32               `yaml:"storage_size"`
 }
 
-func (h *Handler) Bootstrap(c *cli.Context) error {
-	ctx := c.Context
-	log := h.Logger
-	name := c.String("name")
-	templatePath := c.String("template")
-	region := c.String("region")
+// templateVariables is the first YAML document of a bootstrap template,
+// declaring its variables and their defaults
+type templateVariables struct {
+	Variables map[string]interface{} `yaml:"variables"`
+}
 
-	config, err := awsUtil.LoadAWSConfig()
-	config.Region = region
+// LoadBootstrapTemplate renders (see RenderBootstrapTemplate) and validates a
+// bootstrap template from a path, an URL or the built-in templates
+func LoadBootstrapTemplate(path string, vars map[string]string) (BootstrapTemplate, error) {
+	rendered, err := RenderBootstrapTemplate(path, vars)
 	if err != nil {
-		return err
+		return BootstrapTemplate{}, err
 	}
 
-	client := ec2.NewFromConfig(config)
-
 	var template BootstrapTemplate
-	err = util.LoadYAML(templatePath, &template)
+	err = yaml.Unmarshal([]byte(rendered), &template)
 	if err != nil {
-		return fmt.Errorf("error loading template: %v", err)
+		return BootstrapTemplate{}, fmt.Errorf("error loading template: %v", err)
 	}
 	err = util.Validator.Struct(template)
 	if err != nil {
-		return fmt.Errorf("error validating template: %v", err)
+		return BootstrapTemplate{}, fmt.Errorf("error validating template: %v", err)
 	}
 
+	return template, nil
+}
+
+// RenderBootstrapTemplate reads a bootstrap template from a path, an URL or
+// the built-in templates (builtin:<name>) and renders it with text/template.
+// The template can declare its variables and their defaults in a first YAML
+// document, separated from it by "---"; vars override the defaults. A template
+// that declares no variables is only rendered when vars are given, so it can
+// keep literal "{{" in its scripts
+func RenderBootstrapTemplate(path string, vars map[string]string) (string, error) {
+	var data []byte
+	var err error
+	if name, ok := strings.CutPrefix(path, BuiltinTemplatePrefix); ok {
+		data, err = templates.Read(name)
+	} else {
+		var file string
+		file, err = util.RetrieveFile(path)
+		data = []byte(file)
+	}
+	if err != nil {
+		return "", fmt.Errorf("error loading template: %v", err)
+	}
+
+	defaults, body := splitTemplateVariables(string(data))
+	if defaults == nil && len(vars) == 0 {
+		return body, nil
+	}
+
+	// the defaults keep their YAML type, so a false default is false in an
+	// {{ if }}, and the values given for them are converted to that type
+	values := map[string]interface{}{}
+	for key, value := range defaults {
+		if value == nil {
+			value = ""
+		}
+		values[key] = value
+	}
+	for key, value := range vars {
+		typed, err := typedTemplateValue(defaults[key], value)
+		if err != nil {
+			return "", fmt.Errorf("invalid value for variable %s: %v", key, err)
+		}
+		values[key] = typed
+	}
+
+	tmpl, err := texttemplate.New(path).Option("missingkey=error").Parse(body)
+	if err != nil {
+		return "", fmt.Errorf("error parsing template: %v", err)
+	}
+
+	var rendered strings.Builder
+	err = tmpl.Execute(&rendered, values)
+	if err != nil {
+		return "", fmt.Errorf("error rendering template: %v", err)
+	}
+
+	return rendered.String(), nil
+}
+
+// typedTemplateValue converts the value given for a variable to the type of
+// its default
+func typedTemplateValue(def interface{}, value string) (interface{}, error) {
+	switch def.(type) {
+	case bool:
+		return strconv.ParseBool(value)
+	case int:
+		return strconv.Atoi(value)
+	case float64:
+		return strconv.ParseFloat(value, 64)
+	default:
+		return value, nil
+	}
+}
+
+// splitTemplateVariables returns the defaults of the variables declared by a
+// bootstrap template and the template without them
+func splitTemplateVariables(data string) (map[string]interface{}, string) {
+	head, body, found := strings.Cut(data, "\n---\n")
+	if !found {
+		return nil, data
+	}
+
+	var doc templateVariables
+	err := yaml.Unmarshal([]byte(head), &doc)
+	if err != nil || doc.Variables == nil {
+		return nil, data
+	}
+
+	return doc.Variables, body
+}
+
+type BootstrapOptions struct {
+	// Name of the dev space (defaults to the template_name of the template)
+	Name string
+	// TemplatePath is the path or URL of the bootstrap template
+	TemplatePath string `validate:"required_without=Template"`
+	// Template is the bootstrap template, used instead of TemplatePath
+	Template *BootstrapTemplate
+	// Arch is the architecture of the dev space (defaults to the one of the template)
+	Arch string `validate:"omitempty,oneof=x86_64 arm64"`
+	// KeyName overrides the key_name of the template
+	KeyName string
+	// Variables render the template at TemplatePath, overriding its defaults
+	Variables map[string]string
+}
+
+type BootstrapOutput struct {
+	// LaunchTemplateID of the new dev space
+	LaunchTemplateID string `json:"launch_template_id" yaml:"launch_template_id"`
+	// VolumeID of the bootstrapped volume
+	VolumeID string `json:"volume_id" yaml:"volume_id"`
+	// Zone of the volume
+	Zone string `json:"zone" yaml:"zone"`
+}
+
+func (h *Handler) Bootstrap(ctx context.Context, opts BootstrapOptions) (_ BootstrapOutput, err error) {
+	log := h.Logger
+	client := h.EC2Client
+
+	err = util.Validator.Struct(opts)
+	if err != nil {
+		return BootstrapOutput{}, err
+	}
+
+	var template BootstrapTemplate
+	if opts.Template != nil {
+		template = *opts.Template
+		err = util.Validator.Struct(template)
+		if err != nil {
+			return BootstrapOutput{}, fmt.Errorf("error validating template: %v", err)
+		}
+	} else {
+		template, err = LoadBootstrapTemplate(opts.TemplatePath, opts.Variables)
+		if err != nil {
+			return BootstrapOutput{}, err
+		}
+	}
+
+	name := opts.Name
 	az := template.AvailabilityZone
 	if name == "" && template.TemplateName != "" {
 		name = template.TemplateName
 	} else if name == "" {
-		return fmt.Errorf("flag name or template_name must be provided")
+		return BootstrapOutput{}, fmt.Errorf("name or template_name must be provided")
+	}
+	if opts.KeyName != "" {
+		template.KeyName = opts.KeyName
+	}
+	if template.KeyName == "" {
+		return BootstrapOutput{}, fmt.Errorf("key name or key_name must be provided")
 	}
+	if template.StartupScript == "" {
+		template.StartupScript = DEFAULT_STARTUP_SCRIPT
+	}
+
+	arch := template.architecture(opts.Arch)
+	hostFilter, err := template.HostAMI.forArch(arch)
+	if err != nil {
+		return BootstrapOutput{}, fmt.Errorf("host_ami: %v", err)
+	}
+	bootstrapFilter, err := template.BootstrapAMI.forArch(arch)
+	if err != nil {
+		return BootstrapOutput{}, fmt.Errorf("bootstrap_ami: %v", err)
+	}
+	t := h.track("bootstrap", name)
+	defer func() { t.Done(err) }()
+
+	t.Phase("prepare", "checking the images")
 
 	// check if a launch template with the same name already exists
 	templateExists, err := helpers.TemplateExists(ctx, client, name)
 	if err != nil {
-		return err
+		return BootstrapOutput{}, err
 	}
 	if templateExists {
-		return fmt.Errorf("launch template with name %s already exists", name)
+		return BootstrapOutput{}, fmt.Errorf("launch template with name %s already exists", name)
 	}
 
 	// get host and dev space ami
-	hostAMI, err := helpers.GetImageFromFilter(ctx, client, helpers.AMIFilter{
-		Name:  template.HostAMI.Name,
-		ID:    template.HostAMI.ID,
-		Arch:  template.HostAMI.Arch,
-		Owner: template.HostAMI.Owner,
-	})
+	hostAMI, err := helpers.GetImageFromFilter(ctx, client, hostFilter)
 	if err != nil {
-		return err
+		return BootstrapOutput{}, err
 	}
 
-	bootstrapAMI, err := helpers.GetImageFromFilter(ctx, client, helpers.AMIFilter{
-		Name:  template.BootstrapAMI.Name,
-		ID:    template.BootstrapAMI.ID,
-		Arch:  template.BootstrapAMI.Arch,
-		Owner: template.BootstrapAMI.Owner,
-	})
+	bootstrapAMI, err := helpers.GetImageFromFilter(ctx, client, bootstrapFilter)
 	if err != nil {
-		return err
+		return BootstrapOutput{}, err
 	}
 
 	// check if architecture is compatible with the host ami
 	if hostAMI.Architecture != bootstrapAMI.Architecture {
-		return fmt.Errorf("host ami architecture %s is not compatible with bootstrap ami architecture %s", hostAMI.Architecture, bootstrapAMI.Architecture)
+		return BootstrapOutput{}, fmt.Errorf("host ami architecture %s is not compatible with bootstrap ami architecture %s", hostAMI.Architecture, bootstrapAMI.Architecture)
 	}
 
-	log.Info(fmt.Sprintf("creating instance for running bootstrap task: %s", name))
-	taskRunner, err := helpers.CreateSpotTaskRunner(ctx, client, helpers.CreateSpotTaskInput{
+	// clean up the runner and the volume when the bootstrap fails, even when
+	// it was cancelled
+	var taskRunner *ec2.CreateFleetOutput
+	var volume *ec2.CreateVolumeOutput
+	runnerDeleted := false
+	defer func() {
+		if err == nil {
+			return
+		}
+		h.cleanupBootstrap(context.WithoutCancel(ctx), name, taskRunner, volume, !runnerDeleted)
+	}()
+
+	t.Phase("task-runner", fmt.Sprintf("creating instance for running bootstrap task: %s", name))
+	taskRunner, err = helpers.CreateSpotTaskRunner(ctx, client, helpers.CreateSpotTaskInput{
 		Name:        &name,
 		DeviceName:  bootstrapAMI.RootDeviceName,
 		StorageSize: bootstrapAMI.BlockDeviceMappings[0].Ebs.VolumeSize,
@@ -106,62 +304,66 @@ func (h *Handler) Bootstrap(c *cli.Context) error {
 			MinCPU:       template.PreferedInstanceSpecs.MinCPU,
 		},
 		InstanceProfileArn:        &template.InstanceProfileArn,
-		StartupScript:             aws.String(template.BootstrapScript + "\npoweroff"),
+		StartupScript:             aws.String(bootstrapRunnerScript(template.BootstrapScript)),
 		Zone:                      &az,
 		DeleteVolumeOnTermination: true,
 	})
 	if err != nil {
-		return err
+		return BootstrapOutput{}, err
 	}
-	log.Info(fmt.Sprintf("spot task created: %s - waiting instance to be assigned", *taskRunner.FleetId))
+	t.Created(ResourceFleetRequest, *taskRunner.FleetId, fmt.Sprintf("spot task created: %s", *taskRunner.FleetId))
+	t.Waiting(ResourceFleetRequest, *taskRunner.FleetId, "waiting instance to be assigned")
 	id, err := helpers.WaitForFleetInstance(ctx, client, *taskRunner.FleetId, types.InstanceStateNameRunning)
-	log.Info(fmt.Sprintf("instance created: %s", id))
 	if err != nil {
-		return err
+		return BootstrapOutput{}, err
 	}
+	t.Created(ResourceInstance, id, fmt.Sprintf("instance created: %s", id))
 
 	// get instance zone
 	instance, err := helpers.GetInstanceData(ctx, client, id)
 	if err != nil {
-		return err
+		return BootstrapOutput{}, err
 	}
 	az = *instance.Placement.AvailabilityZone
 	log.Info(fmt.Sprintf("instance created on zone: %s", az))
 
-	log.Info(fmt.Sprintf("creating ebs volume for %s", name))
-	volume, err := helpers.CreateEBSVolume(ctx, client, name, template.StorageSize, az)
+	t.Phase("volume", fmt.Sprintf("creating ebs volume for %s", name))
+	volume, err = helpers.CreateEBSVolume(ctx, client, name, template.StorageSize, az)
 	if err != nil {
-		return err
+		return BootstrapOutput{}, err
 	}
-	log.Info(fmt.Sprintf("volume created: %s", *volume.VolumeId))
+	t.Created(ResourceVolume, *volume.VolumeId, fmt.Sprintf("volume created: %s", *volume.VolumeId))
 
 	// wait for volume to be available
-	log.Info(fmt.Sprintf("waiting for volume %s to be available", *volume.VolumeId))
+	t.Waiting(ResourceVolume, *volume.VolumeId, fmt.Sprintf("waiting for volume %s to be available", *volume.VolumeId))
 	err = helpers.WaitForEBSVolume(ctx, client, *volume.VolumeId, types.VolumeStateAvailable)
 	if err != nil {
-		return err
+		return BootstrapOutput{}, err
 	}
 
 	// attach volume to instance
 	log.Info(fmt.Sprintf("attaching volume %s to instance %s", *volume.VolumeId, id))
 	err = helpers.AttachEBSVolume(ctx, client, id, *volume.VolumeId)
 	if err != nil {
-		return err
+		return BootstrapOutput{}, err
 	}
 
-	log.Info(fmt.Sprintf("waiting for bootstrap_script on instance=%s to finish - this may take a few minutes", id))
-	id, err = helpers.WaitForFleetInstance(ctx, client, *taskRunner.FleetId, types.InstanceStateNameTerminated)
+	t.Phase("bootstrap-script", "running bootstrap_script")
+	t.Waiting(ResourceInstance, id, fmt.Sprintf("waiting for bootstrap_script on instance=%s to finish - this may take a few minutes", id))
+	err = h.waitBootstrapScript(ctx, *taskRunner.FleetId, id)
 	if err != nil {
-		return err
+		return BootstrapOutput{}, err
 	}
 	log.Info(fmt.Sprintf("Task terminated: %s", id))
 
 	// delete the runner template
 	err = helpers.DeleteLaunchTemplate(ctx, client, name+"-runner")
 	if err != nil {
-		return err
+		return BootstrapOutput{}, err
 	}
+	runnerDeleted = true
 
+	t.Phase("launch-template", "creating launch template")
 	hostStorageSize := *hostAMI.BlockDeviceMappings[0].Ebs.VolumeSize
 
 	o, err := helpers.CreateLaunchTemplate(ctx, client, log, helpers.CreateLaunchTemplateInput{
@@ -184,9 +386,193 @@ func (h *Handler) Bootstrap(c *cli.Context) error {
 		},
 	})
 	if err != nil {
-		return err
+		return BootstrapOutput{}, err
+	}
+	t.Created(ResourceLaunchTemplate, *o.LaunchTemplateId, fmt.Sprintf("launch template created: %s", *o.LaunchTemplateId))
+
+	return BootstrapOutput{
+		LaunchTemplateID: *o.LaunchTemplateId,
+		VolumeID:         *volume.VolumeId,
+		Zone:             az,
+	}, nil
+}
+
+// cleanupBootstrap removes the resources created by a failed bootstrap: the
+// runner fleet (terminating its instance), the runner launch template and the
+// dev space volume
+func (h *Handler) cleanupBootstrap(ctx context.Context, name string, taskRunner *ec2.CreateFleetOutput, volume *ec2.CreateVolumeOutput, deleteRunner bool) {
+	client := h.EC2Client
+	log := h.Logger
+
+	if taskRunner != nil && taskRunner.FleetId != nil {
+		err := helpers.CancelFleetRequests(ctx, client, []string{*taskRunner.FleetId})
+		if err != nil {
+			log.Warn(fmt.Sprintf("Error cancelling runner fleet %s: %s", *taskRunner.FleetId, err))
+		}
+	}
+
+	if deleteRunner {
+		exists, err := helpers.TemplateExists(ctx, client, name+"-runner")
+		if err == nil && exists {
+			err = helpers.DeleteLaunchTemplate(ctx, client, name+"-runner")
+		}
+		if err != nil {
+			log.Warn(fmt.Sprintf("Error deleting runner launch template %s-runner: %s", name, err))
+		}
+	}
+
+	if volume != nil && volume.VolumeId != nil {
+		// the volume is detached when the runner is terminated
+		err := helpers.WaitUntilEBSUnattached(ctx, client, *volume.VolumeId)
+		if err == nil {
+			err = helpers.DeleteEBSVolume(ctx, client, *volume.VolumeId)
+		}
+		if err != nil {
+			log.Warn(fmt.Sprintf("Error deleting volume %s: %s", *volume.VolumeId, err))
+		}
+	}
+}
+
+// architecture returns the architecture to bootstrap the template on: the
+// requested one, or the one the template or its images are pinned to
+func (t BootstrapTemplate) architecture(requested string) string {
+	for _, arch := range []string{requested, t.Arch, t.BootstrapAMI.Arch, t.HostAMI.Arch} {
+		if arch != "" {
+			return arch
+		}
+	}
+
+	return DefaultArch
+}
+
+// Architectures returns the architectures the template can be bootstrapped on
+func (t BootstrapTemplate) Architectures() []string {
+	var archs []string
+	for _, arch := range Architectures {
+		if t.Arch != "" && t.Arch != arch {
+			continue
+		}
+		if t.HostAMI.supports(arch) && t.BootstrapAMI.supports(arch) {
+			archs = append(archs, arch)
+		}
+	}
+
+	return archs
+}
+
+// forArch returns the filter of the image for an architecture, picking its
+// name from names when they are given per architecture
+func (f AMIFilter) forArch(arch string) (helpers.AMIFilter, error) {
+	if !f.supports(arch) {
+		return helpers.AMIFilter{}, fmt.Errorf("no image for the %s architecture", arch)
+	}
+
+	// an image picked by ID is only checked against the other image
+	if f.ID != "" {
+		return helpers.AMIFilter{ID: f.ID, Arch: f.Arch, Owner: f.Owner}, nil
+	}
+
+	name := f.Name
+	if len(f.Names) > 0 {
+		name = f.Names[arch]
+	}
+
+	return helpers.AMIFilter{
+		Name:  name,
+		Arch:  arch,
+		Owner: f.Owner,
+	}, nil
+}
+
+// supports reports whether the filter can find an image for an architecture
+func (f AMIFilter) supports(arch string) bool {
+	if f.Arch != "" && f.Arch != arch {
+		return false
+	}
+	if f.ID == "" && f.Name == "" && f.Names[arch] == "" {
+		return false
+	}
+
+	return true
+}
+
+// bootstrapRunnerScript wraps the bootstrap_script so its exit status is
+// reported on the console. The runner only powers off when the script
+// succeeds, a failure is caught by waitBootstrapScript
+func bootstrapRunnerScript(script string) string {
+	return fmt.Sprintf(`#!/bin/sh
+cat > /var/tmp/dev-spaces-bootstrap <<'DEV_SPACES_BOOTSTRAP_SCRIPT'
+%s
+DEV_SPACES_BOOTSTRAP_SCRIPT
+chmod +x /var/tmp/dev-spaces-bootstrap
+/var/tmp/dev-spaces-bootstrap
+status=$?
+echo "%s$status" | tee -a %s > /dev/console
+if [ $status -eq 0 ]; then
+	poweroff
+fi
+`, strings.TrimRight(script, "\n"), bootstrapStatusPrefix, UserDataLog)
+}
+
+// waitBootstrapScript waits for the runner to power off after the
+// bootstrap_script succeeds, failing as soon as the script exits non-zero. A
+// runner gone without reporting the success of the script (e.g. a spot
+// interruption) is a failure
+func (h *Handler) waitBootstrapScript(ctx context.Context, fleetID, instanceID string) error {
+	client := h.EC2Client
+
+	var goneAt time.Time
+	waiter := util.NewWaiter(fmt.Sprintf("bootstrap_script on instance %s to finish", instanceID), BootstrapScriptTimeout)
+	waiter.MaxInterval = 30 * time.Second
+	return waiter.Wait(ctx, func(ctx context.Context) (bool, error) {
+		instances, err := client.DescribeFleetInstances(ctx, &ec2.DescribeFleetInstancesInput{
+			FleetId: &fleetID,
+		})
+		if err != nil {
+			return false, util.Retryable(err)
+		}
+		gone := len(instances.ActiveInstances) == 0
+		if gone && goneAt.IsZero() {
+			goneAt = time.Now()
+		}
+
+		output, err := helpers.GetConsoleOutput(ctx, client, instanceID)
+		if err != nil {
+			return false, util.Retryable(err)
+		}
+		lines := userDataLines(output)
+		status, i := bootstrapScriptStatus(lines)
+		switch {
+		case i >= 0 && status == 0:
+			return gone, nil
+		case i >= 0:
+			tail := lines[:i]
+			if len(tail) > bootstrapErrorLines {
+				tail = tail[len(tail)-bootstrapErrorLines:]
+			}
+			return false, fmt.Errorf("bootstrap_script exited with status %d:\n%s", status, strings.Join(tail, "\n"))
+		case gone && time.Since(goneAt) > bootstrapConsoleDelay:
+			return false, fmt.Errorf("instance %s was terminated before bootstrap_script finished", instanceID)
+		}
+
+		return false, nil
+	})
+}
+
+// bootstrapScriptStatus returns the exit status of the bootstrap_script and
+// the index of its line in the log, or -1 when the script has not finished
+func bootstrapScriptStatus(lines []string) (int, int) {
+	for i := len(lines) - 1; i >= 0; i-- {
+		if !strings.HasPrefix(lines[i], bootstrapStatusPrefix) {
+			continue
+		}
+		status, err := strconv.Atoi(strings.TrimPrefix(lines[i], bootstrapStatusPrefix))
+		if err != nil {
+			return 0, -1
+		}
+
+		return status, i
 	}
-	log.Info(fmt.Sprintf("launch template created: %s", *o.LaunchTemplateId))
 
-	return nil
+	return 0, -1
 }
@@ -0,0 +1,124 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRenderBootstrapTemplate(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		vars     map[string]string
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:     "renders the defaults of the variables",
+			template: "variables:\n  storage_size: 8\n  key_name:\n---\nstorage_size: {{ .storage_size }}\nkey_name: \"{{ .key_name }}\"\n",
+			want:     "storage_size: 8\nkey_name: \"\"\n",
+		},
+		{
+			name:     "overrides the defaults",
+			template: "variables:\n  storage_size: 8\n---\nstorage_size: {{ .storage_size }}\n",
+			vars:     map[string]string{"storage_size": "20"},
+			want:     "storage_size: 20\n",
+		},
+		{
+			name:     "renders a template without variables",
+			template: "key_name: {{ .key_name }}\n",
+			vars:     map[string]string{"key_name": "MyKey"},
+			want:     "key_name: MyKey\n",
+		},
+		{
+			name:     "keeps the documents that do not declare variables",
+			template: "template_name: a\n---\ntemplate_name: b\n",
+			want:     "template_name: a\n---\ntemplate_name: b\n",
+		},
+		{
+			name:     "keeps a template without variables verbatim",
+			template: "bootstrap_script: docker ps --format '{{.ID}}'\n",
+			want:     "bootstrap_script: docker ps --format '{{.ID}}'\n",
+		},
+		{
+			name:     "keeps the type of the defaults",
+			template: "variables:\n  install_docker: false\n---\n{{ if .install_docker }}docker{{ else }}none{{ end }}\n",
+			want:     "none\n",
+		},
+		{
+			name:     "converts the values to the type of the defaults",
+			template: "variables:\n  install_docker: false\n---\n{{ if .install_docker }}docker{{ else }}none{{ end }}\n",
+			vars:     map[string]string{"install_docker": "true"},
+			want:     "docker\n",
+		},
+		{
+			name:     "fails on an invalid value",
+			template: "variables:\n  install_docker: false\n---\n{{ .install_docker }}\n",
+			vars:     map[string]string{"install_docker": "maybe"},
+			wantErr:  true,
+		},
+		{
+			name:     "fails on an unknown variable",
+			template: "variables:\n  storage_size: 8\n---\nstorage_size: {{ .size }}\n",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "template.yaml")
+			err := os.WriteFile(path, []byte(tt.template), 0644)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := RenderBootstrapTemplate(path, tt.vars)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RenderBootstrapTemplate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("RenderBootstrapTemplate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBootstrapScriptStatus(t *testing.T) {
+	tests := []struct {
+		name       string
+		lines      []string
+		wantStatus int
+		wantIndex  int
+	}{
+		{
+			name:       "script succeeded",
+			lines:      []string{"installing", bootstrapStatusPrefix + "0"},
+			wantStatus: 0,
+			wantIndex:  1,
+		},
+		{
+			name:       "script failed",
+			lines:      []string{"installing", bootstrapStatusPrefix + "2", "poweroff skipped"},
+			wantStatus: 2,
+			wantIndex:  1,
+		},
+		{
+			name:      "script still running",
+			lines:     []string{"installing"},
+			wantIndex: -1,
+		},
+		{
+			name:      "truncated status line",
+			lines:     []string{"installing", bootstrapStatusPrefix},
+			wantIndex: -1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, i := bootstrapScriptStatus(tt.lines)
+			if status != tt.wantStatus || i != tt.wantIndex {
+				t.Errorf("bootstrapScriptStatus() = %d, %d, want %d, %d", status, i, tt.wantStatus, tt.wantIndex)
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/felipemarinho97/dev-spaces/core/helpers"
+	"github.com/felipemarinho97/dev-spaces/core/util"
+)
+
+type Budget struct {
+	// Monthly is the limit in USD of the spend of all dev spaces in a calendar month (0 disables)
+	Monthly float64
+	// DevSpace is the default limit in USD of the monthly spend of each dev space (0 disables)
+	DevSpace float64
+	// DevSpaces are the limits in USD of the monthly spend of specific dev spaces,
+	// overriding DevSpace
+	DevSpaces map[string]float64
+	// WarnOnly logs a warning instead of refusing to start when a limit would be exceeded
+	WarnOnly bool
+}
+
+// BudgetExceededError is returned when placing a fleet request could exceed a budget
+type BudgetExceededError struct {
+	// Scope is the budget that would be exceeded ("monthly" or the dev space name)
+	Scope string
+	// Limit of the budget in USD
+	Limit float64
+	// Spent is the spend of the current month in USD
+	Spent float64
+	// Projected is the maximum spend of the fleet request in USD
+	Projected float64
+}
+
+func (e *BudgetExceededError) Error() string {
+	return fmt.Sprintf("%s budget of $%.2f would be exceeded: $%.2f spent this month and up to $%.2f projected",
+		e.Scope, e.Limit, e.Spent, e.Projected)
+}
+
+func (b Budget) enabled(name string) bool {
+	return b.Monthly > 0 || b.limit(name) > 0
+}
+
+func (b Budget) limit(name string) float64 {
+	if limit, ok := b.DevSpaces[name]; ok {
+		return limit
+	}
+	return b.DevSpace
+}
+
+// exceeded returns the budget that the projected spend would exceed, checking
+// the dev space budget before the monthly one
+func (b Budget) exceeded(name string, spent, spentDevSpace, projected float64) *BudgetExceededError {
+	if limit := b.limit(name); limit > 0 && spentDevSpace+projected > limit {
+		return &BudgetExceededError{Scope: name, Limit: limit, Spent: spentDevSpace, Projected: projected}
+	}
+	if b.Monthly > 0 && spent+projected > b.Monthly {
+		return &BudgetExceededError{Scope: "monthly", Limit: b.Monthly, Spent: spent, Projected: projected}
+	}
+
+	return nil
+}
+
+// checkBudget estimates the spend of a fleet request over its duration and
+// returns a BudgetExceededError when, added to the spend of the current month,
+// it would exceed the monthly or the dev space budget. Spot capacity is capped
+// by the max price, on-demand capacity by the on-demand price of the most
+// expensive instance type matching the requirements
+func (h *Handler) checkBudget(ctx context.Context, template *types.LaunchTemplate, mode CapacityMode, minCPUs, minMemory int, maxPrice string, duration time.Duration) error {
+	log := h.Logger
+	budget := h.Config.Budget
+	name := util.GetValue(template.LaunchTemplateName)
+	if !budget.enabled(name) {
+		return nil
+	}
+
+	price, err := strconv.ParseFloat(maxPrice, 64)
+	if err != nil {
+		return fmt.Errorf("invalid max price: %s", maxPrice)
+	}
+	if mode == CapacityModeOnDemand || mode == CapacityModeSpotThenOnDemand {
+		onDemandPrice, err := h.maxOnDemandPrice(ctx, template, minCPUs, minMemory)
+		if err != nil {
+			return fmt.Errorf("error projecting the on-demand spend: %w", err)
+		}
+		// the spot capacity of spot-then-on-demand is still capped by the max price
+		if mode == CapacityModeOnDemand || onDemandPrice > price {
+			price = onDemandPrice
+		}
+	}
+	projected := price * duration.Hours()
+
+	now := time.Now()
+	items, err := h.Cost(ctx, CostOptions{
+		From: time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location()),
+		To:   now,
+	})
+	if err != nil {
+		return fmt.Errorf("error computing the spend of the month: %w", err)
+	}
+
+	var spent, spentDevSpace float64
+	for _, item := range items {
+		spent += item.Total
+		if item.Name == name {
+			spentDevSpace = item.Total
+		}
+	}
+	log.Debug(fmt.Sprintf("Budget: $%.2f spent this month ($%.2f by %s), up to $%.2f projected", spent, spentDevSpace, name, projected))
+
+	exceeded := budget.exceeded(name, spent, spentDevSpace, projected)
+	if exceeded != nil && budget.WarnOnly {
+		log.Warn(exceeded.Error())
+		return nil
+	}
+	if exceeded != nil {
+		return exceeded
+	}
+
+	return nil
+}
+
+// maxOnDemandPrice returns the highest on-demand price of the instance types
+// matching the requirements
+func (h *Handler) maxOnDemandPrice(ctx context.Context, template *types.LaunchTemplate, minCPUs, minMemory int) (float64, error) {
+	instanceTypes, err := h.candidateInstanceTypes(ctx, template, minCPUs, minMemory)
+	if err != nil {
+		return 0, err
+	}
+
+	var max float64
+	for _, instanceType := range instanceTypes {
+		price, err := helpers.GetOnDemandPrice(ctx, h.PricingClient, h.Config.DefaultRegion, instanceType)
+		if err != nil {
+			return 0, fmt.Errorf("error getting the on-demand price of %s: %w", instanceType, err)
+		}
+		if price > max {
+			max = price
+		}
+	}
+
+	return max, nil
+}
@@ -0,0 +1,168 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+func TestBudget_exceeded(t *testing.T) {
+	tests := []struct {
+		name          string
+		budget        Budget
+		spent         float64
+		spentDevSpace float64
+		projected     float64
+		want          *BudgetExceededError
+	}{
+		{
+			name:      "no budget",
+			budget:    Budget{},
+			spent:     1000,
+			projected: 1000,
+			want:      nil,
+		},
+		{
+			name:      "under the monthly budget",
+			budget:    Budget{Monthly: 100},
+			spent:     60,
+			projected: 40,
+			want:      nil,
+		},
+		{
+			name:      "over the monthly budget",
+			budget:    Budget{Monthly: 100},
+			spent:     60,
+			projected: 41,
+			want:      &BudgetExceededError{Scope: "monthly", Limit: 100, Spent: 60, Projected: 41},
+		},
+		{
+			name:          "over the default dev space budget",
+			budget:        Budget{DevSpace: 20},
+			spent:         60,
+			spentDevSpace: 15,
+			projected:     6,
+			want:          &BudgetExceededError{Scope: "MySpace", Limit: 20, Spent: 15, Projected: 6},
+		},
+		{
+			name:          "the dev space budget overrides the default one",
+			budget:        Budget{DevSpace: 20, DevSpaces: map[string]float64{"MySpace": 50}},
+			spent:         60,
+			spentDevSpace: 15,
+			projected:     6,
+			want:          nil,
+		},
+		{
+			name:          "the budget of another dev space does not apply",
+			budget:        Budget{DevSpace: 20, DevSpaces: map[string]float64{"OtherSpace": 50}},
+			spentDevSpace: 15,
+			projected:     6,
+			want:          &BudgetExceededError{Scope: "MySpace", Limit: 20, Spent: 15, Projected: 6},
+		},
+		{
+			name:          "the dev space budget is checked before the monthly one",
+			budget:        Budget{Monthly: 100, DevSpace: 20},
+			spent:         99,
+			spentDevSpace: 15,
+			projected:     6,
+			want:          &BudgetExceededError{Scope: "MySpace", Limit: 20, Spent: 15, Projected: 6},
+		},
+		{
+			name:          "under the dev space budget and over the monthly one",
+			budget:        Budget{Monthly: 100, DevSpace: 20},
+			spent:         99,
+			spentDevSpace: 10,
+			projected:     6,
+			want:          &BudgetExceededError{Scope: "monthly", Limit: 100, Spent: 99, Projected: 6},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.budget.exceeded("MySpace", tt.spent, tt.spentDevSpace, tt.projected)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("exceeded() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// warnLogger counts the warnings of the operations
+type warnLogger struct {
+	nopLogger
+	warnings int
+}
+
+func (l *warnLogger) Warn(args ...interface{}) { l.warnings++ }
+
+func TestHandler_checkBudget(t *testing.T) {
+	// on-demand prices of the instance types matching the requirements
+	prices := map[string]float64{"m5.large": 0.5, "m5.xlarge": 1}
+
+	tests := []struct {
+		name         string
+		budget       Budget
+		mode         CapacityMode
+		maxPrice     string
+		wantErr      bool
+		wantExceeded bool
+		wantWarnings int
+	}{
+		{"disabled budget", Budget{}, CapacityModeSpot, "1", false, false, 0},
+		{"within the budget", Budget{Monthly: 10}, CapacityModeSpot, "0.5", false, false, 0},
+		{"exceeds the budget", Budget{Monthly: 10}, CapacityModeSpot, "1", true, true, 0},
+		{"warns only", Budget{Monthly: 10, WarnOnly: true}, CapacityModeSpot, "1", false, false, 1},
+		{"invalid max price", Budget{Monthly: 10}, CapacityModeSpot, "abc", true, false, 0},
+		{"on-demand ignores the max price", Budget{Monthly: 10}, CapacityModeOnDemand, "0.5", true, true, 0},
+		{"spot then on-demand uses the higher price", Budget{Monthly: 10}, CapacityModeSpotThenOnDemand, "0.1", true, true, 0},
+		{"on-demand within the budget", Budget{Monthly: 15}, CapacityModeOnDemand, "0.5", false, false, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := &warnLogger{}
+			client := &fakeEC2Client{instanceTypes: []string{"m5.large", "m5.xlarge"}}
+			h := NewHandler(Config{Budget: tt.budget}, client, &fakePricingClient{prices: prices}, logger)
+			template := devSpaceTemplate("MySpace", nil)
+
+			// up to 1 an hour over 12 hours, with nothing spent this month
+			err := h.checkBudget(context.Background(), &template, tt.mode, 2, 4096, tt.maxPrice, 12*time.Hour)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("checkBudget() = %v, wantErr %v", err, tt.wantErr)
+			}
+			var exceeded *BudgetExceededError
+			if errors.As(err, &exceeded) != tt.wantExceeded {
+				t.Errorf("checkBudget() = %v, want a BudgetExceededError: %v", err, tt.wantExceeded)
+			}
+			if exceeded != nil && exceeded.Projected != 12 {
+				t.Errorf("checkBudget() projected = %v, want 12", exceeded.Projected)
+			}
+			if logger.warnings != tt.wantWarnings {
+				t.Errorf("checkBudget() logged %d warnings, want %d", logger.warnings, tt.wantWarnings)
+			}
+		})
+	}
+}
+
+func TestHandler_Start_budgetBeforeUnarchive(t *testing.T) {
+	// the budget is exceeded before the volume is restored from snap-1
+	client := &fakeEC2Client{
+		templates: []types.LaunchTemplate{devSpaceTemplate("MySpace", map[string]string{snapshotIDTag: "snap-1"})},
+	}
+	h := NewHandler(Config{Budget: Budget{Monthly: 10}}, client, nil, nopLogger{})
+
+	_, err := h.Start(context.Background(), StartOptions{
+		Name:     "MySpace",
+		MaxPrice: "1",
+		Timeout:  12 * time.Hour,
+	})
+
+	var exceeded *BudgetExceededError
+	if !errors.As(err, &exceeded) {
+		t.Fatalf("Start() = %v, want a BudgetExceededError", err)
+	}
+}
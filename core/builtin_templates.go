@@ -0,0 +1,49 @@
+package core
+
+import (
+	"fmt"
+
+	"github.com/felipemarinho97/dev-spaces/core/templates"
+)
+
+type TemplateItem struct {
+	// Name selects the template, as builtin:<name>
+	Name string `json:"name" yaml:"name"`
+	// TemplateName is the default name of the dev spaces bootstrapped with it
+	TemplateName string `json:"template_name" yaml:"template_name"`
+	// Description of the template
+	Description string `json:"description" yaml:"description"`
+	// Architectures the template can be bootstrapped on
+	Architectures []string `json:"architectures" yaml:"architectures"`
+}
+
+// ListTemplates returns the built-in bootstrap templates
+func ListTemplates() ([]TemplateItem, error) {
+	var items []TemplateItem
+	for _, name := range templates.Names() {
+		template, err := LoadBootstrapTemplate(BuiltinTemplatePrefix+name, nil)
+		if err != nil {
+			return nil, fmt.Errorf("template %s: %v", name, err)
+		}
+
+		items = append(items, TemplateItem{
+			Name:          name,
+			TemplateName:  template.TemplateName,
+			Description:   template.Description,
+			Architectures: template.Architectures(),
+		})
+	}
+
+	return items, nil
+}
+
+// ShowTemplate returns the YAML of a built-in bootstrap template, which can be
+// saved and edited to bootstrap a customized dev space
+func ShowTemplate(name string) (string, error) {
+	data, err := templates.Read(name)
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
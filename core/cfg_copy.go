@@ -25,15 +25,15 @@ type CopyOptions struct {
 
 type CopyOutput struct {
 	// LaunchTemplateID of the new launch template
-	LaunchTemplateID string
+	LaunchTemplateID string `json:"launch_template_id" yaml:"launch_template_id"`
 	// VolumeID of the new volume
-	VolumeID string
+	VolumeID string `json:"volume_id" yaml:"volume_id"`
 	// Zone of the new instance
-	Zone string
+	Zone string `json:"zone" yaml:"zone"`
 }
 
-func (h *Handler) Copy(ctx context.Context, opts CopyOptions) (CopyOutput, error) {
-	err := util.Validator.Struct(opts)
+func (h *Handler) Copy(ctx context.Context, opts CopyOptions) (_ CopyOutput, err error) {
+	err = util.Validator.Struct(opts)
 	if err != nil {
 		return CopyOutput{}, err
 	}
@@ -52,6 +52,10 @@ func (h *Handler) Copy(ctx context.Context, opts CopyOptions) (CopyOutput, error
 	newRegionClient := ec2.NewFromConfig(config)
 
 	name, version := util.GetTemplateNameAndVersion(opts.Name)
+	t := h.track("copy", name)
+	defer func() { t.Done(err) }()
+
+	t.Phase("prepare", "Checking the dev space...")
 	template, err := helpers.GetLaunchTemplateByName(ctx, client, name)
 	if err != nil {
 		return CopyOutput{}, err
@@ -72,21 +76,22 @@ func (h *Handler) Copy(ctx context.Context, opts CopyOptions) (CopyOutput, error
 	}
 
 	// create a snapshot of the volume
-	h.Logger.Info("creating a snapshot of the volume")
+	t.Phase("snapshot", "creating a snapshot of the volume")
 	snapshot, err := helpers.CreateSnapshot(ctx, client, volumeID)
 	if err != nil {
 		return CopyOutput{}, err
 	}
+	t.Created(ResourceSnapshot, snapshot, fmt.Sprintf("snapshot created: %s", snapshot))
 
 	// wait for the snapshot to be available
-	h.Logger.Info("waiting for the snapshot to be available")
+	t.Waiting(ResourceSnapshot, snapshot, "waiting for the snapshot to be available")
 	err = helpers.WaitForSnapshot(ctx, client, snapshot)
 	if err != nil {
 		return CopyOutput{}, err
 	}
 
 	// copy the snapshot to the new region
-	h.Logger.Info("copying the snapshot to the new region")
+	t.Phase("copy-snapshot", "copying the snapshot to the new region")
 	copySnapshot, err := newRegionClient.CopySnapshot(ctx, &ec2.CopySnapshotInput{
 		SourceSnapshotId: aws.String(snapshot),
 		SourceRegion:     aws.String(h.Config.DefaultRegion),
@@ -96,15 +101,17 @@ func (h *Handler) Copy(ctx context.Context, opts CopyOptions) (CopyOutput, error
 		return CopyOutput{}, err
 	}
 
+	t.Created(ResourceSnapshot, *copySnapshot.SnapshotId, fmt.Sprintf("snapshot copy created: %s", *copySnapshot.SnapshotId))
+
 	// wait for the snapshot to be available
-	h.Logger.Info("waiting for the snapshot to be available")
+	t.Waiting(ResourceSnapshot, *copySnapshot.SnapshotId, "waiting for the snapshot copy to be available")
 	err = helpers.WaitForSnapshot(ctx, newRegionClient, *copySnapshot.SnapshotId)
 	if err != nil {
 		return CopyOutput{}, err
 	}
 
 	// create a new volume from the copied snapshot
-	h.Logger.Info("creating a new volume from the copied snapshot")
+	t.Phase("volume", "creating a new volume from the copied snapshot")
 	newVolume, err := newRegionClient.CreateVolume(ctx, &ec2.CreateVolumeInput{
 		AvailabilityZone: aws.String(opts.AvailabilityZone),
 		SnapshotId:       copySnapshot.SnapshotId,
@@ -121,6 +128,7 @@ func (h *Handler) Copy(ctx context.Context, opts CopyOptions) (CopyOutput, error
 	if err != nil {
 		return CopyOutput{}, err
 	}
+	t.Created(ResourceVolume, *newVolume.VolumeId, fmt.Sprintf("volume created: %s", *newVolume.VolumeId))
 
 	// get default launch template version
 	h.Logger.Debug("getting default launch template version")
@@ -135,7 +143,7 @@ func (h *Handler) Copy(ctx context.Context, opts CopyOptions) (CopyOutput, error
 	hostArchitecture := currentHostImage.Architecture
 
 	// create a new launch template with the same specifications as the old one
-	h.Logger.Info("creating a new launch template with the same specifications as the old one")
+	t.Phase("launch-template", "creating a new launch template with the same specifications as the old one")
 	instanceProfileArn := ""
 	if defaultVersion.LaunchTemplateData.IamInstanceProfile != nil {
 		instanceProfileArn = util.GetValue(defaultVersion.LaunchTemplateData.IamInstanceProfile.Arn)
@@ -175,9 +183,10 @@ func (h *Handler) Copy(ctx context.Context, opts CopyOptions) (CopyOutput, error
 	if err != nil {
 		return CopyOutput{}, err
 	}
+	t.Created(ResourceLaunchTemplate, *newLaunchTemplate.LaunchTemplateId, fmt.Sprintf("launch template created: %s", *newLaunchTemplate.LaunchTemplateId))
 
 	// delete both snapshots
-	h.Logger.Info("deleting snapshots")
+	t.Phase("cleanup", "deleting snapshots")
 	_, err = client.DeleteSnapshot(ctx, &ec2.DeleteSnapshotInput{
 		SnapshotId: aws.String(snapshot),
 	})
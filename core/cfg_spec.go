@@ -7,7 +7,6 @@ import (
 
 	"github.com/felipemarinho97/dev-spaces/core/helpers"
 	"github.com/felipemarinho97/dev-spaces/core/util"
-	"github.com/felipemarinho97/dev-spaces/core/util/ssh"
 )
 
 type EditSpecOptions struct {
@@ -21,23 +20,39 @@ type EditSpecOptions struct {
 	MaxPrice string `validate:"required"`
 	// SSHKey is the path of the SSH key
 	SSHKey string `validate:"required"`
+	// IgnoreBudget places the fleet request even if it could exceed a budget
+	IgnoreBudget bool
+	// Timeout bounds the waits for the current and the new instance (defaults to helpers.InstanceWaitTimeout)
+	Timeout time.Duration `validate:"min=0"`
 }
 
 type EditOutput struct {
 	// InstanceID is the ID of the instance
-	InstanceID string `json:"instance_id"`
+	InstanceID string `json:"instance_id" yaml:"instance_id"`
 	// InstanceIP is the Public IP of the instance
-	InstanceIP string `json:"instance_ip"`
+	InstanceIP string `json:"instance_ip" yaml:"instance_ip"`
 	// InstanceType is the type of the instance
-	InstanceType string `json:"instance_type"`
+	InstanceType string `json:"instance_type" yaml:"instance_type"`
 	// FleetRequestID is the ID of the FleetRequest
-	FleetRequestID string `json:"fleet_request_id"`
+	FleetRequestID string `json:"fleet_request_id" yaml:"fleet_request_id"`
+	// Hostname is the stable hostname of the dev space when dynamic DNS is enabled
+	Hostname string `json:"hostname" yaml:"hostname"`
 }
 
-func (h *Handler) EditSpec(ctx context.Context, opts EditSpecOptions) (EditOutput, error) {
+// Host returns the stable hostname of the dev space, or its public IP when
+// dynamic DNS is disabled
+func (o EditOutput) Host() string {
+	if o.Hostname != "" {
+		return o.Hostname
+	}
+
+	return o.InstanceIP
+}
+
+func (h *Handler) EditSpec(ctx context.Context, opts EditSpecOptions) (_ EditOutput, err error) {
 	log := h.Logger
 
-	err := util.Validator.Struct(opts)
+	err = util.Validator.Struct(opts)
 	if err != nil {
 		return EditOutput{}, err
 	}
@@ -48,8 +63,16 @@ func (h *Handler) EditSpec(ctx context.Context, opts EditSpecOptions) (EditOutpu
 	}
 
 	client := h.EC2Client
+	waitTimeout := opts.Timeout
+	if waitTimeout == 0 {
+		waitTimeout = helpers.InstanceWaitTimeout
+	}
 
 	name, version := util.GetTemplateNameAndVersion(opts.Name)
+	t := h.track("edit-spec", name)
+	defer func() { t.Done(err) }()
+
+	t.Phase("prepare", "Checking the running instance...")
 	template, err := helpers.GetLaunchTemplateByName(ctx, client, name)
 	if err != nil {
 		return EditOutput{}, err
@@ -61,49 +84,70 @@ func (h *Handler) EditSpec(ctx context.Context, opts EditSpecOptions) (EditOutpu
 	if err != nil {
 		return EditOutput{}, err
 	}
-	currentInstance, err := waitInstance(ctx, client, log, currentReq.FleetId)
+	currentInstance, err := waitInstance(ctx, client, log, currentReq.FleetId, waitTimeout)
 	if err != nil {
 		return EditOutput{}, err
 	}
 
-	// create instance
-	log.Info("Creating new instance...")
 	now := time.Now()
-	t := currentReq.ValidUntil.Sub(now).Round(time.Second)
-	out, err := helpers.CreateSpotRequest(ctx, client, name, version, opts.MinCPUs, opts.MinMemory, opts.MaxPrice, template, t)
+	validFor := currentReq.ValidUntil.Sub(now).Round(time.Second)
+	if !opts.IgnoreBudget {
+		err = h.checkBudget(ctx, template, CapacityModeSpot, opts.MinCPUs, opts.MinMemory, opts.MaxPrice, validFor)
+		if err != nil {
+			return EditOutput{}, err
+		}
+	}
+
+	// create instance
+	t.Phase("fleet-request", "Creating new instance...")
+	out, err := helpers.CreateSpotRequest(ctx, client, name, version, opts.MinCPUs, opts.MinMemory, opts.MaxPrice, template, validFor)
 	if err != nil {
 		return EditOutput{}, err
 	}
+	t.Created(ResourceFleetRequest, *out.FleetId, fmt.Sprintf("Created fleet request with id: %s", *out.FleetId))
 
 	// wait for instance to be running
-	newInstance, err := waitInstance(ctx, client, log, out.FleetId)
+	t.Phase("instance", "Waiting for the new instance to be running...")
+	t.Waiting(ResourceFleetRequest, *out.FleetId, "Waiting for the new instance to be running...")
+	newInstance, err := waitInstance(ctx, client, log, out.FleetId, waitTimeout)
 	if err != nil {
+		// do not leave the new fleet request open when the wait is cancelled or times out
+		cancelErr := helpers.CancelFleetRequests(context.WithoutCancel(ctx), client, []string{*out.FleetId})
+		if cancelErr != nil {
+			t.Warn(fmt.Sprintf("Error cancelling fleet request %s", *out.FleetId), cancelErr)
+		}
 		return EditOutput{}, err
 	}
 
+	t.Created(ResourceInstance, *newInstance.InstanceId, fmt.Sprintf("Instance %s is running", *newInstance.InstanceId))
+
 	// wait until port 22 is reachable
-	err = helpers.WaitUntilReachable(*newInstance.PublicIpAddress, 22)
+	t.Waiting(ResourceInstance, *newInstance.InstanceId, "Waiting for the new instance to be reachable...")
+	err = helpers.WaitUntilReachable(ctx, *newInstance.PublicIpAddress, 22)
 	if err != nil {
+		cancelErr := helpers.CancelFleetRequests(context.WithoutCancel(ctx), client, []string{*out.FleetId})
+		if cancelErr != nil {
+			t.Warn(fmt.Sprintf("Error cancelling fleet request %s", *out.FleetId), cancelErr)
+		}
 		return EditOutput{}, err
 	}
 
 	// power off devspace
+	t.Phase("volume", "Moving the EBS volume to the new instance...")
 	timeout := 60 * time.Second
-	sshClient, err := ssh.NewSSHClient(*currentInstance.PublicIpAddress, 22, "ec2-user", string(identityKey))
+	sshClient, err := connectHost(*currentInstance.PublicIpAddress, string(identityKey))
 	if err != nil {
-		sshClient, err = ssh.NewSSHClient(*currentInstance.PublicIpAddress, 22, "root", string(identityKey))
-		if err != nil {
-			return EditOutput{}, err
-		}
+		return EditOutput{}, err
 	}
+	defer sshClient.Close()
 	_, err = sshClient.Run("sudo machinectl terminate devspace", timeout)
 	if err != nil {
-		log.Warn("Error powering off devspace: ", err)
+		t.Warn("Error powering off devspace", err)
 	}
 	log.Debug("Powered off devspace")
 	_, err = sshClient.Run("sudo umount /dev/sdf1", timeout)
 	if err != nil {
-		log.Warn("Error unmounting EBS volume: ", err)
+		t.Warn("Error unmounting EBS volume", err)
 	} else {
 		log.Debug("Unmounted EBS volume")
 	}
@@ -116,6 +160,7 @@ func (h *Handler) EditSpec(ctx context.Context, opts EditSpecOptions) (EditOutpu
 	log.Debug("Detached EBS volume with id: ", volumeID)
 
 	// wait until ebs volume is detached
+	t.Waiting(ResourceVolume, volumeID, "Waiting for EBS volume to be detached...")
 	err = helpers.WaitUntilEBSUnattached(ctx, client, volumeID)
 	if err != nil {
 		return EditOutput{}, err
@@ -129,6 +174,7 @@ func (h *Handler) EditSpec(ctx context.Context, opts EditSpecOptions) (EditOutpu
 	log.Info(fmt.Sprintf("Attached EBS volume with id=%s on the new instance", volumeID))
 
 	// terminate old instance
+	t.Phase("cleanup", "Terminating old instance...")
 	err = helpers.CancelFleetRequests(ctx, client, []string{*currentReq.FleetId})
 	if err != nil {
 		log.Error(err)
@@ -137,10 +183,17 @@ func (h *Handler) EditSpec(ctx context.Context, opts EditSpecOptions) (EditOutpu
 	log.Info("Terminated old instance with id: ", *currentInstance.InstanceId)
 	log.Info("Scaled successfully!")
 
+	var hostname string
+	if h.Config.DNS.enabled() {
+		t.Phase("dns", "Updating DNS record...")
+		hostname = h.updateDNS(ctx, name, *newInstance.PublicIpAddress)
+	}
+
 	return EditOutput{
 		InstanceID:     *newInstance.InstanceId,
 		InstanceIP:     *newInstance.PublicIpAddress,
 		InstanceType:   fmt.Sprint(newInstance.InstanceType),
 		FleetRequestID: *out.FleetId,
+		Hostname:       hostname,
 	}, nil
 }
@@ -0,0 +1,86 @@
+package core
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/felipemarinho97/dev-spaces/core/helpers"
+	"github.com/felipemarinho97/dev-spaces/core/util"
+	"github.com/felipemarinho97/dev-spaces/core/util/ssh"
+)
+
+type ConnectOptions struct {
+	// Name of the dev space
+	Name string `validate:"required"`
+	// SSHKey is the path of the SSH key
+	SSHKey string `validate:"required"`
+	// Host connects to the host (port 22) instead of the dev space (port 2222)
+	Host bool
+	// User is the dev space user (defaults to root), ignored for the host
+	User string
+}
+
+// Connect opens an SSH connection to the running instance of a dev space
+func (h *Handler) Connect(ctx context.Context, opts ConnectOptions) (*ssh.SSHClient, error) {
+	err := util.Validator.Struct(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	identityKey, err := util.RetrieveFile(opts.SSHKey)
+	if err != nil {
+		return nil, err
+	}
+
+	ip, err := h.getPublicIP(ctx, opts.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	return connect(ip, identityKey, opts.Host, opts.User)
+}
+
+// connect opens an SSH connection to the dev space (port 2222) or its host
+func connect(ip string, identityKey string, host bool, user string) (*ssh.SSHClient, error) {
+	if host {
+		return connectHost(ip, identityKey)
+	}
+
+	if user == "" {
+		user = "root"
+	}
+
+	return ssh.NewSSHClient(ip, 2222, user, identityKey)
+}
+
+// getPublicIP returns the public IP of the running instance of a dev space
+func (h *Handler) getPublicIP(ctx context.Context, name string) (string, error) {
+	name, _ = util.GetTemplateNameAndVersion(name)
+
+	managedInstances, err := helpers.GetManagedInstances(ctx, h.EC2Client)
+	if err != nil {
+		return "", err
+	}
+
+	instance := managedInstances[name]
+	if instance == nil || instance.State.Name != types.InstanceStateNameRunning || instance.PublicIpAddress == nil {
+		return "", fmt.Errorf("dev space %s is not running", name)
+	}
+
+	return *instance.PublicIpAddress, nil
+}
+
+// connectHost opens an SSH connection to the dev space host (port 22),
+// trying the default users of the supported host AMIs
+func connectHost(ip string, identityKey string) (*ssh.SSHClient, error) {
+	sshClient, err := ssh.NewSSHClient(ip, 22, "ec2-user", identityKey)
+	if err != nil {
+		sshClient, err = ssh.NewSSHClient(ip, 22, "root", identityKey)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return sshClient, nil
+}
@@ -4,7 +4,7 @@ const DEFAULT_STARTUP_SCRIPT = `#!/bin/bash -xe
 exec > >(tee /var/log/user-data.log|logger -t user-data -s 2>/dev/console) 2>&1
 
 ## add required packages
-yum install -y systemd-container
+yum install -y systemd-container cloud-utils-growpart
 
 ## start networkd and resolved 
 systemctl start systemd-resolved 
@@ -33,6 +33,14 @@ else
 	mount "$DEVICE" $MOUNTPOINT
 fi
 
+## grow the partition and filesystem in case the volume was resized while stopped
+growpart /dev/$(lsblk -n -d -o PKNAME $DEVICE) $(cat /sys/class/block/$not_mounted/partition) || true
+if [ "$FSTYPE" == "xfs" ]; then
+	xfs_growfs $MOUNTPOINT || true
+else
+	resize2fs "$DEVICE" || true
+fi
+
 ## enable ip forwarding
 echo 1 > /proc/sys/net/ipv4/ip_forward
 
@@ -0,0 +1,241 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/felipemarinho97/dev-spaces/core/helpers"
+	"github.com/felipemarinho97/dev-spaces/core/util"
+)
+
+const (
+	// DefaultVolumePrice is the gp3 storage price in USD per GB-month (us-east-1)
+	DefaultVolumePrice = 0.08
+	// DefaultSnapshotPrice is the standard snapshot storage price in USD per GB-month (us-east-1)
+	DefaultSnapshotPrice = 0.05
+
+	hoursPerMonth = 730
+)
+
+type CostOptions struct {
+	// Name of the dev space, empty reports all dev spaces
+	Name string
+	// From is the start of the period
+	From time.Time `validate:"required"`
+	// To is the end of the period (defaults to now)
+	To time.Time
+	// VolumePrice is the volume storage price in USD per GB-month (defaults to DefaultVolumePrice)
+	VolumePrice float64 `validate:"min=0"`
+	// SnapshotPrice is the snapshot storage price in USD per GB-month (defaults to DefaultSnapshotPrice)
+	SnapshotPrice float64 `validate:"min=0"`
+}
+
+type CostItem struct {
+	// Name of the dev space
+	Name string `json:"name" yaml:"name"`
+	// InstanceHours is the time the dev space instances ran in the period
+	InstanceHours float64 `json:"instance_hours" yaml:"instance_hours"`
+	// InstanceCost is the cost of the instances in USD
+	InstanceCost float64 `json:"instance_cost" yaml:"instance_cost"`
+	// VolumeGBMonths is the volume storage used in the period
+	VolumeGBMonths float64 `json:"volume_gb_months" yaml:"volume_gb_months"`
+	// VolumeCost is the cost of the volume storage in USD
+	VolumeCost float64 `json:"volume_cost" yaml:"volume_cost"`
+	// SnapshotGBMonths is the snapshot storage used in the period. Snapshots are
+	// counted with the size of their volume, which is an upper bound
+	SnapshotGBMonths float64 `json:"snapshot_gb_months" yaml:"snapshot_gb_months"`
+	// SnapshotCost is the cost of the snapshot storage in USD
+	SnapshotCost float64 `json:"snapshot_cost" yaml:"snapshot_cost"`
+	// Total is the total cost in USD
+	Total float64 `json:"total" yaml:"total"`
+	// Incomplete is true when the price of some instance could not be found
+	Incomplete bool `json:"incomplete" yaml:"incomplete"`
+}
+
+// Cost reports what each dev space cost over a period, from its fleet requests
+// and the spot price history, and from its volumes and snapshots. AWS only
+// keeps deleted fleet requests and volumes for a short time, so only the usage
+// that is still visible is reported
+func (h *Handler) Cost(ctx context.Context, opts CostOptions) ([]CostItem, error) {
+	log := h.Logger
+	err := util.Validator.Struct(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.To.IsZero() {
+		opts.To = time.Now()
+	}
+	if opts.VolumePrice == 0 {
+		opts.VolumePrice = DefaultVolumePrice
+	}
+	if opts.SnapshotPrice == 0 {
+		opts.SnapshotPrice = DefaultSnapshotPrice
+	}
+
+	client := h.EC2Client
+	name, _ := util.GetTemplateNameAndVersion(opts.Name)
+	items := map[string]*CostItem{}
+	get := func(name string) *CostItem {
+		if _, ok := items[name]; !ok {
+			items[name] = &CostItem{Name: name}
+		}
+		return items[name]
+	}
+
+	// instances
+	fleets, err := helpers.GetFleetStatus(ctx, client, name)
+	if err != nil {
+		return nil, err
+	}
+
+	onDemandPrices := map[string]float64{}
+	for _, fleet := range fleets {
+		if fleet.CreateTime == nil || fleet.CreateTime.After(opts.To) {
+			continue
+		}
+
+		usages, err := helpers.GetFleetInstanceUsage(ctx, client, fleet)
+		if err != nil {
+			return nil, err
+		}
+
+		item := get(util.GetTag(fleet.Tags, "dev-spaces:name"))
+		for _, usage := range usages {
+			from, to := overlap(usage.Launch, usage.Termination, opts.From, opts.To)
+			if !to.After(from) {
+				continue
+			}
+			item.InstanceHours += to.Sub(from).Hours()
+
+			cost, err := h.instanceCost(ctx, usage, from, to, onDemandPrices)
+			if err != nil {
+				log.Warn(fmt.Sprintf("Error getting the price of %s (%s): %s", usage.InstanceID, usage.InstanceType, err))
+				item.Incomplete = true
+				continue
+			}
+			item.InstanceCost += cost
+		}
+	}
+
+	// storage
+	volumes, err := helpers.GetManagedVolumes(ctx, client, name)
+	if err != nil {
+		return nil, err
+	}
+	for _, volume := range volumes {
+		from, to := overlap(aws.TimeValue(volume.CreateTime), time.Time{}, opts.From, opts.To)
+		if !to.After(from) {
+			continue
+		}
+
+		item := get(util.GetTag(volume.Tags, "dev-spaces:name"))
+		gbMonths := float64(aws.Int32Value(volume.Size)) * to.Sub(from).Hours() / hoursPerMonth
+		item.VolumeGBMonths += gbMonths
+		item.VolumeCost += gbMonths * opts.VolumePrice
+	}
+
+	snapshots, err := helpers.GetManagedSnapshots(ctx, client, name)
+	if err != nil {
+		return nil, err
+	}
+	for _, snapshot := range snapshots {
+		from, to := overlap(aws.TimeValue(snapshot.StartTime), time.Time{}, opts.From, opts.To)
+		if !to.After(from) {
+			continue
+		}
+
+		item := get(util.GetTag(snapshot.Tags, "dev-spaces:name"))
+		gbMonths := float64(aws.Int32Value(snapshot.VolumeSize)) * to.Sub(from).Hours() / hoursPerMonth
+		item.SnapshotGBMonths += gbMonths
+		item.SnapshotCost += gbMonths * opts.SnapshotPrice
+	}
+
+	out := make([]CostItem, 0, len(items))
+	for _, item := range items {
+		item.Total = item.InstanceCost + item.VolumeCost + item.SnapshotCost
+		out = append(out, *item)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Name < out[j].Name
+	})
+
+	return out, nil
+}
+
+// instanceCost returns the cost of an instance between from and to, using the
+// spot price history for spot instances and the on-demand price otherwise
+func (h *Handler) instanceCost(ctx context.Context, usage helpers.FleetInstanceUsage, from, to time.Time, onDemandPrices map[string]float64) (float64, error) {
+	if usage.InstanceType == "" {
+		return 0, fmt.Errorf("unknown instance type")
+	}
+
+	if !usage.Spot {
+		price, ok := onDemandPrices[usage.InstanceType]
+		if !ok {
+			var err error
+			price, err = helpers.GetOnDemandPrice(ctx, h.PricingClient, h.Config.DefaultRegion, usage.InstanceType)
+			if err != nil {
+				return 0, err
+			}
+			onDemandPrices[usage.InstanceType] = price
+		}
+
+		return price * to.Sub(from).Hours(), nil
+	}
+
+	prices, err := helpers.GetSpotPriceHistory(ctx, h.EC2Client, usage.Zone, usage.InstanceType, from, to)
+	if err != nil {
+		return 0, err
+	}
+	if len(prices) == 0 {
+		return 0, fmt.Errorf("no spot price history in %s", usage.Zone)
+	}
+
+	return spotCost(prices, from, to)
+}
+
+// spotCost integrates the spot prices (sorted from the oldest) between from
+// and to. The oldest price is assumed to be in effect since from
+func spotCost(prices []types.SpotPrice, from, to time.Time) (float64, error) {
+	var total float64
+	for i, price := range prices {
+		start := aws.TimeValue(price.Timestamp)
+		if i == 0 || start.Before(from) {
+			start = from
+		}
+		end := to
+		if i+1 < len(prices) && prices[i+1].Timestamp.Before(to) {
+			end = *prices[i+1].Timestamp
+		}
+		if !end.After(start) {
+			continue
+		}
+
+		value, err := strconv.ParseFloat(aws.StringValue(price.SpotPrice), 64)
+		if err != nil {
+			return 0, err
+		}
+		total += value * end.Sub(start).Hours()
+	}
+
+	return total, nil
+}
+
+// overlap returns the part of [start, end] within [from, to]. A zero end
+// means the resource still exists
+func overlap(start, end, from, to time.Time) (time.Time, time.Time) {
+	if end.IsZero() || end.After(to) {
+		end = to
+	}
+	if start.Before(from) {
+		start = from
+	}
+
+	return start, end
+}
@@ -0,0 +1,127 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go-v2/service/pricing"
+)
+
+// fakePricingClient answers GetProducts with the on-demand prices of the
+// instance types and counts the calls
+type fakePricingClient struct {
+	prices map[string]float64
+	err    error
+	calls  int
+}
+
+func (f *fakePricingClient) GetProducts(ctx context.Context, params *pricing.GetProductsInput, optFns ...func(*pricing.Options)) (*pricing.GetProductsOutput, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	out := &pricing.GetProductsOutput{}
+	for _, filter := range params.Filters {
+		if price, ok := f.prices[aws.ToString(filter.Value)]; ok && aws.ToString(filter.Field) == "instanceType" {
+			out.PriceList = []string{fmt.Sprintf(`{"terms": {"OnDemand": {"offer": {"priceDimensions": {"dimension": {"pricePerUnit": {"USD": "%f"}}}}}}}`, price)}
+		}
+	}
+
+	return out, nil
+}
+
+// onDemandFleet returns an on-demand fleet of the dev space whose instances
+// ran for the given durations from start
+func onDemandFleet(name string, start time.Time, runs ...time.Duration) (types.FleetData, []types.HistoryRecordEntry) {
+	fleet := types.FleetData{
+		FleetId:    aws.String("fleet-1"),
+		CreateTime: aws.Time(start),
+		TargetCapacitySpecification: &types.TargetCapacitySpecification{
+			DefaultTargetCapacityType: types.DefaultTargetCapacityTypeOnDemand,
+		},
+		Tags: devSpaceTemplate(name, nil).Tags,
+	}
+
+	var history []types.HistoryRecordEntry
+	for i, run := range runs {
+		instanceID := fmt.Sprintf("i-%d", i)
+		history = append(history,
+			types.HistoryRecordEntry{
+				Timestamp: aws.Time(start),
+				EventInformation: &types.EventInformation{
+					InstanceId:       aws.String(instanceID),
+					EventSubType:     aws.String("launched"),
+					EventDescription: aws.String(`{"instanceType": "m5.large"}`),
+				},
+			},
+			types.HistoryRecordEntry{
+				Timestamp: aws.Time(start.Add(run)),
+				EventInformation: &types.EventInformation{
+					InstanceId:   aws.String(instanceID),
+					EventSubType: aws.String("terminated"),
+				},
+			},
+		)
+	}
+
+	return fleet, history
+}
+
+func TestHandler_Cost_onDemand(t *testing.T) {
+	to := time.Date(2023, time.September, 30, 0, 0, 0, 0, time.UTC)
+	from := to.AddDate(0, 0, -7)
+	fleet, history := onDemandFleet("myspace", from.Add(time.Hour), 10*time.Hour, 2*time.Hour)
+
+	tests := []struct {
+		name           string
+		pricing        *fakePricingClient
+		wantCost       float64
+		wantIncomplete bool
+		wantCalls      int
+	}{
+		{"priced once per instance type", &fakePricingClient{prices: map[string]float64{"m5.large": 0.096}}, 12 * 0.096, false, 1},
+		{"price list error", &fakePricingClient{err: errors.New("throttled")}, 0, true, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &fakeEC2Client{fleets: []types.FleetData{fleet}, fleetHistory: history}
+			h := NewHandler(Config{DefaultRegion: "us-east-1"}, client, tt.pricing, nopLogger{})
+
+			items, err := h.Cost(context.Background(), CostOptions{From: from, To: to})
+			if err != nil {
+				t.Fatalf("Handler.Cost() error = %v", err)
+			}
+			// the fake answers DescribeSnapshots with an untagged snapshot
+			var item *CostItem
+			for i := range items {
+				if items[i].Name == "myspace" {
+					item = &items[i]
+				}
+			}
+			if item == nil {
+				t.Fatalf("Handler.Cost() = %+v, want an item for myspace", items)
+			}
+
+			if item.InstanceHours != 12 {
+				t.Errorf("Handler.Cost() instance hours = %v, want 12", item.InstanceHours)
+			}
+			if math.Abs(item.InstanceCost-tt.wantCost) > 1e-9 {
+				t.Errorf("Handler.Cost() instance cost = %v, want %v", item.InstanceCost, tt.wantCost)
+			}
+			if item.Incomplete != tt.wantIncomplete {
+				t.Errorf("Handler.Cost() incomplete = %v, want %v", item.Incomplete, tt.wantIncomplete)
+			}
+			if tt.pricing.calls != tt.wantCalls {
+				t.Errorf("Handler.Cost() called the price list %d times, want %d", tt.pricing.calls, tt.wantCalls)
+			}
+		})
+	}
+}
@@ -18,10 +18,13 @@ type PreferedLaunchSpecs struct {
 }
 
 type AMIFilter struct {
-	ID    string `validate:"required_without=Name" yaml:"id"`
-	Name  string `validate:"required_without=ID" yaml:"name"`
-	Arch  string `yaml:"arch"`
-	Owner string `yaml:"owner"`
+	ID   string `validate:"required_without_all=Name Names" yaml:"id"`
+	Name string `validate:"required_without_all=ID Names" yaml:"name"`
+	// Names are the names of the image per architecture, used instead of Name
+	// so the same template works on every architecture
+	Names map[string]string `validate:"required_without_all=ID Name" yaml:"names"`
+	Arch  string            `yaml:"arch"`
+	Owner string            `yaml:"owner"`
 }
 
 type CreateOptions struct {
@@ -31,23 +34,27 @@ type CreateOptions struct {
 	KeyName             string              `validate:"required"`
 	InstanceProfileArn  string
 	StartupScriptPath   string
-	SecurityGroupIds    []string
-	StorageSize         int
-	HostAMI             *AMIFilter
+	// StartupScript is the content of the startup script, used instead of StartupScriptPath
+	StartupScript    string
+	SecurityGroupIds []string
+	StorageSize      int
+	HostAMI          *AMIFilter
+	// Tags are added to the launch template and the instances of the dev space
+	Tags map[string]string
 }
 
 type CreateOutput struct {
-	LaunchTemplateId *string
-	HostImage        *string
-	DevSpaceImage    *string
-	StorageVolumeId  *string
-	StorageSize      *int
+	LaunchTemplateId *string `json:"launch_template_id" yaml:"launch_template_id"`
+	HostImage        *string `json:"host_image" yaml:"host_image"`
+	DevSpaceImage    *string `json:"dev_space_image" yaml:"dev_space_image"`
+	StorageVolumeId  *string `json:"storage_volume_id" yaml:"storage_volume_id"`
+	StorageSize      *int    `json:"storage_size" yaml:"storage_size"`
 }
 
 type InstanceType types.InstanceType
 
-func (h *Handler) Create(ctx context.Context, opts CreateOptions) (CreateOutput, error) {
-	err := util.Validator.Struct(opts)
+func (h *Handler) Create(ctx context.Context, opts CreateOptions) (_ CreateOutput, err error) {
+	err = util.Validator.Struct(opts)
 	if err != nil {
 		return CreateOutput{}, err
 	}
@@ -61,7 +68,10 @@ func (h *Handler) Create(ctx context.Context, opts CreateOptions) (CreateOutput,
 
 	client := h.EC2Client
 	log := h.Logger
+	t := h.track("create", name)
+	defer func() { t.Done(err) }()
 
+	t.Phase("prepare", "Checking the key pair and images...")
 	// check if a launch template with the same name already exists
 	templateExists, err := helpers.TemplateExists(ctx, client, name)
 	if err != nil {
@@ -71,11 +81,13 @@ func (h *Handler) Create(ctx context.Context, opts CreateOptions) (CreateOutput,
 		return CreateOutput{}, fmt.Errorf("launch template with name %s already exists", name)
 	}
 
-	if opts.StartupScriptPath == "" {
+	if opts.StartupScript != "" {
+		startupScript = opts.StartupScript
+	} else if opts.StartupScriptPath == "" {
 		startupScript = DEFAULT_STARTUP_SCRIPT
-		log.Info("Using default startup script...")
+		log.Debug("Using default startup script...")
 	} else {
-		log.Info(fmt.Sprintf("Using custom startup script: %s", opts.StartupScriptPath))
+		log.Debug(fmt.Sprintf("Using custom startup script: %s", opts.StartupScriptPath))
 		script, err := util.RetrieveFile(opts.StartupScriptPath)
 		if err != nil {
 			return CreateOutput{}, err
@@ -111,12 +123,12 @@ func (h *Handler) Create(ctx context.Context, opts CreateOptions) (CreateOutput,
 			return CreateOutput{}, err
 		}
 	} else {
-		hostAMI, err = helpers.GetImageFromFilter(ctx, client, helpers.AMIFilter{
-			ID:    opts.HostAMI.ID,
-			Name:  opts.HostAMI.Name,
-			Arch:  opts.HostAMI.Arch,
-			Owner: opts.HostAMI.Owner,
-		})
+		// the host runs the dev space, so it has the same architecture
+		hostFilter, err := opts.HostAMI.forArch(string(devSpaceAMI.Architecture))
+		if err != nil {
+			return CreateOutput{}, fmt.Errorf("host_ami: %v", err)
+		}
+		hostAMI, err = helpers.GetImageFromFilter(ctx, client, hostFilter)
 		if err != nil {
 			return CreateOutput{}, err
 		}
@@ -127,6 +139,7 @@ func (h *Handler) Create(ctx context.Context, opts CreateOptions) (CreateOutput,
 		storageSize = *devSpaceAMI.BlockDeviceMappings[0].Ebs.VolumeSize
 	}
 
+	t.Phase("task-runner", "Creating spot task...")
 	taskRunner, err := helpers.CreateSpotTaskRunner(ctx, client, helpers.CreateSpotTaskInput{
 		Name:        &name,
 		AMIID:       devSpaceAMI.ImageId,
@@ -143,12 +156,13 @@ func (h *Handler) Create(ctx context.Context, opts CreateOptions) (CreateOutput,
 	if err != nil {
 		return CreateOutput{}, err
 	}
-	log.Info(fmt.Sprintf("Spot task created: %s - Waiting instance to be assigned..", *taskRunner.FleetId))
+	t.Created(ResourceFleetRequest, *taskRunner.FleetId, fmt.Sprintf("Spot task created: %s", *taskRunner.FleetId))
+	t.Waiting(ResourceFleetRequest, *taskRunner.FleetId, "Waiting instance to be assigned..")
 	id, err := helpers.WaitForFleetInstance(ctx, client, *taskRunner.FleetId, types.InstanceStateNameRunning)
 	if err != nil {
 		return CreateOutput{}, err
 	}
-	log.Info(fmt.Sprintf("Instance assigned: %s", id))
+	t.Created(ResourceInstance, id, fmt.Sprintf("Instance assigned: %s", id))
 
 	// get the volume id associated with the instance
 	instanceData, err := helpers.GetInstanceData(ctx, client, id)
@@ -159,7 +173,7 @@ func (h *Handler) Create(ctx context.Context, opts CreateOptions) (CreateOutput,
 	volumeZone := instanceData.Placement.AvailabilityZone
 
 	// tag the volume
-	log.Info(fmt.Sprintf("Tagging volume: %s", *volumeId))
+	t.Phase("volume", fmt.Sprintf("Tagging volume: %s", *volumeId))
 	_, err = client.CreateTags(ctx, &ec2.CreateTagsInput{
 		Resources: []string{*volumeId},
 		Tags:      util.GenerateTags(name),
@@ -168,8 +182,10 @@ func (h *Handler) Create(ctx context.Context, opts CreateOptions) (CreateOutput,
 		return CreateOutput{}, err
 	}
 
+	t.Created(ResourceVolume, *volumeId, fmt.Sprintf("Volume created: %s", *volumeId))
+
 	// cancel the spot task
-	log.Info(fmt.Sprintf("Stopping instance: %s", id))
+	t.Phase("cleanup", fmt.Sprintf("Stopping instance: %s", id))
 	err = helpers.CancelFleetRequests(ctx, client, []string{*taskRunner.FleetId})
 	if err != nil {
 		return CreateOutput{}, err
@@ -181,19 +197,24 @@ func (h *Handler) Create(ctx context.Context, opts CreateOptions) (CreateOutput,
 		return CreateOutput{}, err
 	}
 
-	log.Info(fmt.Sprintf("Waiting for instance: %s to finish.. This may take a few minutes..", id))
+	t.Waiting(ResourceInstance, id, fmt.Sprintf("Waiting for instance: %s to finish.. This may take a few minutes..", id))
 	id, err = helpers.WaitForFleetInstance(ctx, client, *taskRunner.FleetId, types.InstanceStateNameTerminated)
 	if err != nil {
 		return CreateOutput{}, err
 	}
 	// wait for ebs volume to be available
-	helpers.WaitUntilEBSUnattached(ctx, client, *volumeId)
+	t.Waiting(ResourceVolume, *volumeId, fmt.Sprintf("Waiting for volume %s to be detached", *volumeId))
+	err = helpers.WaitUntilEBSUnattached(ctx, client, *volumeId)
+	if err != nil {
+		t.Warn(fmt.Sprintf("Error waiting for volume %s to be detached", *volumeId), err)
+	}
 
 	// get the root device name fot this hostImage
 	hostDeviceName := *hostAMI.RootDeviceName
 	hostStorageSize := *hostAMI.BlockDeviceMappings[0].Ebs.VolumeSize
 
 	// create the launch template
+	t.Phase("launch-template", "Creating launch template...")
 	o, err := helpers.CreateLaunchTemplate(ctx, client, log, helpers.CreateLaunchTemplateInput{
 		Name:               name,
 		VolumeId:           *volumeId,
@@ -202,6 +223,7 @@ func (h *Handler) Create(ctx context.Context, opts CreateOptions) (CreateOutput,
 		SecurityGroupIds:   securityGroupIds,
 		InstanceProfileArn: &instanceProfileArn,
 		KeyName:            keyName,
+		Tags:               opts.Tags,
 		Host: helpers.CreateLaunchTemplateHost{
 			AMIID: *hostAMI.ImageId,
 			Device: helpers.CreateLaunchTemplateHostDevice{
@@ -216,7 +238,7 @@ func (h *Handler) Create(ctx context.Context, opts CreateOptions) (CreateOutput,
 	if err != nil {
 		return CreateOutput{}, err
 	}
-	log.Info(fmt.Sprintf("Launch template created: %s", *o.LaunchTemplateId))
+	t.Created(ResourceLaunchTemplate, *o.LaunchTemplateId, fmt.Sprintf("Launch template created: %s", *o.LaunchTemplateId))
 
 	return CreateOutput{
 		LaunchTemplateId: o.LaunchTemplateId,
@@ -2,7 +2,9 @@ package core
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
@@ -22,8 +24,10 @@ type DestroyOptions struct {
 	Name string `validate:"required"`
 }
 
-func (h *Handler) Destroy(ctx context.Context, opts DestroyOptions) error {
-	err := util.Validator.Struct(opts)
+// Destroy removes all the resources of a dev space. It goes on when a step
+// fails, and returns the errors of the failed steps
+func (h *Handler) Destroy(ctx context.Context, opts DestroyOptions) (err error) {
+	err = util.Validator.Struct(opts)
 	if err != nil {
 		return err
 	}
@@ -37,31 +41,57 @@ func (h *Handler) Destroy(ctx context.Context, opts DestroyOptions) error {
 		log:       log,
 	}
 
+	t := h.track("destroy", name)
+	defer func() { t.Done(err) }()
+
+	var errs []error
+	warn := func(message string, err error) {
+		t.Warn(message, err)
+		errs = append(errs, fmt.Errorf("%s: %w", strings.ToLower(message), err))
+	}
+
 	// Destroy spot requests
+	t.Phase("fleet-requests", "Cancelling fleet requests...")
 	_, err = helpers.CancelSpotRequests(ctx, client, log, name)
 	if err != nil {
-		log.Error(err.Error())
+		warn("Error cancelling fleet requests", err)
+	}
+
+	// Remove the DNS record
+	if h.Config.DNS.enabled() {
+		t.Phase("dns", "Removing DNS record...")
+		h.removeDNS(ctx, name)
 	}
 
 	// Destroy security groups
+	t.Phase("security-groups", "Destroying security groups...")
 	err = ds.destroySecurityGroups(ctx, name)
 	if err != nil {
-		log.Error(err.Error())
+		warn("Error destroying security groups", err)
+	}
+
+	// Destroy the snapshot of archived dev spaces
+	t.Phase("snapshots", "Destroying archive snapshots...")
+	err = ds.destroyArchiveSnapshots(ctx, name)
+	if err != nil {
+		warn("Error destroying archive snapshots", err)
 	}
 
 	// Destroy launch templates
+	t.Phase("launch-templates", "Destroying launch templates...")
 	err = ds.destroyLaunchTemplate(ctx, name)
 	if err != nil {
-		log.Error(err.Error())
+		warn("Error destroying launch templates", err)
 	}
 
 	// Destroy all the created volumes for this template
+	t.Phase("volumes", "Destroying volumes...")
 	err = ds.destroyVolumes(ctx, name)
 	if err != nil {
-		log.Error(err.Error())
+		warn("Error destroying volumes", err)
 	}
 
-	return nil
+	return errors.Join(errs...)
 }
 
 func (ds *DestroySpec) destroyVolumes(ctx context.Context, templateName string) error {
@@ -85,6 +115,30 @@ func (ds *DestroySpec) destroyVolumes(ctx context.Context, templateName string)
 	return nil
 }
 
+func (ds *DestroySpec) destroyArchiveSnapshots(ctx context.Context, templateName string) error {
+	launchTemplates, err := ds.getLaunchTemplate(ctx, templateName)
+	if err != nil {
+		return err
+	}
+
+	for _, launchTemplate := range launchTemplates {
+		snapshotID := util.GetTag(launchTemplate.Tags, snapshotIDTag)
+		if snapshotID == "" {
+			continue
+		}
+
+		ds.log.Info(fmt.Sprintf("Destroying snapshot %s", snapshotID))
+		_, err := ds.ec2Client.DeleteSnapshot(ctx, &ec2.DeleteSnapshotInput{
+			SnapshotId: aws.String(snapshotID),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (ds *DestroySpec) getVolumes(ctx context.Context, templateName string) ([]types.Volume, error) {
 	volumes, err := ds.ec2Client.DescribeVolumes(ctx, &ec2.DescribeVolumesInput{
 		Filters: []types.Filter{
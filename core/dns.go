@@ -0,0 +1,157 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/felipemarinho97/dev-spaces/core/helpers"
+	"github.com/felipemarinho97/dev-spaces/core/util"
+)
+
+// DNSProvider publishes the public IP of the dev spaces under a stable hostname
+type DNSProvider interface {
+	// UpsertRecord points hostname to ip
+	UpsertRecord(ctx context.Context, hostname, ip string) error
+	// DeleteRecord removes the record of hostname, if any
+	DeleteRecord(ctx context.Context, hostname string) error
+}
+
+type DNSConfig struct {
+	// Domain is the domain the hostnames of the dev spaces are created under
+	Domain string
+	// Provider manages the records, nil disables dynamic DNS
+	Provider DNSProvider
+}
+
+func (c DNSConfig) enabled() bool {
+	return c.Provider != nil && c.Domain != ""
+}
+
+// Hostname returns the stable hostname of a dev space, or an empty string
+// when dynamic DNS is disabled
+func (h *Handler) Hostname(name string) string {
+	if !h.Config.DNS.enabled() {
+		return ""
+	}
+
+	name, _ = util.GetTemplateNameAndVersion(name)
+	return fmt.Sprintf("%s.%s", strings.ToLower(name), strings.TrimSuffix(h.Config.DNS.Domain, "."))
+}
+
+// updateDNS points the hostname of a dev space to its public IP, returning
+// the hostname or an empty string when dynamic DNS is disabled or fails
+func (h *Handler) updateDNS(ctx context.Context, name, ip string) string {
+	hostname := h.Hostname(name)
+	if hostname == "" {
+		return ""
+	}
+
+	err := h.Config.DNS.Provider.UpsertRecord(ctx, hostname, ip)
+	if err != nil {
+		h.Logger.Warn(fmt.Sprintf("Error updating the DNS record of %s: %s", hostname, err))
+		return ""
+	}
+	h.Logger.Info(fmt.Sprintf("Updated DNS record %s -> %s", hostname, ip))
+
+	return hostname
+}
+
+// removeDNS removes the DNS records of a dev space, or of all dev spaces when
+// name is empty
+func (h *Handler) removeDNS(ctx context.Context, name string) {
+	if !h.Config.DNS.enabled() {
+		return
+	}
+
+	names := []string{name}
+	if name == "" {
+		names = nil
+		launchTemplates, err := helpers.GetLaunchTemplates(ctx, h.EC2Client)
+		if err != nil {
+			h.Logger.Warn("Error listing the dev spaces to remove their DNS records: ", err)
+			return
+		}
+		for _, template := range launchTemplates.LaunchTemplates {
+			names = append(names, *template.LaunchTemplateName)
+		}
+	}
+
+	for _, name := range names {
+		hostname := h.Hostname(name)
+		err := h.Config.DNS.Provider.DeleteRecord(ctx, hostname)
+		if err != nil {
+			h.Logger.Warn(fmt.Sprintf("Error removing the DNS record of %s: %s", hostname, err))
+			continue
+		}
+		h.Logger.Debug(fmt.Sprintf("Removed DNS record %s", hostname))
+	}
+}
+
+// WebhookDNS manages the records through an HTTP endpoint. Records are
+// upserted with a POST and removed with a DELETE of a JSON body with the
+// hostname and the IP, authenticated with a bearer token
+type WebhookDNS struct {
+	Endpoint string
+	Token    string
+	Client   *http.Client
+}
+
+type webhookDNSRecord struct {
+	Hostname string `json:"hostname"`
+	IP       string `json:"ip,omitempty"`
+}
+
+func NewWebhookDNS(endpoint, token string) *WebhookDNS {
+	return &WebhookDNS{
+		Endpoint: endpoint,
+		Token:    token,
+		Client:   &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+func (w *WebhookDNS) UpsertRecord(ctx context.Context, hostname, ip string) error {
+	return w.send(ctx, http.MethodPost, webhookDNSRecord{Hostname: hostname, IP: ip})
+}
+
+func (w *WebhookDNS) DeleteRecord(ctx context.Context, hostname string) error {
+	return w.send(ctx, http.MethodDelete, webhookDNSRecord{Hostname: hostname})
+}
+
+func (w *WebhookDNS) send(ctx context.Context, method string, record webhookDNSRecord) error {
+	body, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, w.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if w.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+w.Token)
+	}
+
+	resp, err := w.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// deleting a record that does not exist is not an error
+	if method == http.MethodDelete && resp.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s %s: %s: %s", method, w.Endpoint, resp.Status, strings.TrimSpace(string(msg)))
+	}
+
+	return nil
+}
@@ -0,0 +1,169 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/route53"
+	"github.com/aws/aws-sdk-go-v2/service/route53/types"
+)
+
+const (
+	// DefaultRoute53TTL is the TTL in seconds of the records created by Route53DNS
+	DefaultRoute53TTL = 60
+
+	// route53OwnerValue marks the records created by dev-spaces. Route 53
+	// records can not be tagged, so each A record is paired with a TXT record
+	// with this value, and only the records owned are deleted
+	route53OwnerValue = `"managed-by=dev-spaces"`
+)
+
+// IRoute53Client is the part of the Route 53 API used by Route53DNS
+type IRoute53Client interface {
+	ChangeResourceRecordSets(ctx context.Context, params *route53.ChangeResourceRecordSetsInput, optFns ...func(*route53.Options)) (*route53.ChangeResourceRecordSetsOutput, error)
+	ListResourceRecordSets(ctx context.Context, params *route53.ListResourceRecordSetsInput, optFns ...func(*route53.Options)) (*route53.ListResourceRecordSetsOutput, error)
+}
+
+// Route53DNS manages the records of the dev spaces in a Route 53 hosted zone
+type Route53DNS struct {
+	Client       IRoute53Client
+	HostedZoneID string
+	TTL          int64
+}
+
+func NewRoute53DNS(client IRoute53Client, hostedZoneID string) *Route53DNS {
+	return &Route53DNS{
+		Client:       client,
+		HostedZoneID: hostedZoneID,
+		TTL:          DefaultRoute53TTL,
+	}
+}
+
+// UpsertRecord points hostname to ip. An A record not owned by dev-spaces is
+// never taken over, and the owner value is added to the TXT values already at
+// hostname instead of replacing them
+func (r *Route53DNS) UpsertRecord(ctx context.Context, hostname, ip string) error {
+	a, txt, err := r.recordSets(ctx, hostname)
+	if err != nil {
+		return err
+	}
+	owned := txt != nil && hasRecordValue(*txt, route53OwnerValue)
+	if a != nil && !owned {
+		return fmt.Errorf("%s already has an A record not managed by dev-spaces", hostname)
+	}
+
+	owner := r.recordSet(hostname, types.RRTypeTxt, route53OwnerValue)
+	if txt != nil && !owned {
+		owner = *txt
+		owner.ResourceRecords = append(append([]types.ResourceRecord{}, txt.ResourceRecords...),
+			types.ResourceRecord{Value: aws.String(route53OwnerValue)})
+	}
+
+	record := r.recordSet(hostname, types.RRTypeA, ip)
+	_, err = r.Client.ChangeResourceRecordSets(ctx, &route53.ChangeResourceRecordSetsInput{
+		HostedZoneId: aws.String(r.HostedZoneID),
+		ChangeBatch: &types.ChangeBatch{
+			Comment: aws.String(fmt.Sprintf("dev-spaces: point %s to %s", hostname, ip)),
+			Changes: []types.Change{
+				{Action: types.ChangeActionUpsert, ResourceRecordSet: &record},
+				{Action: types.ChangeActionUpsert, ResourceRecordSet: &owner},
+			},
+		},
+	})
+	return err
+}
+
+// DeleteRecord deletes the A record of hostname and the owner value of its TXT
+// record, if they are owned by dev-spaces. The other TXT values are kept
+func (r *Route53DNS) DeleteRecord(ctx context.Context, hostname string) error {
+	a, txt, err := r.recordSets(ctx, hostname)
+	if err != nil {
+		return err
+	}
+	if txt == nil || !hasRecordValue(*txt, route53OwnerValue) {
+		return nil
+	}
+
+	// the deleted record sets must match the current ones
+	var changes []types.Change
+	if a != nil {
+		changes = append(changes, types.Change{Action: types.ChangeActionDelete, ResourceRecordSet: a})
+	}
+	if len(txt.ResourceRecords) == 1 {
+		changes = append(changes, types.Change{Action: types.ChangeActionDelete, ResourceRecordSet: txt})
+	} else {
+		others := *txt
+		others.ResourceRecords = nil
+		for _, record := range txt.ResourceRecords {
+			if aws.ToString(record.Value) != route53OwnerValue {
+				others.ResourceRecords = append(others.ResourceRecords, record)
+			}
+		}
+		changes = append(changes, types.Change{Action: types.ChangeActionUpsert, ResourceRecordSet: &others})
+	}
+
+	_, err = r.Client.ChangeResourceRecordSets(ctx, &route53.ChangeResourceRecordSetsInput{
+		HostedZoneId: aws.String(r.HostedZoneID),
+		ChangeBatch: &types.ChangeBatch{
+			Comment: aws.String(fmt.Sprintf("dev-spaces: remove %s", hostname)),
+			Changes: changes,
+		},
+	})
+	return err
+}
+
+// recordSets returns the A and TXT record sets at hostname, nil when missing
+func (r *Route53DNS) recordSets(ctx context.Context, hostname string) (a, txt *types.ResourceRecordSet, err error) {
+	name := fqdn(hostname)
+	out, err := r.Client.ListResourceRecordSets(ctx, &route53.ListResourceRecordSetsInput{
+		HostedZoneId:    aws.String(r.HostedZoneID),
+		StartRecordName: aws.String(name),
+		StartRecordType: types.RRTypeA,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, recordSet := range out.ResourceRecordSets {
+		if !strings.EqualFold(aws.ToString(recordSet.Name), name) {
+			break
+		}
+		recordSet := recordSet
+		switch recordSet.Type {
+		case types.RRTypeA:
+			a = &recordSet
+		case types.RRTypeTxt:
+			txt = &recordSet
+		}
+	}
+
+	return a, txt, nil
+}
+
+func (r *Route53DNS) recordSet(hostname string, recordType types.RRType, value string) types.ResourceRecordSet {
+	return types.ResourceRecordSet{
+		Name: aws.String(fqdn(hostname)),
+		Type: recordType,
+		TTL:  aws.Int64(r.TTL),
+		ResourceRecords: []types.ResourceRecord{
+			{Value: aws.String(value)},
+		},
+	}
+}
+
+func hasRecordValue(recordSet types.ResourceRecordSet, value string) bool {
+	for _, record := range recordSet.ResourceRecords {
+		if aws.ToString(record.Value) == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// fqdn returns the hostname with the trailing dot Route 53 returns
+func fqdn(hostname string) string {
+	return strings.TrimSuffix(hostname, ".") + "."
+}
@@ -0,0 +1,234 @@
+package core
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/route53"
+	"github.com/aws/aws-sdk-go-v2/service/route53/types"
+)
+
+// fakeRoute53Client keeps the record sets of a single hosted zone in memory
+type fakeRoute53Client struct {
+	recordSets []types.ResourceRecordSet
+	changes    []types.Change
+}
+
+func (f *fakeRoute53Client) ChangeResourceRecordSets(ctx context.Context, params *route53.ChangeResourceRecordSetsInput, optFns ...func(*route53.Options)) (*route53.ChangeResourceRecordSetsOutput, error) {
+	for _, change := range params.ChangeBatch.Changes {
+		f.changes = append(f.changes, change)
+		f.remove(*change.ResourceRecordSet)
+		if change.Action != types.ChangeActionDelete {
+			f.recordSets = append(f.recordSets, *change.ResourceRecordSet)
+		}
+	}
+	sort.Slice(f.recordSets, func(i, j int) bool {
+		if *f.recordSets[i].Name != *f.recordSets[j].Name {
+			return *f.recordSets[i].Name < *f.recordSets[j].Name
+		}
+		return f.recordSets[i].Type < f.recordSets[j].Type
+	})
+
+	return &route53.ChangeResourceRecordSetsOutput{}, nil
+}
+
+func (f *fakeRoute53Client) ListResourceRecordSets(ctx context.Context, params *route53.ListResourceRecordSetsInput, optFns ...func(*route53.Options)) (*route53.ListResourceRecordSetsOutput, error) {
+	out := &route53.ListResourceRecordSetsOutput{}
+	for _, recordSet := range f.recordSets {
+		if *recordSet.Name < *params.StartRecordName {
+			continue
+		}
+		out.ResourceRecordSets = append(out.ResourceRecordSets, recordSet)
+	}
+
+	return out, nil
+}
+
+func (f *fakeRoute53Client) remove(recordSet types.ResourceRecordSet) {
+	recordSets := f.recordSets[:0]
+	for _, r := range f.recordSets {
+		if *r.Name == *recordSet.Name && r.Type == recordSet.Type {
+			continue
+		}
+		recordSets = append(recordSets, r)
+	}
+	f.recordSets = recordSets
+}
+
+func (f *fakeRoute53Client) records() map[string]string {
+	records := map[string]string{}
+	for _, recordSet := range f.recordSets {
+		var values []string
+		for _, record := range recordSet.ResourceRecords {
+			values = append(values, *record.Value)
+		}
+		records[*recordSet.Name+" "+string(recordSet.Type)] = strings.Join(values, " ")
+	}
+
+	return records
+}
+
+func recordSet(name string, recordType types.RRType, value string) types.ResourceRecordSet {
+	return types.ResourceRecordSet{
+		Name:            aws.String(name),
+		Type:            recordType,
+		TTL:             aws.Int64(300),
+		ResourceRecords: []types.ResourceRecord{{Value: aws.String(value)}},
+	}
+}
+
+func TestRoute53DNS_UpsertRecord(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing []types.ResourceRecordSet
+		hostname string
+		ip       string
+		want     map[string]string
+		wantErr  bool
+	}{
+		{
+			name:     "creates the A record and its owner TXT record",
+			hostname: "myspace.dev.example.com",
+			ip:       "10.0.0.1",
+			want: map[string]string{
+				"myspace.dev.example.com. A":   "10.0.0.1",
+				"myspace.dev.example.com. TXT": route53OwnerValue,
+			},
+		},
+		{
+			name: "updates the IP of an existing record",
+			existing: []types.ResourceRecordSet{
+				recordSet("myspace.dev.example.com.", types.RRTypeA, "10.0.0.1"),
+				recordSet("myspace.dev.example.com.", types.RRTypeTxt, route53OwnerValue),
+			},
+			hostname: "myspace.dev.example.com.",
+			ip:       "10.0.0.2",
+			want: map[string]string{
+				"myspace.dev.example.com. A":   "10.0.0.2",
+				"myspace.dev.example.com. TXT": route53OwnerValue,
+			},
+		},
+		{
+			name: "refuses to take over an A record not owned by dev-spaces",
+			existing: []types.ResourceRecordSet{
+				recordSet("myspace.dev.example.com.", types.RRTypeA, "10.0.0.1"),
+			},
+			hostname: "myspace.dev.example.com",
+			ip:       "10.0.0.2",
+			want: map[string]string{
+				"myspace.dev.example.com. A": "10.0.0.1",
+			},
+			wantErr: true,
+		},
+		{
+			name: "adds the owner value to an existing TXT record",
+			existing: []types.ResourceRecordSet{
+				recordSet("myspace.dev.example.com.", types.RRTypeTxt, `"v=spf1 -all"`),
+			},
+			hostname: "myspace.dev.example.com",
+			ip:       "10.0.0.1",
+			want: map[string]string{
+				"myspace.dev.example.com. A":   "10.0.0.1",
+				"myspace.dev.example.com. TXT": `"v=spf1 -all" ` + route53OwnerValue,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &fakeRoute53Client{recordSets: tt.existing}
+			r := NewRoute53DNS(client, "Z123")
+
+			err := r.UpsertRecord(context.Background(), tt.hostname, tt.ip)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Route53DNS.UpsertRecord() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := client.records(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Route53DNS.UpsertRecord() records = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRoute53DNS_DeleteRecord(t *testing.T) {
+	tests := []struct {
+		name        string
+		existing    []types.ResourceRecordSet
+		hostname    string
+		want        map[string]string
+		wantChanges int
+	}{
+		{
+			name: "deletes the records owned by dev-spaces",
+			existing: []types.ResourceRecordSet{
+				recordSet("myspace.dev.example.com.", types.RRTypeA, "10.0.0.1"),
+				recordSet("myspace.dev.example.com.", types.RRTypeTxt, route53OwnerValue),
+				recordSet("other.dev.example.com.", types.RRTypeA, "10.0.0.2"),
+				recordSet("other.dev.example.com.", types.RRTypeTxt, route53OwnerValue),
+			},
+			hostname: "myspace.dev.example.com",
+			want: map[string]string{
+				"other.dev.example.com. A":   "10.0.0.2",
+				"other.dev.example.com. TXT": route53OwnerValue,
+			},
+			wantChanges: 2,
+		},
+		{
+			name: "keeps the records not owned by dev-spaces",
+			existing: []types.ResourceRecordSet{
+				recordSet("myspace.dev.example.com.", types.RRTypeA, "10.0.0.1"),
+			},
+			hostname: "myspace.dev.example.com",
+			want: map[string]string{
+				"myspace.dev.example.com. A": "10.0.0.1",
+			},
+			wantChanges: 0,
+		},
+		{
+			name: "keeps the TXT values not owned by dev-spaces",
+			existing: []types.ResourceRecordSet{
+				recordSet("myspace.dev.example.com.", types.RRTypeA, "10.0.0.1"),
+				{
+					Name: aws.String("myspace.dev.example.com."),
+					Type: types.RRTypeTxt,
+					TTL:  aws.Int64(300),
+					ResourceRecords: []types.ResourceRecord{
+						{Value: aws.String(`"v=spf1 -all"`)},
+						{Value: aws.String(route53OwnerValue)},
+					},
+				},
+			},
+			hostname: "myspace.dev.example.com",
+			want: map[string]string{
+				"myspace.dev.example.com. TXT": `"v=spf1 -all"`,
+			},
+			wantChanges: 2,
+		},
+		{
+			name:        "does nothing when there is no record",
+			hostname:    "myspace.dev.example.com",
+			want:        map[string]string{},
+			wantChanges: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &fakeRoute53Client{recordSets: tt.existing}
+			r := NewRoute53DNS(client, "Z123")
+
+			err := r.DeleteRecord(context.Background(), tt.hostname)
+			if err != nil {
+				t.Fatalf("Route53DNS.DeleteRecord() error = %v", err)
+			}
+			if got := client.records(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Route53DNS.DeleteRecord() records = %v, want %v", got, tt.want)
+			}
+			if len(client.changes) != tt.wantChanges {
+				t.Errorf("Route53DNS.DeleteRecord() changes = %d, want %d", len(client.changes), tt.wantChanges)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package core
+
+import "time"
+
+type EventType string
+
+const (
+	// EventPhaseStarted is emitted when an operation enters a phase
+	EventPhaseStarted EventType = "phase-started"
+	// EventPhaseFinished is emitted when a phase ends, with Err set if it failed
+	EventPhaseFinished EventType = "phase-finished"
+	// EventResourceCreated is emitted when an AWS resource is created
+	EventResourceCreated EventType = "resource-created"
+	// EventWaiting is emitted when an operation starts waiting on a resource
+	EventWaiting EventType = "waiting"
+	// EventWarning is emitted when an operation hits an error it can go on from
+	EventWarning EventType = "warning"
+)
+
+type ResourceType string
+
+const (
+	ResourceLaunchTemplate ResourceType = "launch-template"
+	ResourceFleetRequest   ResourceType = "fleet-request"
+	ResourceInstance       ResourceType = "instance"
+	ResourceVolume         ResourceType = "volume"
+	ResourceSnapshot       ResourceType = "snapshot"
+	ResourceSecurityGroup  ResourceType = "security-group"
+)
+
+// Event is a step of a core operation
+type Event struct {
+	Type EventType
+	Time time.Time
+	// Operation is the core operation emitting the event (e.g. "start")
+	Operation string
+	// Name of the dev space
+	Name string
+	// Phase is the phase the operation is in
+	Phase string
+	// ResourceType is the type of the resource created or waited on
+	ResourceType ResourceType
+	// ResourceID is the ID of the resource created or waited on
+	ResourceID string
+	// Message is a human readable description of the event
+	Message string
+	// Err is the error that ended a phase or caused a warning
+	Err error
+}
+
+// EventHandler receives the events of the core operations
+type EventHandler interface {
+	HandleEvent(event Event)
+}
+
+// EventHandlerFunc adapts a function to an EventHandler
+type EventHandlerFunc func(event Event)
+
+func (f EventHandlerFunc) HandleEvent(event Event) {
+	f(event)
+}
+
+// Subscribe registers a handler that receives the events of the operations of
+// the handler
+func (h *Handler) Subscribe(handler EventHandler) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.subscribers = append(h.subscribers, handler)
+}
+
+func (h *Handler) emit(event Event) {
+	h.mu.RLock()
+	subscribers := h.subscribers
+	h.mu.RUnlock()
+
+	for _, subscriber := range subscribers {
+		subscriber.HandleEvent(event)
+	}
+}
+
+// tracker emits the events of a running operation
+type tracker struct {
+	h         *Handler
+	operation string
+	name      string
+	phase     string
+}
+
+func (h *Handler) track(operation, name string) *tracker {
+	return &tracker{h: h, operation: operation, name: name}
+}
+
+func (t *tracker) emit(event Event) {
+	event.Time = time.Now()
+	event.Operation = t.operation
+	event.Name = t.name
+	if event.Phase == "" {
+		event.Phase = t.phase
+	}
+	t.h.emit(event)
+}
+
+// Phase finishes the current phase and starts a new one
+func (t *tracker) Phase(phase, message string) {
+	t.finish(nil)
+	t.phase = phase
+	t.emit(Event{Type: EventPhaseStarted, Message: message})
+}
+
+// Created reports a resource created in the current phase
+func (t *tracker) Created(resourceType ResourceType, id, message string) {
+	t.emit(Event{Type: EventResourceCreated, ResourceType: resourceType, ResourceID: id, Message: message})
+}
+
+// Waiting reports that the current phase is waiting on a resource
+func (t *tracker) Waiting(resourceType ResourceType, id, message string) {
+	t.emit(Event{Type: EventWaiting, ResourceType: resourceType, ResourceID: id, Message: message})
+}
+
+// Warn reports an error the operation goes on from
+func (t *tracker) Warn(message string, err error) {
+	t.emit(Event{Type: EventWarning, Message: message, Err: err})
+}
+
+// Done finishes the current phase, failing it with err if not nil
+func (t *tracker) Done(err error) {
+	t.finish(err)
+}
+
+func (t *tracker) finish(err error) {
+	if t.phase == "" {
+		return
+	}
+
+	message := t.phase + " finished"
+	if err != nil {
+		message = t.phase + " failed: " + err.Error()
+	}
+	t.emit(Event{Type: EventPhaseFinished, Message: message, Err: err})
+	t.phase = ""
+}
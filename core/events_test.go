@@ -0,0 +1,237 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/felipemarinho97/invest-path/clients"
+)
+
+// fakeEC2Client answers the calls of the archive, unarchive and destroy
+// operations from memory, the other calls panic
+type fakeEC2Client struct {
+	clients.IEC2Client
+	templates             []types.LaunchTemplate
+	volumes               []types.Volume
+	fleets                []types.FleetData
+	fleetHistory          []types.HistoryRecordEntry
+	instanceTypes         []string
+	createTagsErr         error
+	deleteTemplateErr     error
+	deletedVolumes        []string
+	deletedLaunchTemplate []string
+}
+
+func (f *fakeEC2Client) DescribeLaunchTemplates(ctx context.Context, params *ec2.DescribeLaunchTemplatesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeLaunchTemplatesOutput, error) {
+	return &ec2.DescribeLaunchTemplatesOutput{LaunchTemplates: f.templates}, nil
+}
+
+func (f *fakeEC2Client) DescribeVolumes(ctx context.Context, params *ec2.DescribeVolumesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeVolumesOutput, error) {
+	return &ec2.DescribeVolumesOutput{Volumes: f.volumes}, nil
+}
+
+func (f *fakeEC2Client) DescribeFleets(ctx context.Context, params *ec2.DescribeFleetsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeFleetsOutput, error) {
+	return &ec2.DescribeFleetsOutput{Fleets: f.fleets}, nil
+}
+
+func (f *fakeEC2Client) DescribeFleetHistory(ctx context.Context, params *ec2.DescribeFleetHistoryInput, optFns ...func(*ec2.Options)) (*ec2.DescribeFleetHistoryOutput, error) {
+	return &ec2.DescribeFleetHistoryOutput{HistoryRecords: f.fleetHistory}, nil
+}
+
+func (f *fakeEC2Client) DescribeFleetInstances(ctx context.Context, params *ec2.DescribeFleetInstancesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeFleetInstancesOutput, error) {
+	return &ec2.DescribeFleetInstancesOutput{}, nil
+}
+
+func (f *fakeEC2Client) DescribeLaunchTemplateVersions(ctx context.Context, params *ec2.DescribeLaunchTemplateVersionsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeLaunchTemplateVersionsOutput, error) {
+	return &ec2.DescribeLaunchTemplateVersionsOutput{LaunchTemplateVersions: []types.LaunchTemplateVersion{
+		{LaunchTemplateData: &types.ResponseLaunchTemplateData{ImageId: aws.String("ami-1")}},
+	}}, nil
+}
+
+func (f *fakeEC2Client) DescribeImages(ctx context.Context, params *ec2.DescribeImagesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeImagesOutput, error) {
+	return &ec2.DescribeImagesOutput{Images: []types.Image{
+		{ImageId: aws.String("ami-1"), Architecture: types.ArchitectureValuesX8664},
+	}}, nil
+}
+
+func (f *fakeEC2Client) GetInstanceTypesFromInstanceRequirements(ctx context.Context, params *ec2.GetInstanceTypesFromInstanceRequirementsInput, optFns ...func(*ec2.Options)) (*ec2.GetInstanceTypesFromInstanceRequirementsOutput, error) {
+	out := &ec2.GetInstanceTypesFromInstanceRequirementsOutput{}
+	for _, instanceType := range f.instanceTypes {
+		out.InstanceTypes = append(out.InstanceTypes, types.InstanceTypeInfoFromInstanceRequirements{InstanceType: aws.String(instanceType)})
+	}
+
+	return out, nil
+}
+
+func (f *fakeEC2Client) DescribeSecurityGroups(ctx context.Context, params *ec2.DescribeSecurityGroupsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeSecurityGroupsOutput, error) {
+	return &ec2.DescribeSecurityGroupsOutput{}, nil
+}
+
+func (f *fakeEC2Client) CreateSnapshot(ctx context.Context, params *ec2.CreateSnapshotInput, optFns ...func(*ec2.Options)) (*ec2.CreateSnapshotOutput, error) {
+	return &ec2.CreateSnapshotOutput{SnapshotId: aws.String("snap-1")}, nil
+}
+
+func (f *fakeEC2Client) DescribeSnapshots(ctx context.Context, params *ec2.DescribeSnapshotsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeSnapshotsOutput, error) {
+	return &ec2.DescribeSnapshotsOutput{Snapshots: []types.Snapshot{
+		{SnapshotId: aws.String("snap-1"), State: types.SnapshotStateCompleted},
+	}}, nil
+}
+
+func (f *fakeEC2Client) CreateVolume(ctx context.Context, params *ec2.CreateVolumeInput, optFns ...func(*ec2.Options)) (*ec2.CreateVolumeOutput, error) {
+	return &ec2.CreateVolumeOutput{VolumeId: aws.String("vol-2")}, nil
+}
+
+func (f *fakeEC2Client) CreateTags(ctx context.Context, params *ec2.CreateTagsInput, optFns ...func(*ec2.Options)) (*ec2.CreateTagsOutput, error) {
+	if f.createTagsErr != nil {
+		return nil, f.createTagsErr
+	}
+	return &ec2.CreateTagsOutput{}, nil
+}
+
+func (f *fakeEC2Client) DeleteTags(ctx context.Context, params *ec2.DeleteTagsInput, optFns ...func(*ec2.Options)) (*ec2.DeleteTagsOutput, error) {
+	return &ec2.DeleteTagsOutput{}, nil
+}
+
+func (f *fakeEC2Client) DeleteVolume(ctx context.Context, params *ec2.DeleteVolumeInput, optFns ...func(*ec2.Options)) (*ec2.DeleteVolumeOutput, error) {
+	f.deletedVolumes = append(f.deletedVolumes, *params.VolumeId)
+	return &ec2.DeleteVolumeOutput{}, nil
+}
+
+func (f *fakeEC2Client) DeleteLaunchTemplate(ctx context.Context, params *ec2.DeleteLaunchTemplateInput, optFns ...func(*ec2.Options)) (*ec2.DeleteLaunchTemplateOutput, error) {
+	if f.deleteTemplateErr != nil {
+		return nil, f.deleteTemplateErr
+	}
+	f.deletedLaunchTemplate = append(f.deletedLaunchTemplate, *params.LaunchTemplateId)
+	return &ec2.DeleteLaunchTemplateOutput{}, nil
+}
+
+// nopLogger discards the log of the operations
+type nopLogger struct{}
+
+func (nopLogger) Debug(args ...interface{}) {}
+func (nopLogger) Info(args ...interface{})  {}
+func (nopLogger) Warn(args ...interface{})  {}
+func (nopLogger) Error(args ...interface{}) {}
+func (nopLogger) Fatal(args ...interface{}) {}
+func (nopLogger) Panic(args ...interface{}) {}
+
+func devSpaceTemplate(name string, tags map[string]string) types.LaunchTemplate {
+	template := types.LaunchTemplate{
+		LaunchTemplateId:   aws.String("lt-1"),
+		LaunchTemplateName: aws.String(name),
+		Tags: []types.Tag{
+			{Key: aws.String("managed-by"), Value: aws.String("dev-spaces")},
+			{Key: aws.String("dev-spaces:name"), Value: aws.String(name)},
+		},
+	}
+	for key, value := range tags {
+		template.Tags = append(template.Tags, types.Tag{Key: aws.String(key), Value: aws.String(value)})
+	}
+
+	return template
+}
+
+// recordEvents subscribes to the events of the handler, keeping the type,
+// phase and resource of each
+func recordEvents(h *Handler) *[]string {
+	var events []string
+	h.Subscribe(EventHandlerFunc(func(event Event) {
+		e := string(event.Type) + " " + event.Operation + "/" + event.Phase
+		if event.ResourceID != "" {
+			e += " " + string(event.ResourceType) + ":" + event.ResourceID
+		}
+		if event.Err != nil {
+			e += " error"
+		}
+		events = append(events, e)
+	}))
+
+	return &events
+}
+
+func TestHandler_Archive_events(t *testing.T) {
+	client := &fakeEC2Client{
+		templates: []types.LaunchTemplate{devSpaceTemplate("myspace", map[string]string{"dev-spaces:volume-id": "vol-1"})},
+		volumes:   []types.Volume{{VolumeId: aws.String("vol-1")}},
+	}
+	h := NewHandler(Config{}, client, nil, nopLogger{})
+	events := recordEvents(h)
+
+	out, err := h.Archive(context.Background(), ArchiveOptions{Name: "myspace"})
+	if err != nil {
+		t.Fatalf("Handler.Archive() error = %v", err)
+	}
+	if out.SnapshotID != "snap-1" || !reflect.DeepEqual(client.deletedVolumes, []string{"vol-1"}) {
+		t.Errorf("Handler.Archive() = %+v, deleted volumes %v", out, client.deletedVolumes)
+	}
+
+	want := []string{
+		"phase-started archive/prepare",
+		"phase-finished archive/prepare",
+		"phase-started archive/snapshot",
+		"resource-created archive/snapshot snapshot:snap-1",
+		"waiting archive/snapshot snapshot:snap-1",
+		"phase-finished archive/snapshot",
+		"phase-started archive/delete-volume",
+		"phase-finished archive/delete-volume",
+	}
+	if !reflect.DeepEqual(*events, want) {
+		t.Errorf("Handler.Archive() events = %q, want %q", *events, want)
+	}
+}
+
+func TestHandler_Destroy_events(t *testing.T) {
+	client := &fakeEC2Client{
+		templates:         []types.LaunchTemplate{devSpaceTemplate("myspace", nil)},
+		deleteTemplateErr: errors.New("access denied"),
+	}
+	h := NewHandler(Config{}, client, nil, nopLogger{})
+	events := recordEvents(h)
+
+	err := h.Destroy(context.Background(), DestroyOptions{Name: "myspace"})
+	if err == nil {
+		t.Fatal("Handler.Destroy() error = nil, want the launch template error")
+	}
+
+	want := []string{
+		"phase-started destroy/fleet-requests",
+		"phase-finished destroy/fleet-requests",
+		"phase-started destroy/security-groups",
+		"phase-finished destroy/security-groups",
+		"phase-started destroy/snapshots",
+		"phase-finished destroy/snapshots",
+		"phase-started destroy/launch-templates",
+		"warning destroy/launch-templates error",
+		"phase-finished destroy/launch-templates",
+		"phase-started destroy/volumes",
+		"phase-finished destroy/volumes error",
+	}
+	if !reflect.DeepEqual(*events, want) {
+		t.Errorf("Handler.Destroy() events = %q, want %q", *events, want)
+	}
+}
+
+func TestHandler_Unarchive_deletesVolumeOnFailure(t *testing.T) {
+	client := &fakeEC2Client{
+		templates: []types.LaunchTemplate{devSpaceTemplate("myspace", map[string]string{
+			snapshotIDTag:     "snap-1",
+			"dev-spaces:zone": "us-east-1a",
+		})},
+		volumes:       []types.Volume{{VolumeId: aws.String("vol-2"), State: types.VolumeStateAvailable}},
+		createTagsErr: errors.New("throttled"),
+	}
+	h := NewHandler(Config{}, client, nil, nopLogger{})
+
+	_, err := h.Unarchive(context.Background(), UnarchiveOptions{Name: "myspace"})
+	if err == nil {
+		t.Fatalf("Handler.Unarchive() succeeded, want an error")
+	}
+	if !reflect.DeepEqual(client.deletedVolumes, []string{"vol-2"}) {
+		t.Errorf("Handler.Unarchive() deleted volumes %v, want [vol-2]", client.deletedVolumes)
+	}
+}
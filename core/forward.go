@@ -0,0 +1,235 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/felipemarinho97/dev-spaces/core/util"
+	"github.com/felipemarinho97/dev-spaces/core/util/ssh"
+)
+
+type Forward struct {
+	// LocalAddr is the local address to listen on (e.g. 127.0.0.1:8080)
+	LocalAddr string `validate:"required"`
+	// RemoteAddr is the address to connect to from the dev space (e.g. localhost:8080)
+	RemoteAddr string `validate:"required"`
+}
+
+type ForwardOptions struct {
+	// Name of the dev space
+	Name string `validate:"required"`
+	// SSHKey is the path of the SSH key
+	SSHKey string `validate:"required"`
+	// Host forwards through the host (port 22) instead of the dev space (port 2222)
+	Host bool
+	// User is the dev space user (defaults to root), ignored for the host
+	User string
+	// Forwards are the local ports to forward
+	Forwards []Forward `validate:"required,min=1,dive"`
+	// CheckInterval is the interval to check if the dev space IP changed
+	CheckInterval time.Duration `validate:"min=0"`
+}
+
+// forwardKeepaliveTimeout bounds the wait for the keepalive of the SSH connection
+const forwardKeepaliveTimeout = 10 * time.Second
+
+type forwarder struct {
+	h           *Handler
+	opts        ForwardOptions
+	identityKey string
+
+	mu     sync.Mutex
+	client *ssh.SSHClient
+	ip     string
+}
+
+// Forward forwards local ports to the dev space until ctx is done. The SSH
+// connection is re-established when the dev space IP changes (e.g. after a restart)
+func (h *Handler) Forward(ctx context.Context, opts ForwardOptions) error {
+	err := util.Validator.Struct(opts)
+	if err != nil {
+		return err
+	}
+
+	identityKey, err := util.RetrieveFile(opts.SSHKey)
+	if err != nil {
+		return err
+	}
+
+	if opts.CheckInterval == 0 {
+		opts.CheckInterval = 30 * time.Second
+	}
+
+	f := &forwarder{
+		h:           h,
+		opts:        opts,
+		identityKey: identityKey,
+	}
+	defer f.close()
+
+	// connect upfront to fail fast when the dev space is not running
+	_, err = f.get(ctx)
+	if err != nil {
+		return err
+	}
+
+	var listeners []net.Listener
+	defer func() {
+		for _, l := range listeners {
+			l.Close()
+		}
+	}()
+
+	for _, forward := range opts.Forwards {
+		l, err := net.Listen("tcp", forward.LocalAddr)
+		if err != nil {
+			return err
+		}
+		listeners = append(listeners, l)
+		h.Logger.Info(fmt.Sprintf("Forwarding %s -> %s", l.Addr(), forward.RemoteAddr))
+
+		go f.serve(ctx, l, forward.RemoteAddr)
+	}
+
+	ticker := time.NewTicker(opts.CheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			f.refresh(ctx)
+		}
+	}
+}
+
+// get returns the current SSH connection, connecting if needed
+func (f *forwarder) get(ctx context.Context) (*ssh.SSHClient, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.client != nil {
+		return f.client, nil
+	}
+
+	ip, err := f.h.getPublicIP(ctx, f.opts.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := connect(ip, f.identityKey, f.opts.Host, f.opts.User)
+	if err != nil {
+		return nil, err
+	}
+	f.client = client
+	f.ip = ip
+
+	return client, nil
+}
+
+// refresh drops the SSH connection if the dev space IP changed or the
+// connection is broken, so the next forwarded connection reconnects
+func (f *forwarder) refresh(ctx context.Context) {
+	log := f.h.Logger
+
+	ip, err := f.h.getPublicIP(ctx, f.opts.Name)
+	if err != nil {
+		log.Warn(fmt.Sprintf("Error resolving dev space IP: %s", err))
+		return
+	}
+
+	// the keepalive runs outside the lock, so a broken connection does not
+	// block the forwarded connections while it is checked
+	f.mu.Lock()
+	client, currentIP := f.client, f.ip
+	f.mu.Unlock()
+
+	stale := client == nil || ip != currentIP || !client.Alive(forwardKeepaliveTimeout)
+	if stale {
+		f.drop(client)
+		_, err = f.get(ctx)
+		if err != nil {
+			log.Warn(fmt.Sprintf("Error reconnecting to %s: %s", ip, err))
+			return
+		}
+		log.Info(fmt.Sprintf("Reconnected to %s", ip))
+	}
+}
+
+func (f *forwarder) serve(ctx context.Context, l net.Listener, remoteAddr string) {
+	for {
+		local, err := l.Accept()
+		if err != nil {
+			return
+		}
+
+		go f.handle(ctx, local, remoteAddr)
+	}
+}
+
+func (f *forwarder) handle(ctx context.Context, local net.Conn, remoteAddr string) {
+	defer local.Close()
+
+	var remote net.Conn
+	for attempt := 0; attempt < 2; attempt++ {
+		client, err := f.get(ctx)
+		if err != nil {
+			f.h.Logger.Warn(fmt.Sprintf("Error connecting to the dev space: %s", err))
+			return
+		}
+
+		remote, err = client.Dial("tcp", remoteAddr)
+		if err == nil {
+			break
+		}
+		f.h.Logger.Warn(fmt.Sprintf("Error connecting to %s: %s", remoteAddr, err))
+		if ssh.IsRejected(err) {
+			return
+		}
+
+		// the SSH connection is broken, retry once on a new one instead of
+		// waiting for the next check
+		f.drop(client)
+	}
+	if remote == nil {
+		return
+	}
+	defer remote.Close()
+
+	done := make(chan struct{}, 2)
+	go func() {
+		io.Copy(remote, local)
+		done <- struct{}{}
+	}()
+	go func() {
+		io.Copy(local, remote)
+		done <- struct{}{}
+	}()
+	<-done
+}
+
+// drop closes the SSH connection if it is still the current one, so the next
+// forwarded connection reconnects
+func (f *forwarder) drop(client *ssh.SSHClient) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if client != nil && f.client == client {
+		f.client.Close()
+		f.client = nil
+	}
+}
+
+func (f *forwarder) close() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.client != nil {
+		f.client.Close()
+		f.client = nil
+	}
+}
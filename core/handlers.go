@@ -1,24 +1,38 @@
 package core
 
 import (
+	"sync"
+
+	"github.com/felipemarinho97/dev-spaces/core/helpers"
 	"github.com/felipemarinho97/dev-spaces/core/log"
 	"github.com/felipemarinho97/invest-path/clients"
 )
 
 type Config struct {
 	DefaultRegion string
+	// Budget limits the spend of the fleet requests placed by Start and EditSpec
+	Budget Budget
+	// DNS publishes the public IP of the dev spaces started by Start and EditSpec
+	DNS DNSConfig
 }
 
 type Handler struct {
 	EC2Client clients.IEC2Client
-	Logger    log.Logger
-	Config    Config
+	// PricingClient is a client of the AWS Price List API, which is only
+	// available in a few regions (e.g. us-east-1)
+	PricingClient helpers.PricingClient
+	Logger        log.Logger
+	Config        Config
+
+	mu          sync.RWMutex
+	subscribers []EventHandler
 }
 
-func NewHandler(cfg Config, ec2Client clients.IEC2Client, logger log.Logger) *Handler {
+func NewHandler(cfg Config, ec2Client clients.IEC2Client, pricingClient helpers.PricingClient, logger log.Logger) *Handler {
 	return &Handler{
-		EC2Client: ec2Client,
-		Logger:    logger,
-		Config:    cfg,
+		EC2Client:     ec2Client,
+		PricingClient: pricingClient,
+		Logger:        logger,
+		Config:        cfg,
 	}
 }
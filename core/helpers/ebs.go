@@ -3,6 +3,7 @@ package helpers
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -30,7 +31,6 @@ func CreateEBSVolume(ctx context.Context, client clients.IEC2Client, name string
 		Iops:       aws.Int32(3000),
 	})
 	if err != nil {
-		fmt.Println(err.Error())
 		return nil, err
 	}
 
@@ -62,18 +62,19 @@ func DetachEBSVolume(ctx context.Context, client clients.IEC2Client, volumeID st
 	return out, nil
 }
 
+// VolumeWaitTimeout bounds the waits for volume state changes
+const VolumeWaitTimeout = 10 * time.Minute
+
 func WaitUntilEBSUnattached(ctx context.Context, client clients.IEC2Client, volumeID string) error {
-	for {
+	waiter := util.NewWaiter(fmt.Sprintf("volume %s to be detached", volumeID), VolumeWaitTimeout)
+	return waiter.Wait(ctx, func(ctx context.Context) (bool, error) {
 		isEBSAttached, err := IsEBSAttached(ctx, client, volumeID)
 		if err != nil {
-			return err
+			return false, err
 		}
 
-		if !isEBSAttached {
-			return nil
-		}
-		time.Sleep(1 * time.Second)
-	}
+		return !isEBSAttached, nil
+	})
 }
 
 func IsEBSAttached(ctx context.Context, client clients.IEC2Client, volumeID string) (bool, error) {
@@ -93,19 +94,185 @@ func IsEBSAttached(ctx context.Context, client clients.IEC2Client, volumeID stri
 }
 
 func WaitForEBSVolume(ctx context.Context, client clients.IEC2Client, volumeID string, state types.VolumeState) error {
-	for {
-		vol, err := client.DescribeVolumes(ctx, &ec2.DescribeVolumesInput{
+	waiter := util.NewWaiter(fmt.Sprintf("volume %s to be %s", volumeID, state), VolumeWaitTimeout)
+	return waiter.Wait(ctx, func(ctx context.Context) (bool, error) {
+		vol, err := GetEBSVolume(ctx, client, volumeID)
+		if err != nil {
+			return false, err
+		}
+
+		return vol.State == state, nil
+	})
+}
+
+// the type, IOPS and throughput of a volume are kept as tags on its
+// snapshots, so the volumes restored from them get the same performance
+const (
+	VolumeTypeTag       = "dev-spaces:volume-type"
+	VolumeIOPSTag       = "dev-spaces:volume-iops"
+	VolumeThroughputTag = "dev-spaces:volume-throughput"
+)
+
+// VolumeSpecTags returns the tags that record the type, IOPS and throughput of a volume
+func VolumeSpecTags(volume *types.Volume) []types.Tag {
+	tags := []types.Tag{
+		{Key: aws.String(VolumeTypeTag), Value: aws.String(string(volume.VolumeType))},
+	}
+	if volume.Iops != nil {
+		tags = append(tags, types.Tag{Key: aws.String(VolumeIOPSTag), Value: aws.String(fmt.Sprint(*volume.Iops))})
+	}
+	if volume.Throughput != nil {
+		tags = append(tags, types.Tag{Key: aws.String(VolumeThroughputTag), Value: aws.String(fmt.Sprint(*volume.Throughput))})
+	}
+
+	return tags
+}
+
+// volumeSpecFromTags returns the type, IOPS and throughput recorded by
+// VolumeSpecTags, or the gp3 baseline for snapshots taken without them
+func volumeSpecFromTags(tags []types.Tag) (volumeType types.VolumeType, iops, throughput *int32) {
+	volumeType = types.VolumeType(util.GetTag(tags, VolumeTypeTag))
+	if volumeType == "" {
+		return types.VolumeTypeGp3, aws.Int32(3000), aws.Int32(125)
+	}
+
+	parse := func(key string) *int32 {
+		value, err := strconv.ParseInt(util.GetTag(tags, key), 10, 32)
+		if err != nil {
+			return nil
+		}
+		return aws.Int32(int32(value))
+	}
+
+	// IOPS can only be set on gp3, io1 and io2 volumes, and throughput on gp3
+	switch volumeType {
+	case types.VolumeTypeGp3:
+		return volumeType, parse(VolumeIOPSTag), parse(VolumeThroughputTag)
+	case types.VolumeTypeIo1, types.VolumeTypeIo2:
+		return volumeType, parse(VolumeIOPSTag), nil
+	}
+
+	return volumeType, nil, nil
+}
+
+// CreateEBSVolumeFromSnapshot creates a volume from a snapshot, with the type,
+// IOPS and throughput of the volume the snapshot was taken from
+func CreateEBSVolumeFromSnapshot(ctx context.Context, client clients.IEC2Client, name string, snapshotID string, az string) (*ec2.CreateVolumeOutput, error) {
+	snapshots, err := client.DescribeSnapshots(ctx, &ec2.DescribeSnapshotsInput{
+		SnapshotIds: []string{snapshotID},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(snapshots.Snapshots) == 0 {
+		return nil, fmt.Errorf("no snapshot found with ID %s", snapshotID)
+	}
+
+	volumeType, iops, throughput := volumeSpecFromTags(snapshots.Snapshots[0].Tags)
+	out, err := client.CreateVolume(ctx, &ec2.CreateVolumeInput{
+		AvailabilityZone: &az,
+		SnapshotId:       &snapshotID,
+		VolumeType:       volumeType,
+		ClientToken:      aws.String(uuid.NewV4().String()),
+		TagSpecifications: []types.TagSpecification{
+			{
+				ResourceType: types.ResourceTypeVolume,
+				Tags:         util.GenerateTags(name),
+			},
+		},
+		Throughput: throughput,
+		Iops:       iops,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+func DeleteEBSVolume(ctx context.Context, client clients.IEC2Client, volumeID string) error {
+	_, err := client.DeleteVolume(ctx, &ec2.DeleteVolumeInput{
+		VolumeId: aws.String(volumeID),
+	})
+	return err
+}
+
+func GetEBSVolume(ctx context.Context, client clients.IEC2Client, volumeID string) (*types.Volume, error) {
+	vol, err := client.DescribeVolumes(ctx, &ec2.DescribeVolumesInput{
+		VolumeIds: []string{volumeID},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(vol.Volumes) == 0 {
+		return nil, fmt.Errorf("no volume found with ID %s", volumeID)
+	}
+
+	return &vol.Volumes[0], nil
+}
+
+func ModifyEBSVolumeSize(ctx context.Context, client clients.IEC2Client, volumeID string, size int32) error {
+	_, err := client.ModifyVolume(ctx, &ec2.ModifyVolumeInput{
+		VolumeId: aws.String(volumeID),
+		Size:     aws.Int32(size),
+	})
+	return err
+}
+
+// WaitForEBSVolumeModification waits until the new size of a modified volume
+// can be used, which happens when the modification is optimizing or completed
+func WaitForEBSVolumeModification(ctx context.Context, client clients.IEC2Client, volumeID string) error {
+	waiter := util.NewWaiter(fmt.Sprintf("modification of volume %s", volumeID), VolumeWaitTimeout)
+	return waiter.Wait(ctx, func(ctx context.Context) (bool, error) {
+		out, err := client.DescribeVolumesModifications(ctx, &ec2.DescribeVolumesModificationsInput{
 			VolumeIds: []string{volumeID},
 		})
 		if err != nil {
-			return err
+			return false, err
 		}
-		if len(vol.Volumes) == 0 {
-			return fmt.Errorf("no volume found with ID %s", volumeID)
+		if len(out.VolumesModifications) == 0 {
+			return false, fmt.Errorf("no modification found for volume %s", volumeID)
 		}
-		if vol.Volumes[0].State == state {
-			return nil
+
+		modification := out.VolumesModifications[0]
+		switch modification.ModificationState {
+		case types.VolumeModificationStateOptimizing, types.VolumeModificationStateCompleted:
+			return true, nil
+		case types.VolumeModificationStateFailed:
+			return false, fmt.Errorf("modification of volume %s failed: %s", volumeID, util.GetValue(modification.StatusMessage))
 		}
-		time.Sleep(1 * time.Second)
+
+		return false, nil
+	})
+}
+
+// GetManagedVolumes returns the volumes of a dev space, or of all dev spaces
+// when name is empty
+func GetManagedVolumes(ctx context.Context, client clients.IEC2Client, name string) ([]types.Volume, error) {
+	filters := []types.Filter{
+		{
+			Name:   aws.String("tag:managed-by"),
+			Values: []string{"dev-spaces"},
+		},
+	}
+	if name != "" {
+		filters = append(filters, types.Filter{
+			Name:   aws.String("tag:dev-spaces:name"),
+			Values: []string{name},
+		})
+	}
+
+	var volumes []types.Volume
+	paginator := ec2.NewDescribeVolumesPaginator(client, &ec2.DescribeVolumesInput{
+		Filters: filters,
+	})
+	for paginator.HasMorePages() {
+		out, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		volumes = append(volumes, out.Volumes...)
 	}
+
+	return volumes, nil
 }
@@ -0,0 +1,83 @@
+package helpers
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+func (f *fakeEC2Client) DescribeSnapshots(ctx context.Context, params *ec2.DescribeSnapshotsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeSnapshotsOutput, error) {
+	return &ec2.DescribeSnapshotsOutput{Snapshots: f.snapshots}, nil
+}
+
+func (f *fakeEC2Client) CreateVolume(ctx context.Context, params *ec2.CreateVolumeInput, optFns ...func(*ec2.Options)) (*ec2.CreateVolumeOutput, error) {
+	f.createdVolume = params
+	return &ec2.CreateVolumeOutput{VolumeId: aws.String("vol-2")}, nil
+}
+
+func TestCreateEBSVolumeFromSnapshot(t *testing.T) {
+	tests := []struct {
+		name           string
+		volume         *types.Volume
+		wantType       types.VolumeType
+		wantIops       *int32
+		wantThroughput *int32
+	}{
+		{
+			name:           "gp3 volume",
+			volume:         &types.Volume{VolumeType: types.VolumeTypeGp3, Iops: aws.Int32(6000), Throughput: aws.Int32(250)},
+			wantType:       types.VolumeTypeGp3,
+			wantIops:       aws.Int32(6000),
+			wantThroughput: aws.Int32(250),
+		},
+		{
+			name:     "io2 volume",
+			volume:   &types.Volume{VolumeType: types.VolumeTypeIo2, Iops: aws.Int32(10000)},
+			wantType: types.VolumeTypeIo2,
+			wantIops: aws.Int32(10000),
+		},
+		{
+			// gp2 volumes report their baseline IOPS, which can not be set
+			name:     "gp2 volume",
+			volume:   &types.Volume{VolumeType: types.VolumeTypeGp2, Iops: aws.Int32(300)},
+			wantType: types.VolumeTypeGp2,
+		},
+		{
+			name:           "snapshot without the volume spec",
+			volume:         nil,
+			wantType:       types.VolumeTypeGp3,
+			wantIops:       aws.Int32(3000),
+			wantThroughput: aws.Int32(125),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			snapshot := types.Snapshot{SnapshotId: aws.String("snap-1")}
+			if tt.volume != nil {
+				snapshot.Tags = VolumeSpecTags(tt.volume)
+			}
+			client := &fakeEC2Client{snapshots: []types.Snapshot{snapshot}}
+
+			_, err := CreateEBSVolumeFromSnapshot(context.Background(), client, "myspace", "snap-1", "us-east-1a")
+			if err != nil {
+				t.Fatalf("CreateEBSVolumeFromSnapshot() error = %v", err)
+			}
+
+			got := client.createdVolume
+			if got.VolumeType != tt.wantType {
+				t.Errorf("CreateEBSVolumeFromSnapshot() type = %s, want %s", got.VolumeType, tt.wantType)
+			}
+			if !reflect.DeepEqual(got.Iops, tt.wantIops) {
+				t.Errorf("CreateEBSVolumeFromSnapshot() iops = %v, want %v", aws.ToInt32(got.Iops), aws.ToInt32(tt.wantIops))
+			}
+			if !reflect.DeepEqual(got.Throughput, tt.wantThroughput) {
+				t.Errorf("CreateEBSVolumeFromSnapshot() throughput = %v, want %v", aws.ToInt32(got.Throughput), aws.ToInt32(tt.wantThroughput))
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package helpers
 
 import (
 	"context"
+	"encoding/base64"
 	"fmt"
 	"net"
 	"time"
@@ -63,45 +64,131 @@ func GetManagedInstances(ctx context.Context, client clients.IEC2Client) (map[st
 	return managedInstances, nil
 }
 
-func WaitUntilReachable(host string, port int) error {
-	for {
-		conn, err := net.DialTimeout("tcp", fmt.Sprintf("%s:%d", host, port), 1*time.Second)
-		if err == nil {
-			conn.Close()
-			return nil
+// GetLatestInstance returns the latest instance of a dev space that is not
+// terminated, or its latest task runner when runner is true. It returns nil
+// when there is no such instance
+func GetLatestInstance(ctx context.Context, client clients.IEC2Client, name string, runner bool) (*types.Instance, error) {
+	instances, err := client.DescribeInstances(ctx, &ec2.DescribeInstancesInput{
+		Filters: []types.Filter{
+			{
+				Name:   aws.String("tag:managed-by"),
+				Values: []string{"dev-spaces"},
+			},
+			{
+				Name:   aws.String("tag:dev-spaces:name"),
+				Values: []string{name},
+			},
+			{
+				Name: aws.String("instance-state-name"),
+				Values: []string{
+					string(types.InstanceStateNamePending),
+					string(types.InstanceStateNameRunning),
+					string(types.InstanceStateNameShuttingDown),
+					string(types.InstanceStateNameStopping),
+					string(types.InstanceStateNameStopped),
+				},
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var latest *types.Instance
+	for _, reservation := range instances.Reservations {
+		for i := range reservation.Instances {
+			instance := &reservation.Instances[i]
+			if (util.GetTag(instance.Tags, RoleTag) == RoleRunner) != runner {
+				continue
+			}
+			if latest == nil || instance.LaunchTime.After(*latest.LaunchTime) {
+				latest = instance
+			}
 		}
-		time.Sleep(1 * time.Second)
 	}
+
+	return latest, nil
+}
+
+// GetConsoleOutput returns the latest console output of an instance
+func GetConsoleOutput(ctx context.Context, client clients.IEC2Client, instanceID string) (string, error) {
+	out, err := client.GetConsoleOutput(ctx, &ec2.GetConsoleOutputInput{
+		InstanceId: &instanceID,
+		Latest:     aws.Bool(true),
+	})
+	if err != nil {
+		// only the Nitro instances support the latest output, the others
+		// return the output buffered at their last boot or shutdown
+		out, err = client.GetConsoleOutput(ctx, &ec2.GetConsoleOutputInput{
+			InstanceId: &instanceID,
+		})
+		if err != nil {
+			return "", err
+		}
+	}
+	if out.Output == nil {
+		return "", nil
+	}
+
+	output, err := base64.StdEncoding.DecodeString(*out.Output)
+	if err != nil {
+		return "", err
+	}
+
+	return string(output), nil
+}
+
+const (
+	// InstanceWaitTimeout bounds the waits for fleet instances
+	InstanceWaitTimeout = 15 * time.Minute
+	// ReachableWaitTimeout bounds the waits for a port to be reachable
+	ReachableWaitTimeout = 10 * time.Minute
+)
+
+func WaitUntilReachable(ctx context.Context, host string, port int) error {
+	addr := fmt.Sprintf("%s:%d", host, port)
+	dialer := net.Dialer{Timeout: 1 * time.Second}
+	waiter := util.NewWaiter(fmt.Sprintf("%s to be reachable", addr), ReachableWaitTimeout)
+	return waiter.Wait(ctx, func(ctx context.Context) (bool, error) {
+		conn, err := dialer.DialContext(ctx, "tcp", addr)
+		if err != nil {
+			return false, util.Retryable(err)
+		}
+		conn.Close()
+
+		return true, nil
+	})
 }
 
 func WaitForFleetInstance(ctx context.Context, client clients.IEC2Client, requestID string, wantedState types.InstanceStateName) (string, error) {
-	for {
-		time.Sleep(time.Second * 1)
+	var instanceID string
+	waiter := util.NewWaiter(fmt.Sprintf("fleet %s instance to be %s", requestID, wantedState), InstanceWaitTimeout)
+	err := waiter.Wait(ctx, func(ctx context.Context) (bool, error) {
 		out2, err := client.DescribeFleetInstances(ctx, &ec2.DescribeFleetInstancesInput{
 			FleetId: &requestID,
 		})
 		if err != nil {
-			fmt.Println(err)
-			continue
+			return false, util.Retryable(err)
 		}
 
 		if len(out2.ActiveInstances) == 0 && wantedState == types.InstanceStateNameTerminated {
-			return "", nil
+			return true, nil
 		}
 
+		// an instance that cannot be described yet is retried, reporting the
+		// error if the wait times out
+		var describeErr error
 		for _, s := range out2.ActiveInstances {
-			time.Sleep(time.Second * 1)
 			instanceData, err := GetInstanceData(ctx, client, *s.InstanceId)
 			if err != nil {
-				// print unicode X to indicate error
-				fmt.Printf("\x1b[31m%s\x1b[0m\n", "\u2717")
+				describeErr = fmt.Errorf("error describing instance %s: %w", *s.InstanceId, err)
 				continue
 			}
 
 			if instanceData.State.Name == wantedState {
-				return *instanceData.InstanceId, nil
+				instanceID = *instanceData.InstanceId
+				return true, nil
 			}
-
 		}
 
 		// get fleet history
@@ -110,16 +197,22 @@ func WaitForFleetInstance(ctx context.Context, client clients.IEC2Client, reques
 			StartTime: aws.Time(time.Now().Add(-24 * time.Hour)),
 		})
 		if err != nil {
-			continue
+			return false, util.Retryable(err)
 		}
 
-		if len(out3.HistoryRecords) > 0 {
-			// iterate over history records, if there is a record with a status of error, return error and print error message
-			for _, record := range out3.HistoryRecords {
-				if record.EventType == "error" && record.EventInformation.EventDescription != nil {
-					return "", fmt.Errorf("error creating instance: %s", *record.EventInformation.EventDescription)
-				}
+		// if there is a record with a status of error, return error and print error message
+		for _, record := range out3.HistoryRecords {
+			if record.EventType == "error" && record.EventInformation.EventDescription != nil {
+				return false, fmt.Errorf("error creating instance: %s", *record.EventInformation.EventDescription)
 			}
 		}
-	}
+
+		if describeErr != nil {
+			return false, util.Retryable(describeErr)
+		}
+
+		return false, nil
+	})
+
+	return instanceID, err
 }
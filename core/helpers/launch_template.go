@@ -5,6 +5,7 @@ import (
 	"encoding/base64"
 	"errors"
 	"fmt"
+	"sort"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
@@ -24,6 +25,8 @@ type CreateLaunchTemplateInput struct {
 	KeyName            string
 	InstanceProfileArn *string
 	Host               CreateLaunchTemplateHost
+	// Tags are added to the launch template, its instances and volumes
+	Tags map[string]string
 }
 
 type CreateLaunchTemplateHost struct {
@@ -40,8 +43,6 @@ type CreateLaunchTemplateHostDevice struct {
 }
 
 func CreateLaunchTemplate(ctx context.Context, ec2Client clients.IEC2Client, log log.Logger, in CreateLaunchTemplateInput) (*types.LaunchTemplate, error) {
-	dataScript := base64.StdEncoding.EncodeToString([]byte(in.StartupScript))
-
 	// create security group
 	groupId, err := CreateSecurityGroup(ctx, ec2Client, log, in.Name)
 	if err != nil {
@@ -50,7 +51,132 @@ func CreateLaunchTemplate(ctx context.Context, ec2Client clients.IEC2Client, log
 
 	in.SecurityGroupIds = append(in.SecurityGroupIds, *groupId)
 
-	// lauch template data
+	// create launch template
+	log.Info("Creating launch template..")
+	o, err := ec2Client.CreateLaunchTemplate(ctx, &ec2.CreateLaunchTemplateInput{
+		LaunchTemplateName: aws.String(in.Name),
+		ClientToken:        aws.String(uuid.NewV4().String()),
+		LaunchTemplateData: launchTemplateData(in),
+		TagSpecifications: []types.TagSpecification{
+			{
+				ResourceType: types.ResourceTypeLaunchTemplate,
+				Tags: append(
+					generateTags(in.Name, in.Tags),
+					types.Tag{
+						Key:   aws.String("dev-spaces:zone"),
+						Value: &in.VolumeZone,
+					},
+					types.Tag{
+						Key:   aws.String("dev-spaces:volume-id"),
+						Value: &in.VolumeId,
+					},
+				),
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return o.LaunchTemplate, nil
+}
+
+// CreateLaunchTemplateVersion creates a version of the launch template of a
+// dev space and sets it as the default one. Unlike CreateLaunchTemplate, no
+// security group is created, so in must include the one of the dev space
+func CreateLaunchTemplateVersion(ctx context.Context, client clients.IEC2Client, templateID string, in CreateLaunchTemplateInput, description string) (*types.LaunchTemplateVersion, error) {
+	out, err := client.CreateLaunchTemplateVersion(ctx, &ec2.CreateLaunchTemplateVersionInput{
+		LaunchTemplateId:   aws.String(templateID),
+		ClientToken:        aws.String(uuid.NewV4().String()),
+		LaunchTemplateData: launchTemplateData(in),
+		VersionDescription: aws.String(description),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = SetDefaultLaunchTemplateVersion(ctx, client, templateID, *out.LaunchTemplateVersion.VersionNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	return out.LaunchTemplateVersion, nil
+}
+
+// SetDefaultLaunchTemplateVersion sets the version used when a dev space is
+// started without a version
+func SetDefaultLaunchTemplateVersion(ctx context.Context, client clients.IEC2Client, templateID string, version int64) error {
+	_, err := client.ModifyLaunchTemplate(ctx, &ec2.ModifyLaunchTemplateInput{
+		LaunchTemplateId: aws.String(templateID),
+		DefaultVersion:   aws.String(fmt.Sprint(version)),
+	})
+	return err
+}
+
+// GetLaunchTemplateVersions returns the versions of a launch template, newest
+// first. versions can be numbers, $Latest or $Default (all versions if empty)
+func GetLaunchTemplateVersions(ctx context.Context, client clients.IEC2Client, templateID string, versions ...string) ([]types.LaunchTemplateVersion, error) {
+	var out []types.LaunchTemplateVersion
+	var nextToken *string
+	for {
+		page, err := client.DescribeLaunchTemplateVersions(ctx, &ec2.DescribeLaunchTemplateVersionsInput{
+			LaunchTemplateId: aws.String(templateID),
+			Versions:         versions,
+			NextToken:        nextToken,
+		})
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, page.LaunchTemplateVersions...)
+
+		nextToken = page.NextToken
+		if nextToken == nil || *nextToken == "" {
+			break
+		}
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		return *out[i].VersionNumber > *out[j].VersionNumber
+	})
+
+	return out, nil
+}
+
+// DeleteLaunchTemplateVersions deletes versions of a launch template, the
+// default version can not be deleted
+func DeleteLaunchTemplateVersions(ctx context.Context, client clients.IEC2Client, templateID string, versions []int64) error {
+	// at most 200 versions are deleted per request
+	for start := 0; start < len(versions); start += 200 {
+		end := min(start+200, len(versions))
+		var batch []string
+		for _, version := range versions[start:end] {
+			batch = append(batch, fmt.Sprint(version))
+		}
+
+		out, err := client.DeleteLaunchTemplateVersions(ctx, &ec2.DeleteLaunchTemplateVersionsInput{
+			LaunchTemplateId: aws.String(templateID),
+			Versions:         batch,
+		})
+		if err != nil {
+			return err
+		}
+		if len(out.UnsuccessfullyDeletedLaunchTemplateVersions) > 0 {
+			failed := out.UnsuccessfullyDeletedLaunchTemplateVersions[0]
+			var reason string
+			if failed.ResponseError != nil {
+				reason = util.GetValue(failed.ResponseError.Message)
+			}
+			return fmt.Errorf("error deleting version %d: %s", aws.ToInt64(failed.VersionNumber), reason)
+		}
+	}
+
+	return nil
+}
+
+// launchTemplateData returns the data of the launch template of a dev space
+func launchTemplateData(in CreateLaunchTemplateInput) *types.RequestLaunchTemplateData {
+	dataScript := base64.StdEncoding.EncodeToString([]byte(in.StartupScript))
+
 	ltd := &types.RequestLaunchTemplateData{
 		KeyName: &in.KeyName,
 		ImageId: &in.Host.AMIID,
@@ -75,11 +201,11 @@ func CreateLaunchTemplate(ctx context.Context, ec2Client clients.IEC2Client, log
 		TagSpecifications: []types.LaunchTemplateTagSpecificationRequest{
 			{
 				ResourceType: types.ResourceTypeInstance,
-				Tags:         util.GenerateTags(in.Name),
+				Tags:         generateTags(in.Name, in.Tags),
 			},
 			{
 				ResourceType: types.ResourceTypeVolume,
-				Tags:         util.GenerateTags(in.Name),
+				Tags:         generateTags(in.Name, in.Tags),
 			},
 		},
 	}
@@ -90,34 +216,26 @@ func CreateLaunchTemplate(ctx context.Context, ec2Client clients.IEC2Client, log
 		}
 	}
 
-	// create launch template
-	log.Info("Creating launch template..")
-	o, err := ec2Client.CreateLaunchTemplate(ctx, &ec2.CreateLaunchTemplateInput{
-		LaunchTemplateName: aws.String(in.Name),
-		ClientToken:        aws.String(uuid.NewV4().String()),
-		LaunchTemplateData: ltd,
-		TagSpecifications: []types.TagSpecification{
-			{
-				ResourceType: types.ResourceTypeLaunchTemplate,
-				Tags: append(
-					util.GenerateTags(in.Name),
-					types.Tag{
-						Key:   aws.String("dev-spaces:zone"),
-						Value: &in.VolumeZone,
-					},
-					types.Tag{
-						Key:   aws.String("dev-spaces:volume-id"),
-						Value: &in.VolumeId,
-					},
-				),
-			},
-		},
-	})
-	if err != nil {
-		return nil, err
+	return ltd
+}
+
+// generateTags returns the tags of the resources of a dev space with extra tags
+func generateTags(name string, extra map[string]string) []types.Tag {
+	tags := util.GenerateTags(name)
+
+	keys := make([]string, 0, len(extra))
+	for key := range extra {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		tags = append(tags, types.Tag{
+			Key:   aws.String(key),
+			Value: aws.String(extra[key]),
+		})
 	}
 
-	return o.LaunchTemplate, nil
+	return tags
 }
 
 func GetDefaultLaunchTemplateVersion(ctx context.Context, client clients.IEC2Client, templateID string) (*types.LaunchTemplateVersion, error) {
@@ -188,3 +306,36 @@ func DeleteLaunchTemplate(ctx context.Context, client clients.IEC2Client, name s
 	})
 	return err
 }
+
+// SetLaunchTemplateTags creates or overwrites tags on a launch template
+func SetLaunchTemplateTags(ctx context.Context, client clients.IEC2Client, templateID string, tags map[string]string) error {
+	var t []types.Tag
+	for key, value := range tags {
+		t = append(t, types.Tag{
+			Key:   aws.String(key),
+			Value: aws.String(value),
+		})
+	}
+
+	_, err := client.CreateTags(ctx, &ec2.CreateTagsInput{
+		Resources: []string{templateID},
+		Tags:      t,
+	})
+	return err
+}
+
+// RemoveLaunchTemplateTags deletes tags from a launch template
+func RemoveLaunchTemplateTags(ctx context.Context, client clients.IEC2Client, templateID string, keys ...string) error {
+	var t []types.Tag
+	for _, key := range keys {
+		t = append(t, types.Tag{
+			Key: aws.String(key),
+		})
+	}
+
+	_, err := client.DeleteTags(ctx, &ec2.DeleteTagsInput{
+		Resources: []string{templateID},
+		Tags:      t,
+	})
+	return err
+}
@@ -0,0 +1,169 @@
+package helpers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go-v2/service/pricing"
+	pricingTypes "github.com/aws/aws-sdk-go-v2/service/pricing/types"
+	"github.com/felipemarinho97/invest-path/clients"
+)
+
+// GetInstanceTypesFromRequirements returns the instance types matching the dev
+// space instance requirements for the given architecture
+func GetInstanceTypesFromRequirements(ctx context.Context, client clients.IEC2Client, cpusSpec, minMemory int, arch types.ArchitectureType) ([]string, error) {
+	var instanceTypes []string
+	paginator := ec2.NewGetInstanceTypesFromInstanceRequirementsPaginator(client, &ec2.GetInstanceTypesFromInstanceRequirementsInput{
+		ArchitectureTypes:    []types.ArchitectureType{arch},
+		VirtualizationTypes:  []types.VirtualizationType{types.VirtualizationTypeHvm},
+		InstanceRequirements: NewInstanceRequirements(cpusSpec, minMemory),
+	})
+	for paginator.HasMorePages() {
+		out, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, instanceType := range out.InstanceTypes {
+			instanceTypes = append(instanceTypes, *instanceType.InstanceType)
+		}
+	}
+
+	return instanceTypes, nil
+}
+
+// GetInstanceTypesInfo returns the details of the given instance types
+func GetInstanceTypesInfo(ctx context.Context, client clients.IEC2Client, instanceTypes []string) (map[string]types.InstanceTypeInfo, error) {
+	infos := map[string]types.InstanceTypeInfo{}
+	for _, chunk := range chunks(instanceTypes, 100) {
+		paginator := ec2.NewDescribeInstanceTypesPaginator(client, &ec2.DescribeInstanceTypesInput{
+			InstanceTypes: toInstanceTypes(chunk),
+		})
+		for paginator.HasMorePages() {
+			out, err := paginator.NextPage(ctx)
+			if err != nil {
+				return nil, err
+			}
+			for _, info := range out.InstanceTypes {
+				infos[string(info.InstanceType)] = info
+			}
+		}
+	}
+
+	return infos, nil
+}
+
+// GetSpotPrices returns the current Linux spot price of the given instance
+// types in a zone. Instance types without spot offers are not returned
+func GetSpotPrices(ctx context.Context, client clients.IEC2Client, zone string, instanceTypes []string) (map[string]float64, error) {
+	prices := map[string]float64{}
+	for _, chunk := range chunks(instanceTypes, 100) {
+		paginator := ec2.NewDescribeSpotPriceHistoryPaginator(client, &ec2.DescribeSpotPriceHistoryInput{
+			AvailabilityZone:    aws.String(zone),
+			InstanceTypes:       toInstanceTypes(chunk),
+			ProductDescriptions: []string{"Linux/UNIX"},
+			StartTime:           aws.Time(time.Now()),
+		})
+		for paginator.HasMorePages() {
+			out, err := paginator.NextPage(ctx)
+			if err != nil {
+				return nil, err
+			}
+			for _, price := range out.SpotPriceHistory {
+				value, err := strconv.ParseFloat(aws.ToString(price.SpotPrice), 64)
+				if err != nil {
+					return nil, err
+				}
+				prices[string(price.InstanceType)] = value
+			}
+		}
+	}
+
+	return prices, nil
+}
+
+// PricingClient is the part of the AWS Price List API used by dev-spaces
+type PricingClient interface {
+	GetProducts(ctx context.Context, params *pricing.GetProductsInput, optFns ...func(*pricing.Options)) (*pricing.GetProductsOutput, error)
+}
+
+// GetOnDemandPrice returns the Linux on-demand hourly price of an instance
+// type in a region, from the AWS Price List API
+func GetOnDemandPrice(ctx context.Context, client PricingClient, region, instanceType string) (float64, error) {
+	filter := func(field, value string) pricingTypes.Filter {
+		return pricingTypes.Filter{
+			Type:  pricingTypes.FilterTypeTermMatch,
+			Field: aws.String(field),
+			Value: aws.String(value),
+		}
+	}
+
+	out, err := client.GetProducts(ctx, &pricing.GetProductsInput{
+		ServiceCode: aws.String("AmazonEC2"),
+		Filters: []pricingTypes.Filter{
+			filter("instanceType", instanceType),
+			filter("regionCode", region),
+			filter("operatingSystem", "Linux"),
+			filter("tenancy", "Shared"),
+			filter("preInstalledSw", "NA"),
+			filter("capacitystatus", "Used"),
+		},
+		MaxResults: aws.Int32(1),
+	})
+	if err != nil {
+		return 0, err
+	}
+	if len(out.PriceList) == 0 {
+		return 0, fmt.Errorf("no on-demand price found for %s in %s", instanceType, region)
+	}
+
+	return parseOnDemandPrice(out.PriceList[0])
+}
+
+// parseOnDemandPrice reads the USD price per unit of a price list product
+// (terms.OnDemand.<offer>.priceDimensions.<dimension>.pricePerUnit.USD)
+func parseOnDemandPrice(document string) (float64, error) {
+	var product map[string]interface{}
+	err := json.Unmarshal([]byte(document), &product)
+	if err != nil {
+		return 0, fmt.Errorf("invalid price list product: %w", err)
+	}
+
+	terms, _ := product["terms"].(map[string]interface{})
+	onDemand, _ := terms["OnDemand"].(map[string]interface{})
+	for _, offer := range onDemand {
+		offer, _ := offer.(map[string]interface{})
+		dimensions, _ := offer["priceDimensions"].(map[string]interface{})
+		for _, dimension := range dimensions {
+			dimension, _ := dimension.(map[string]interface{})
+			pricePerUnit, _ := dimension["pricePerUnit"].(map[string]interface{})
+			usd, ok := pricePerUnit["USD"].(string)
+			if ok {
+				return strconv.ParseFloat(usd, 64)
+			}
+		}
+	}
+
+	return 0, fmt.Errorf("unable to find the on-demand price in the price list")
+}
+
+func toInstanceTypes(instanceTypes []string) []types.InstanceType {
+	out := make([]types.InstanceType, 0, len(instanceTypes))
+	for _, instanceType := range instanceTypes {
+		out = append(out, types.InstanceType(instanceType))
+	}
+	return out
+}
+
+func chunks(items []string, size int) [][]string {
+	var out [][]string
+	for size < len(items) {
+		items, out = items[size:], append(out, items[:size])
+	}
+	return append(out, items)
+}
@@ -0,0 +1,78 @@
+package helpers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/pricing"
+)
+
+// fakePricingClient answers GetProducts with a price list document per
+// instance type and keeps the filters of the last call
+type fakePricingClient struct {
+	products map[string]string
+	filters  map[string]string
+	err      error
+}
+
+func (f *fakePricingClient) GetProducts(ctx context.Context, params *pricing.GetProductsInput, optFns ...func(*pricing.Options)) (*pricing.GetProductsOutput, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	f.filters = map[string]string{}
+	for _, filter := range params.Filters {
+		f.filters[aws.ToString(filter.Field)] = aws.ToString(filter.Value)
+	}
+
+	out := &pricing.GetProductsOutput{}
+	if product, ok := f.products[f.filters["instanceType"]]; ok {
+		out.PriceList = []string{product}
+	}
+
+	return out, nil
+}
+
+const m5LargeProduct = `{
+	"product": {"attributes": {"instanceType": "m5.large"}},
+	"terms": {"OnDemand": {"ABC.JRTCKXETXF": {"priceDimensions": {"ABC.JRTCKXETXF.6YS6EN2CT7": {
+		"unit": "Hrs",
+		"pricePerUnit": {"USD": "0.0960000000"}
+	}}}}}
+}`
+
+func TestGetOnDemandPrice(t *testing.T) {
+	tests := []struct {
+		name         string
+		instanceType string
+		products     map[string]string
+		err          error
+		want         float64
+		wantErr      bool
+	}{
+		{"price found", "m5.large", map[string]string{"m5.large": m5LargeProduct}, nil, 0.096, false},
+		{"no product", "m5.xlarge", map[string]string{"m5.large": m5LargeProduct}, nil, 0, true},
+		{"product without on-demand terms", "m5.large", map[string]string{"m5.large": `{"terms": {}}`}, nil, 0, true},
+		{"invalid product", "m5.large", map[string]string{"m5.large": `{`}, nil, 0, true},
+		{"api error", "m5.large", nil, errors.New("throttled"), 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &fakePricingClient{products: tt.products, err: tt.err}
+
+			got, err := GetOnDemandPrice(context.Background(), client, "us-west-2", tt.instanceType)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetOnDemandPrice() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("GetOnDemandPrice() = %v, want %v", got, tt.want)
+			}
+			if tt.err == nil && (client.filters["regionCode"] != "us-west-2" || client.filters["operatingSystem"] != "Linux") {
+				t.Errorf("GetOnDemandPrice() filters = %v, want the region and Linux", client.filters)
+			}
+		})
+	}
+}
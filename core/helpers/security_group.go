@@ -79,3 +79,28 @@ func CreateSecurityGroup(ctx context.Context, client clients.IEC2Client, log log
 
 	return out.GroupId, nil
 }
+
+// GetSecurityGroupID returns the ID of the security group created for a dev
+// space, or an empty string when there is none
+func GetSecurityGroupID(ctx context.Context, client clients.IEC2Client, name string) (string, error) {
+	out, err := client.DescribeSecurityGroups(ctx, &ec2.DescribeSecurityGroupsInput{
+		Filters: []types.Filter{
+			{
+				Name:   aws.String("tag:managed-by"),
+				Values: []string{"dev-spaces"},
+			},
+			{
+				Name:   aws.String("tag:dev-spaces:name"),
+				Values: []string{name},
+			},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	if len(out.SecurityGroups) == 0 {
+		return "", nil
+	}
+
+	return *out.SecurityGroups[0].GroupId, nil
+}
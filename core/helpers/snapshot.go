@@ -3,17 +3,32 @@ package helpers
 import (
 	"context"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/felipemarinho97/dev-spaces/core/util"
 	"github.com/felipemarinho97/invest-path/clients"
 )
 
+// CreateSnapshot takes a snapshot of a volume, tagged with the volume type,
+// IOPS and throughput
 func CreateSnapshot(ctx context.Context, client clients.IEC2Client, volumeID string) (string, error) {
+	volume, err := GetEBSVolume(ctx, client, volumeID)
+	if err != nil {
+		return "", err
+	}
+
 	snapshot, err := client.CreateSnapshot(ctx, &ec2.CreateSnapshotInput{
 		VolumeId: aws.String(volumeID),
+		TagSpecifications: []types.TagSpecification{
+			{
+				ResourceType: types.ResourceTypeSnapshot,
+				Tags:         VolumeSpecTags(volume),
+			},
+		},
 	})
 	if err != nil {
 		return "", err
@@ -22,23 +37,87 @@ func CreateSnapshot(ctx context.Context, client clients.IEC2Client, volumeID str
 	return *snapshot.SnapshotId, nil
 }
 
+// SnapshotWaitTimeout bounds the waits for snapshots, which can take a long
+// time for big volumes or copies between regions
+const SnapshotWaitTimeout = 2 * time.Hour
+
 func WaitForSnapshot(ctx context.Context, client clients.IEC2Client, snapshotID string) error {
-	for {
+	waiter := util.NewWaiter(fmt.Sprintf("snapshot %s to complete", snapshotID), SnapshotWaitTimeout)
+	waiter.MaxInterval = 30 * time.Second
+	return waiter.Wait(ctx, func(ctx context.Context) (bool, error) {
 		snapshot, err := client.DescribeSnapshots(ctx, &ec2.DescribeSnapshotsInput{
 			SnapshotIds: []string{snapshotID},
 		})
 		if err != nil {
-			return err
+			return false, err
 		}
-
-		if snapshot.Snapshots[0].State == types.SnapshotStateCompleted {
-			return nil
+		if len(snapshot.Snapshots) == 0 {
+			return false, fmt.Errorf("no snapshot found with ID %s", snapshotID)
 		}
 
-		if snapshot.Snapshots[0].State == types.SnapshotStateError {
-			return fmt.Errorf("snapshot %s is in error state: %s", snapshotID, *snapshot.Snapshots[0].StateMessage)
+		switch snapshot.Snapshots[0].State {
+		case types.SnapshotStateCompleted:
+			return true, nil
+		case types.SnapshotStateError:
+			return false, fmt.Errorf("snapshot %s is in error state: %s", snapshotID, aws.ToString(snapshot.Snapshots[0].StateMessage))
 		}
 
-		time.Sleep(time.Second * 1)
+		return false, nil
+	})
+}
+
+// GetBackupSnapshots returns the backup snapshots of a dev space, or of all
+// dev spaces when name is empty, sorted from newest to oldest
+func GetBackupSnapshots(ctx context.Context, client clients.IEC2Client, name string) ([]types.Snapshot, error) {
+	return getManagedSnapshots(ctx, client, name, types.Filter{
+		Name:   aws.String("tag:dev-spaces:backup"),
+		Values: []string{"true"},
+	})
+}
+
+// GetManagedSnapshots returns all the snapshots (backups and archives) of a
+// dev space, or of all dev spaces when name is empty, sorted from newest to oldest
+func GetManagedSnapshots(ctx context.Context, client clients.IEC2Client, name string) ([]types.Snapshot, error) {
+	return getManagedSnapshots(ctx, client, name)
+}
+
+func getManagedSnapshots(ctx context.Context, client clients.IEC2Client, name string, extraFilters ...types.Filter) ([]types.Snapshot, error) {
+	filters := append([]types.Filter{
+		{
+			Name:   aws.String("tag:managed-by"),
+			Values: []string{"dev-spaces"},
+		},
+	}, extraFilters...)
+	if name != "" {
+		filters = append(filters, types.Filter{
+			Name:   aws.String("tag:dev-spaces:name"),
+			Values: []string{name},
+		})
+	}
+
+	var snapshots []types.Snapshot
+	paginator := ec2.NewDescribeSnapshotsPaginator(client, &ec2.DescribeSnapshotsInput{
+		OwnerIds: []string{"self"},
+		Filters:  filters,
+	})
+	for paginator.HasMorePages() {
+		out, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, out.Snapshots...)
 	}
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].StartTime.After(*snapshots[j].StartTime)
+	})
+
+	return snapshots, nil
+}
+
+func DeleteSnapshot(ctx context.Context, client clients.IEC2Client, snapshotID string) error {
+	_, err := client.DeleteSnapshot(ctx, &ec2.DeleteSnapshotInput{
+		SnapshotId: aws.String(snapshotID),
+	})
+	return err
 }
@@ -3,7 +3,9 @@ package helpers
 import (
 	"context"
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -17,6 +19,28 @@ import (
 )
 
 func CreateSpotRequest(ctx context.Context, client clients.IEC2Client, name, version string, cpusSpec, minMemory int, maxPrice string, template *types.LaunchTemplate, timeout time.Duration) (*ec2.CreateFleetOutput, error) {
+	return CreateFleetRequest(ctx, client, name, version, cpusSpec, minMemory, maxPrice, template, timeout, types.DefaultTargetCapacityTypeSpot)
+}
+
+// NewInstanceRequirements returns the instance requirements used to request
+// dev space instances with at least the given vCPUs and memory (in MiB).
+func NewInstanceRequirements(cpusSpec, minMemory int) *types.InstanceRequirementsRequest {
+	return &types.InstanceRequirementsRequest{
+		VCpuCount: &types.VCpuCountRangeRequest{
+			Min: aws.Int32(int32(cpusSpec)),
+		},
+		MemoryMiB: &types.MemoryMiBRequest{
+			Min: aws.Int32(int32(minMemory)),
+		},
+		BareMetal:            types.BareMetalIncluded,
+		BurstablePerformance: types.BurstablePerformanceIncluded,
+	}
+}
+
+// CreateFleetRequest places a fleet request for the dev space launch template
+// using the given capacity type (spot or on-demand). The tags are added to the
+// tags of the fleet request.
+func CreateFleetRequest(ctx context.Context, client clients.IEC2Client, name, version string, cpusSpec, minMemory int, maxPrice string, template *types.LaunchTemplate, timeout time.Duration, capacityType types.DefaultTargetCapacityType, tags ...types.Tag) (*ec2.CreateFleetOutput, error) {
 	now := time.Now().UTC()
 	now = time.Date(now.Year(), now.Month(), now.Day(), now.Hour(), now.Minute(), now.Second(), 0, time.UTC)
 
@@ -24,7 +48,7 @@ func CreateSpotRequest(ctx context.Context, client clients.IEC2Client, name, ver
 		version = "$Default"
 	}
 
-	out, err := client.CreateFleet(ctx, &ec2.CreateFleetInput{
+	input := &ec2.CreateFleetInput{
 		LaunchTemplateConfigs: []types.FleetLaunchTemplateConfigRequest{
 			{
 				LaunchTemplateSpecification: &types.FleetLaunchTemplateSpecificationRequest{
@@ -33,18 +57,9 @@ func CreateSpotRequest(ctx context.Context, client clients.IEC2Client, name, ver
 				},
 				Overrides: []types.FleetLaunchTemplateOverridesRequest{
 					{
-						AvailabilityZone: aws.String(util.GetTag(template.Tags, "dev-spaces:zone")),
-						InstanceRequirements: &types.InstanceRequirementsRequest{
-							VCpuCount: &types.VCpuCountRangeRequest{
-								Min: aws.Int32(int32(cpusSpec)),
-							},
-							MemoryMiB: &types.MemoryMiBRequest{
-								Min: aws.Int32(int32(minMemory)),
-							},
-							BareMetal:            types.BareMetalIncluded,
-							BurstablePerformance: types.BurstablePerformanceIncluded,
-						},
-						MaxPrice: &maxPrice,
+						AvailabilityZone:     aws.String(util.GetTag(template.Tags, "dev-spaces:zone")),
+						InstanceRequirements: NewInstanceRequirements(cpusSpec, minMemory),
+						MaxPrice:             &maxPrice,
 					},
 				},
 			},
@@ -70,10 +85,29 @@ func CreateSpotRequest(ctx context.Context, client clients.IEC2Client, name, ver
 
 			{
 				ResourceType: types.ResourceTypeFleet,
-				Tags:         util.GenerateTags(name),
+				Tags:         append(util.GenerateTags(name), tags...),
 			},
 		},
-	})
+	}
+
+	if capacityType == types.DefaultTargetCapacityTypeOnDemand {
+		// the max price override only applies to spot capacity
+		input.LaunchTemplateConfigs[0].Overrides[0].MaxPrice = nil
+		input.TargetCapacitySpecification = &types.TargetCapacitySpecificationRequest{
+			TotalTargetCapacity:       aws.Int32(1),
+			DefaultTargetCapacityType: types.DefaultTargetCapacityTypeOnDemand,
+			OnDemandTargetCapacity:    aws.Int32(1),
+			SpotTargetCapacity:        aws.Int32(0),
+		}
+		input.SpotOptions = nil
+		// the max price is a spot price, usually below the on-demand price,
+		// so it does not cap the on-demand capacity
+		input.OnDemandOptions = &types.OnDemandOptionsRequest{
+			AllocationStrategy: types.FleetOnDemandAllocationStrategyLowestPrice,
+		}
+	}
+
+	out, err := client.CreateFleet(ctx, input)
 	if err != nil {
 		return nil, err
 	}
@@ -87,6 +121,13 @@ type PreferedLaunchSpecs struct {
 	MinCPU       int32
 }
 
+const (
+	// RoleTag is the tag with the role of the instances launched by dev-spaces
+	RoleTag = "dev-spaces:role"
+	// RoleRunner is the role of the instances running bootstrap and create tasks
+	RoleRunner = "runner"
+)
+
 type CreateSpotTaskInput struct {
 	Name                      *string `validate:"required"`
 	DeviceName                *string `validate:"required"`
@@ -125,7 +166,11 @@ func CreateSpotTaskRunner(ctx context.Context, client clients.IEC2Client, in Cre
 		TagSpecifications: []types.LaunchTemplateTagSpecificationRequest{
 			{
 				ResourceType: types.ResourceTypeInstance,
-				Tags:         util.GenerateTags(*in.Name),
+				// the role tells the runner apart from the dev space instance
+				Tags: append(util.GenerateTags(*in.Name), types.Tag{
+					Key:   aws.String(RoleTag),
+					Value: aws.String(RoleRunner),
+				}),
 			},
 			{
 				ResourceType: types.ResourceTypeVolume,
@@ -212,7 +257,6 @@ func CreateSpotTaskRunner(ctx context.Context, client clients.IEC2Client, in Cre
 	}
 	out, err := client.CreateFleet(ctx, input)
 	if err != nil {
-		fmt.Println(err.Error())
 		return nil, err
 	}
 
@@ -222,7 +266,6 @@ func CreateSpotTaskRunner(ctx context.Context, client clients.IEC2Client, in Cre
 func GetFleetStatus(ctx context.Context, client clients.IEC2Client, name string) ([]types.FleetData, error) {
 	requests, err := client.DescribeFleets(ctx, &ec2.DescribeFleetsInput{})
 	if err != nil {
-		fmt.Println(err.Error())
 		return nil, err
 	}
 
@@ -299,3 +342,214 @@ func CancelFleetRequests(ctx context.Context, client clients.IEC2Client, ids []s
 
 	return nil
 }
+
+// IsFleetInterrupted reports whether an active spot fleet request lost its
+// instance to a spot interruption, which happens when AWS reclaims the spot
+// capacity of a one-time fleet request. Instances terminated for any other
+// reason (a poweroff in the guest, a termination from the console) are not
+// interruptions
+func IsFleetInterrupted(ctx context.Context, client clients.IEC2Client, fleet types.FleetData) (bool, error) {
+	if fleet.FleetState != types.FleetStateCodeActive || fleet.CreateTime == nil {
+		return false, nil
+	}
+	if fleet.TargetCapacitySpecification != nil &&
+		fleet.TargetCapacitySpecification.DefaultTargetCapacityType == types.DefaultTargetCapacityTypeOnDemand {
+		return false, nil
+	}
+
+	instances, err := client.DescribeFleetInstances(ctx, &ec2.DescribeFleetInstancesInput{
+		FleetId: fleet.FleetId,
+	})
+	if err != nil {
+		return false, err
+	}
+	if len(instances.ActiveInstances) > 0 {
+		return false, nil
+	}
+
+	history, err := client.DescribeFleetHistory(ctx, &ec2.DescribeFleetHistoryInput{
+		FleetId:   fleet.FleetId,
+		StartTime: fleet.CreateTime,
+		EventType: types.FleetEventTypeInstanceChange,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	for _, record := range history.HistoryRecords {
+		if record.EventInformation == nil || record.EventInformation.EventSubType == nil {
+			continue
+		}
+		// AWS notifies the termination of the spot instances it reclaims
+		if *record.EventInformation.EventSubType == "termination_notified" {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// GetInterruptedFleets returns the fleet requests of the dev space (or all
+// dev spaces when name is empty) whose instance was interrupted
+func GetInterruptedFleets(ctx context.Context, client clients.IEC2Client, name string) ([]types.FleetData, error) {
+	requests, err := GetFleetStatus(ctx, client, name)
+	if err != nil {
+		return nil, err
+	}
+
+	var interrupted []types.FleetData
+	for _, request := range requests {
+		ok, err := IsFleetInterrupted(ctx, client, request)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			interrupted = append(interrupted, request)
+		}
+	}
+
+	return interrupted, nil
+}
+
+// FleetInstanceUsage is the lifetime of an instance launched by a fleet request
+type FleetInstanceUsage struct {
+	InstanceID   string
+	InstanceType string
+	Zone         string
+	Spot         bool
+	Launch       time.Time
+	// Termination is zero while the instance is running
+	Termination time.Time
+}
+
+// GetFleetInstanceUsage returns the instances launched by a fleet request with
+// their launch and termination times, from the fleet history
+func GetFleetInstanceUsage(ctx context.Context, client clients.IEC2Client, fleet types.FleetData) ([]FleetInstanceUsage, error) {
+	if fleet.CreateTime == nil {
+		return nil, nil
+	}
+
+	zone := ""
+	if len(fleet.LaunchTemplateConfigs) > 0 && len(fleet.LaunchTemplateConfigs[0].Overrides) > 0 {
+		zone = util.GetValue(fleet.LaunchTemplateConfigs[0].Overrides[0].AvailabilityZone)
+	}
+	spot := fleet.TargetCapacitySpecification == nil ||
+		fleet.TargetCapacitySpecification.DefaultTargetCapacityType != types.DefaultTargetCapacityTypeOnDemand
+
+	usages := map[string]*FleetInstanceUsage{}
+	var order []string
+	get := func(instanceID string) *FleetInstanceUsage {
+		if _, ok := usages[instanceID]; !ok {
+			usages[instanceID] = &FleetInstanceUsage{
+				InstanceID: instanceID,
+				Zone:       zone,
+				Spot:       spot,
+			}
+			order = append(order, instanceID)
+		}
+		return usages[instanceID]
+	}
+
+	input := &ec2.DescribeFleetHistoryInput{
+		FleetId:   fleet.FleetId,
+		StartTime: fleet.CreateTime,
+		EventType: types.FleetEventTypeInstanceChange,
+	}
+	for {
+		history, err := client.DescribeFleetHistory(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, record := range history.HistoryRecords {
+			info := record.EventInformation
+			if info == nil || info.InstanceId == nil || info.EventSubType == nil || record.Timestamp == nil {
+				continue
+			}
+
+			usage := get(*info.InstanceId)
+			switch *info.EventSubType {
+			case "launched":
+				usage.Launch = *record.Timestamp
+				// the launch description holds the instance details as JSON
+				var description struct {
+					InstanceType     string `json:"instanceType"`
+					AvailabilityZone string `json:"availabilityZone"`
+				}
+				if json.Unmarshal([]byte(util.GetValue(info.EventDescription)), &description) == nil {
+					usage.InstanceType = description.InstanceType
+					if description.AvailabilityZone != "" {
+						usage.Zone = description.AvailabilityZone
+					}
+				}
+			case "terminated":
+				usage.Termination = *record.Timestamp
+			}
+		}
+
+		if history.NextToken == nil {
+			break
+		}
+		input.NextToken = history.NextToken
+	}
+
+	// running instances and instances not described by the history
+	instances, err := client.DescribeFleetInstances(ctx, &ec2.DescribeFleetInstancesInput{
+		FleetId: fleet.FleetId,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, instance := range instances.ActiveInstances {
+		usage := get(util.GetValue(instance.InstanceId))
+		if usage.InstanceType == "" {
+			usage.InstanceType = util.GetValue(instance.InstanceType)
+		}
+	}
+
+	out := make([]FleetInstanceUsage, 0, len(order))
+	for _, instanceID := range order {
+		usage := usages[instanceID]
+		if usage.InstanceType == "" || usage.Launch.IsZero() {
+			instance, err := GetInstanceData(ctx, client, instanceID)
+			if err == nil && instance != nil {
+				usage.InstanceType = string(instance.InstanceType)
+				if usage.Launch.IsZero() && instance.LaunchTime != nil {
+					usage.Launch = *instance.LaunchTime
+				}
+			}
+		}
+		if usage.Launch.IsZero() {
+			usage.Launch = *fleet.CreateTime
+		}
+		out = append(out, *usage)
+	}
+
+	return out, nil
+}
+
+// GetSpotPriceHistory returns the spot prices of an instance type in a zone
+// that were in effect between from and to, from the oldest to the newest
+func GetSpotPriceHistory(ctx context.Context, client clients.IEC2Client, zone, instanceType string, from, to time.Time) ([]types.SpotPrice, error) {
+	var prices []types.SpotPrice
+	paginator := ec2.NewDescribeSpotPriceHistoryPaginator(client, &ec2.DescribeSpotPriceHistoryInput{
+		AvailabilityZone:    aws.String(zone),
+		InstanceTypes:       []types.InstanceType{types.InstanceType(instanceType)},
+		ProductDescriptions: []string{"Linux/UNIX"},
+		StartTime:           aws.Time(from),
+		EndTime:             aws.Time(to),
+	})
+	for paginator.HasMorePages() {
+		out, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		prices = append(prices, out.SpotPriceHistory...)
+	}
+
+	sort.Slice(prices, func(i, j int) bool {
+		return prices[i].Timestamp.Before(*prices[j].Timestamp)
+	})
+
+	return prices, nil
+}
@@ -0,0 +1,107 @@
+package helpers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/felipemarinho97/invest-path/clients"
+)
+
+// fakeEC2Client answers the fleet, snapshot and volume calls from memory,
+// the other calls panic
+type fakeEC2Client struct {
+	clients.IEC2Client
+	instances []types.ActiveInstance
+	history   []types.HistoryRecordEntry
+	snapshots []types.Snapshot
+	// createdVolume is the input of the last CreateVolume call
+	createdVolume *ec2.CreateVolumeInput
+}
+
+func (f *fakeEC2Client) DescribeFleetInstances(ctx context.Context, params *ec2.DescribeFleetInstancesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeFleetInstancesOutput, error) {
+	return &ec2.DescribeFleetInstancesOutput{ActiveInstances: f.instances}, nil
+}
+
+func (f *fakeEC2Client) DescribeFleetHistory(ctx context.Context, params *ec2.DescribeFleetHistoryInput, optFns ...func(*ec2.Options)) (*ec2.DescribeFleetHistoryOutput, error) {
+	return &ec2.DescribeFleetHistoryOutput{HistoryRecords: f.history}, nil
+}
+
+func instanceChange(subType string) types.HistoryRecordEntry {
+	return types.HistoryRecordEntry{
+		EventType: types.FleetEventTypeInstanceChange,
+		EventInformation: &types.EventInformation{
+			EventSubType: aws.String(subType),
+			InstanceId:   aws.String("i-1"),
+		},
+	}
+}
+
+func TestIsFleetInterrupted(t *testing.T) {
+	fleet := func(state types.FleetStateCode, capacityType types.DefaultTargetCapacityType) types.FleetData {
+		return types.FleetData{
+			FleetId:    aws.String("fleet-1"),
+			FleetState: state,
+			CreateTime: aws.Time(time.Now().Add(-time.Hour)),
+			TargetCapacitySpecification: &types.TargetCapacitySpecification{
+				DefaultTargetCapacityType: capacityType,
+			},
+		}
+	}
+
+	tests := []struct {
+		name      string
+		fleet     types.FleetData
+		instances []types.ActiveInstance
+		history   []types.HistoryRecordEntry
+		want      bool
+	}{
+		{
+			name:    "spot interruption",
+			fleet:   fleet(types.FleetStateCodeActive, types.DefaultTargetCapacityTypeSpot),
+			history: []types.HistoryRecordEntry{instanceChange("launched"), instanceChange("termination_notified"), instanceChange("terminated")},
+			want:    true,
+		},
+		{
+			name:    "instance terminated by the user",
+			fleet:   fleet(types.FleetStateCodeActive, types.DefaultTargetCapacityTypeSpot),
+			history: []types.HistoryRecordEntry{instanceChange("launched"), instanceChange("terminated")},
+			want:    false,
+		},
+		{
+			name:    "on-demand fleet",
+			fleet:   fleet(types.FleetStateCodeActive, types.DefaultTargetCapacityTypeOnDemand),
+			history: []types.HistoryRecordEntry{instanceChange("launched"), instanceChange("termination_notified")},
+			want:    false,
+		},
+		{
+			name:      "instance still running",
+			fleet:     fleet(types.FleetStateCodeActive, types.DefaultTargetCapacityTypeSpot),
+			instances: []types.ActiveInstance{{InstanceId: aws.String("i-2")}},
+			history:   []types.HistoryRecordEntry{instanceChange("termination_notified")},
+			want:      false,
+		},
+		{
+			name:    "cancelled fleet",
+			fleet:   fleet(types.FleetStateCodeDeleted, types.DefaultTargetCapacityTypeSpot),
+			history: []types.HistoryRecordEntry{instanceChange("termination_notified")},
+			want:    false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &fakeEC2Client{instances: tt.instances, history: tt.history}
+
+			got, err := IsFleetInterrupted(context.Background(), client, tt.fleet)
+			if err != nil {
+				t.Fatalf("IsFleetInterrupted() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("IsFleetInterrupted() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,310 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/felipemarinho97/dev-spaces/core/helpers"
+	"github.com/felipemarinho97/dev-spaces/core/util"
+)
+
+// idleProbeScript prints the active SSH sessions (minus the probe itself), the
+// SSH sessions with a pty, the 1-minute load average, the current time, the
+// last input time on any pty of the host or the devspace container and the
+// boot time of the host. sshd names the process of each session after its
+// terminal, user@pts/N or user@notty
+const idleProbeScript = `sudo bash -c '
+sessions=$(ss -Htn state established "( sport = :22 or sport = :2222 )" | wc -l)
+ptys=$(pgrep -cf "^sshd(-session)?: [^ ]+@pts/")
+load=$(cut -d" " -f1 /proc/loadavg)
+now=$(date +%s)
+boot=$(( now - $(cut -d. -f1 /proc/uptime) ))
+last=$(stat -c %X /dev/pts/[0-9]* 2>/dev/null | sort -n | tail -1)
+leader=$(machinectl show devspace -p Leader --value 2>/dev/null)
+if [ -n "$leader" ]; then
+	clast=$(nsenter -t $leader -m sh -c "stat -c %X /dev/pts/[0-9]* 2>/dev/null" | sort -n | tail -1)
+	if [ "${clast:-0}" -gt "${last:-0}" ]; then last=$clast; fi
+fi
+echo $(( sessions - 1 )) ${ptys:-0} $load $now ${last:-0} $boot
+'`
+
+const (
+	idleTimeoutTag = "dev-spaces:idle-timeout"
+	idleMaxLoadTag = "dev-spaces:idle-max-load"
+
+	// DefaultIdleMaxLoad is used when an idle policy does not set a max load
+	DefaultIdleMaxLoad = 0.1
+)
+
+type IdlePolicy struct {
+	// Timeout is how long the dev space must be idle before it is stopped (0 disables)
+	Timeout time.Duration `json:"timeout" yaml:"timeout"`
+	// MaxLoad is the 1-minute load average under which the host is considered idle
+	MaxLoad float64 `json:"max_load" yaml:"max_load"`
+}
+
+type SetIdlePolicyOptions struct {
+	// Name of the dev space
+	Name string `validate:"required"`
+	// Policy is the idle policy to set, nil removes the dev space policy
+	Policy *IdlePolicy
+}
+
+type IdleCheckOptions struct {
+	// Name of the dev space (empty means all dev spaces)
+	Name string
+	// SSHKey is the path of the SSH key
+	SSHKey string `validate:"required"`
+	// DefaultPolicy is used for dev spaces without their own idle policy
+	DefaultPolicy IdlePolicy
+}
+
+type IdleCheckItem struct {
+	// Name of the dev space
+	Name string `json:"name" yaml:"name"`
+	// InstanceID is the instance that was checked
+	InstanceID string `json:"instance_id" yaml:"instance_id"`
+	// Sessions is the number of active SSH sessions
+	Sessions int `json:"sessions" yaml:"sessions"`
+	// Load is the 1-minute load average of the host
+	Load float64 `json:"load" yaml:"load"`
+	// IdleFor is the time since the last input on the dev space
+	IdleFor time.Duration `json:"idle_for" yaml:"idle_for"`
+	// Policy is the idle policy applied
+	Policy IdlePolicy `json:"policy" yaml:"policy"`
+	// Stopped reports if the dev space was stopped
+	Stopped bool `json:"stopped" yaml:"stopped"`
+	// Reason is the reason the dev space was stopped
+	Reason string `json:"reason" yaml:"reason"`
+}
+
+type idleProbe struct {
+	sessions  int
+	ptys      int
+	load      float64
+	now       time.Time
+	lastInput time.Time
+	boot      time.Time
+}
+
+// SetIdlePolicy stores the idle policy of a dev space as tags on its launch template
+func (h *Handler) SetIdlePolicy(ctx context.Context, opts SetIdlePolicyOptions) error {
+	err := util.Validator.Struct(opts)
+	if err != nil {
+		return err
+	}
+
+	client := h.EC2Client
+	name, _ := util.GetTemplateNameAndVersion(opts.Name)
+	template, err := helpers.GetLaunchTemplateByName(ctx, client, name)
+	if err != nil {
+		return err
+	}
+
+	if opts.Policy == nil {
+		return helpers.RemoveLaunchTemplateTags(ctx, client, *template.LaunchTemplateId, idleTimeoutTag, idleMaxLoadTag)
+	}
+
+	return helpers.SetLaunchTemplateTags(ctx, client, *template.LaunchTemplateId, map[string]string{
+		idleTimeoutTag: opts.Policy.Timeout.String(),
+		idleMaxLoadTag: strconv.FormatFloat(opts.Policy.MaxLoad, 'f', -1, 64),
+	})
+}
+
+// IdleCheck checks the activity of the running dev spaces over SSH and stops
+// the ones that have been idle for longer than their idle policy allows
+func (h *Handler) IdleCheck(ctx context.Context, opts IdleCheckOptions) ([]IdleCheckItem, error) {
+	err := util.Validator.Struct(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	identityKey, err := util.RetrieveFile(opts.SSHKey)
+	if err != nil {
+		return nil, err
+	}
+
+	client := h.EC2Client
+	log := h.Logger
+
+	launchTemplates, err := helpers.GetLaunchTemplates(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+
+	managedInstances, err := helpers.GetManagedInstances(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+
+	filter, _ := util.GetTemplateNameAndVersion(opts.Name)
+	var items []IdleCheckItem
+	for _, template := range launchTemplates.LaunchTemplates {
+		name := *template.LaunchTemplateName
+		if filter != "" && name != filter {
+			continue
+		}
+
+		instance := managedInstances[name]
+		if instance == nil || instance.State.Name != types.InstanceStateNameRunning || instance.PublicIpAddress == nil {
+			continue
+		}
+
+		policy := getIdlePolicy(template.Tags, opts.DefaultPolicy)
+		if policy.Timeout <= 0 {
+			continue
+		}
+
+		item, err := h.checkIdle(ctx, name, *instance, identityKey, policy)
+		if err != nil {
+			log.Warn(fmt.Sprintf("Error checking activity of %s: %s", name, err))
+			continue
+		}
+
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+func (h *Handler) checkIdle(ctx context.Context, name string, instance types.Instance, identityKey string, policy IdlePolicy) (_ IdleCheckItem, err error) {
+	log := h.Logger
+	t := h.track("idle-check", name)
+	defer func() { t.Done(err) }()
+
+	sshClient, err := connectHost(*instance.PublicIpAddress, identityKey)
+	if err != nil {
+		return IdleCheckItem{}, err
+	}
+	defer sshClient.Close()
+
+	out, err := sshClient.Run(idleProbeScript, 30*time.Second)
+	if err != nil {
+		return IdleCheckItem{}, err
+	}
+
+	probe, err := parseIdleProbe(out)
+	if err != nil {
+		return IdleCheckItem{}, err
+	}
+
+	item := IdleCheckItem{
+		Name:       name,
+		InstanceID: *instance.InstanceId,
+		Sessions:   probe.sessions,
+		Load:       probe.load,
+		IdleFor:    probe.idleFor(),
+		Policy:     policy,
+	}
+	log.Debug(fmt.Sprintf("%s: sessions=%d load=%.2f idle=%s", name, item.Sessions, item.Load, item.IdleFor))
+
+	if !probe.isIdle(policy) {
+		return item, nil
+	}
+
+	item.Reason = fmt.Sprintf("idle for %s (sessions=%d, load=%.2f)", item.IdleFor, item.Sessions, item.Load)
+	log.Info(fmt.Sprintf("Stopping %s: %s", name, item.Reason))
+
+	// power off the devspace container before terminating the instance
+	t.Phase("power-off", "Powering off devspace...")
+	_, err = sshClient.Run("sudo machinectl poweroff devspace && while sudo machinectl status devspace >/dev/null 2>&1; do sleep 1; done", 60*time.Second)
+	if err != nil {
+		t.Warn("Error powering off devspace", err)
+	}
+
+	_, err = h.Stop(ctx, StopOptions{
+		Name:   name,
+		Reason: item.Reason,
+	})
+	if err != nil {
+		return item, err
+	}
+	item.Stopped = true
+
+	return item, nil
+}
+
+func getIdlePolicy(tags []types.Tag, defaultPolicy IdlePolicy) IdlePolicy {
+	policy := defaultPolicy
+
+	if timeout, err := time.ParseDuration(util.GetTag(tags, idleTimeoutTag)); err == nil {
+		policy.Timeout = timeout
+	}
+	if maxLoad, err := strconv.ParseFloat(util.GetTag(tags, idleMaxLoadTag), 64); err == nil {
+		policy.MaxLoad = maxLoad
+	}
+	if policy.MaxLoad <= 0 {
+		policy.MaxLoad = DefaultIdleMaxLoad
+	}
+
+	return policy
+}
+
+func parseIdleProbe(out string) (idleProbe, error) {
+	fields := strings.Fields(out)
+	if len(fields) != 6 {
+		return idleProbe{}, fmt.Errorf("unexpected idle probe output: %q", out)
+	}
+
+	sessions, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return idleProbe{}, err
+	}
+	ptys, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return idleProbe{}, err
+	}
+	load, err := strconv.ParseFloat(fields[2], 64)
+	if err != nil {
+		return idleProbe{}, err
+	}
+
+	var times [3]time.Time
+	for i, field := range fields[3:] {
+		seconds, err := strconv.ParseInt(field, 10, 64)
+		if err != nil {
+			return idleProbe{}, err
+		}
+		if seconds > 0 {
+			times[i] = time.Unix(seconds, 0)
+		}
+	}
+
+	if sessions < 0 {
+		sessions = 0
+	}
+
+	return idleProbe{
+		sessions:  sessions,
+		ptys:      ptys,
+		load:      load,
+		now:       times[0],
+		lastInput: times[1],
+		boot:      times[2],
+	}, nil
+}
+
+// idleFor returns the time since the last input, or since boot if there was none
+func (p idleProbe) idleFor() time.Duration {
+	since := p.boot
+	if p.lastInput.After(since) {
+		since = p.lastInput
+	}
+
+	return p.now.Sub(since)
+}
+
+// isIdle reports whether the policy allows stopping the dev space. The pty
+// input only tells the activity of the pty sessions, so any session without a
+// pty (e.g. editors, port forwards or file transfers) keeps it active
+func (p idleProbe) isIdle(policy IdlePolicy) bool {
+	if p.sessions > p.ptys {
+		return false
+	}
+
+	return p.idleFor() >= policy.Timeout && p.load < policy.MaxLoad
+}
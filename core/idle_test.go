@@ -0,0 +1,163 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+func TestGetIdlePolicy(t *testing.T) {
+	defaultPolicy := IdlePolicy{Timeout: time.Hour, MaxLoad: 0.5}
+	tag := func(key, value string) types.Tag {
+		return types.Tag{Key: aws.String(key), Value: aws.String(value)}
+	}
+
+	tests := []struct {
+		name string
+		tags []types.Tag
+		def  IdlePolicy
+		want IdlePolicy
+	}{
+		{
+			name: "uses the default policy without tags",
+			def:  defaultPolicy,
+			want: defaultPolicy,
+		},
+		{
+			name: "uses the policy of the tags",
+			tags: []types.Tag{tag(idleTimeoutTag, "30m0s"), tag(idleMaxLoadTag, "0.2")},
+			def:  defaultPolicy,
+			want: IdlePolicy{Timeout: 30 * time.Minute, MaxLoad: 0.2},
+		},
+		{
+			name: "ignores invalid tags",
+			tags: []types.Tag{tag(idleTimeoutTag, "soon"), tag(idleMaxLoadTag, "low")},
+			def:  defaultPolicy,
+			want: defaultPolicy,
+		},
+		{
+			name: "defaults the max load",
+			tags: []types.Tag{tag(idleTimeoutTag, "15m")},
+			want: IdlePolicy{Timeout: 15 * time.Minute, MaxLoad: DefaultIdleMaxLoad},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getIdlePolicy(tt.tags, tt.def); got != tt.want {
+				t.Errorf("getIdlePolicy() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseIdleProbe(t *testing.T) {
+	tests := []struct {
+		name    string
+		out     string
+		want    idleProbe
+		wantErr bool
+	}{
+		{
+			name: "parses the probe output",
+			out:  "2 1 0.15 1700000600 1700000300 1700000000\n",
+			want: idleProbe{
+				sessions:  2,
+				ptys:      1,
+				load:      0.15,
+				now:       time.Unix(1700000600, 0),
+				lastInput: time.Unix(1700000300, 0),
+				boot:      time.Unix(1700000000, 0),
+			},
+		},
+		{
+			name: "without input on any pty",
+			out:  "0 0 0.00 1700000600 0 1700000000\n",
+			want: idleProbe{
+				now:  time.Unix(1700000600, 0),
+				boot: time.Unix(1700000000, 0),
+			},
+		},
+		{
+			name: "does not count the probe twice",
+			out:  "-1 0 0.00 1700000600 0 1700000000\n",
+			want: idleProbe{
+				now:  time.Unix(1700000600, 0),
+				boot: time.Unix(1700000000, 0),
+			},
+		},
+		{
+			name:    "fails on a missing field",
+			out:     "1 0.15 1700000600 1700000300 1700000000\n",
+			wantErr: true,
+		},
+		{
+			name:    "fails on an invalid load",
+			out:     "1 1 high 1700000600 1700000300 1700000000\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseIdleProbe(tt.out)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseIdleProbe() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseIdleProbe() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIdleProbe_isIdle(t *testing.T) {
+	policy := IdlePolicy{Timeout: 30 * time.Minute, MaxLoad: 0.1}
+	now := time.Unix(1700010000, 0)
+	boot := now.Add(-2 * time.Hour)
+
+	tests := []struct {
+		name  string
+		probe idleProbe
+		want  bool
+	}{
+		{
+			name:  "no sessions since boot",
+			probe: idleProbe{now: now, boot: boot},
+			want:  true,
+		},
+		{
+			name:  "old pty session",
+			probe: idleProbe{sessions: 1, ptys: 1, now: now, lastInput: now.Add(-time.Hour), boot: boot},
+			want:  true,
+		},
+		{
+			name:  "recent input on a pty",
+			probe: idleProbe{sessions: 1, ptys: 1, now: now, lastInput: now.Add(-time.Minute), boot: boot},
+			want:  false,
+		},
+		{
+			name:  "session without a pty next to an old pty session",
+			probe: idleProbe{sessions: 2, ptys: 1, now: now, lastInput: now.Add(-time.Hour), boot: boot},
+			want:  false,
+		},
+		{
+			name:  "session without a pty",
+			probe: idleProbe{sessions: 1, now: now, boot: boot},
+			want:  false,
+		},
+		{
+			name:  "busy host",
+			probe: idleProbe{load: 1.5, now: now, boot: boot},
+			want:  false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.probe.isIdle(policy); got != tt.want {
+				t.Errorf("idleProbe.isIdle() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -8,29 +8,38 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/felipemarinho97/dev-spaces/core/helpers"
+	"github.com/felipemarinho97/dev-spaces/core/util"
 )
 
 type OutputFormat string
 
 const (
+	OutputFormatTable OutputFormat = "table"
+	OutputFormatJSON  OutputFormat = "json"
+	OutputFormatYAML  OutputFormat = "yaml"
+	// OutputFormatWide and OutputFormatShort are the table formats of list
 	OutputFormatWide  OutputFormat = "wide"
 	OutputFormatShort OutputFormat = "short"
+	// OutputFormatCSV is the export format of cost
+	OutputFormatCSV OutputFormat = "csv"
 )
 
 type ListOptions struct{}
 
 type ListItem struct {
-	Name             string
-	Version          int64
-	LaunchTemplateID string
-	CreateTime       string
-	InstanceID       string
-	InstanceType     string
-	InstanceState    string
-	PublicDNS        string
-	PublicIP         string
-	KeyName          string
-	Zone             string
+	Name             string `json:"name" yaml:"name"`
+	Version          int64  `json:"version" yaml:"version"`
+	LaunchTemplateID string `json:"launch_template_id" yaml:"launch_template_id"`
+	CreateTime       string `json:"create_time" yaml:"create_time"`
+	InstanceID       string `json:"instance_id" yaml:"instance_id"`
+	InstanceType     string `json:"instance_type" yaml:"instance_type"`
+	InstanceState    string `json:"instance_state" yaml:"instance_state"`
+	PublicDNS        string `json:"public_dns" yaml:"public_dns"`
+	PublicIP         string `json:"public_ip" yaml:"public_ip"`
+	KeyName          string `json:"key_name" yaml:"key_name"`
+	Zone             string `json:"zone" yaml:"zone"`
+	// SnapshotID is set when the dev space is archived
+	SnapshotID string `json:"snapshot_id" yaml:"snapshot_id"`
 }
 
 func (h *Handler) ListSpaces(ctx context.Context, opts ListOptions) ([]ListItem, error) {
@@ -74,6 +83,7 @@ func toListItems(launchTemplates []types.LaunchTemplate, managedInstances map[st
 			Version:          *launchTemplate.DefaultVersionNumber,
 			LaunchTemplateID: *launchTemplate.LaunchTemplateId,
 			CreateTime:       *aws.String(launchTemplate.CreateTime.Format("2006-01-02 15:04:05")),
+			SnapshotID:       util.GetTag(launchTemplate.Tags, snapshotIDTag),
 		}
 
 		if instance != nil {
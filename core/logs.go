@@ -0,0 +1,234 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/felipemarinho97/dev-spaces/core/helpers"
+	"github.com/felipemarinho97/dev-spaces/core/util"
+	"github.com/felipemarinho97/dev-spaces/core/util/ssh"
+)
+
+const (
+	// UserDataLog is the log the bootstrap and startup scripts tee their output to
+	UserDataLog = "/var/log/user-data.log"
+
+	// the scripts send the user-data log to the console through logger, which
+	// prefixes each line with its tag
+	userDataTag = "user-data: "
+
+	logsPollInterval = 5 * time.Second
+)
+
+type LogsOptions struct {
+	// Name of the dev space
+	Name string `validate:"required"`
+	// Bootstrap streams the log of the bootstrap task runner instead of the dev space host
+	Bootstrap bool
+	// Follow keeps streaming the log until the instance stops or ctx is done
+	Follow bool
+	// SSHKey is the path of the SSH key, without it the log is only read from
+	// the console output (optional)
+	SSHKey string
+	// Output receives the log
+	Output io.Writer `validate:"required"`
+}
+
+// Logs streams the user-data log of the instance of a dev space, or of its
+// bootstrap task runner. The log is read from the console output of the
+// instance until SSH is reachable, and over SSH afterwards
+func (h *Handler) Logs(ctx context.Context, opts LogsOptions) error {
+	client := h.EC2Client
+
+	err := util.Validator.Struct(opts)
+	if err != nil {
+		return err
+	}
+
+	var identityKey string
+	if opts.SSHKey != "" {
+		identityKey, err = util.RetrieveFile(opts.SSHKey)
+		if err != nil {
+			return err
+		}
+	}
+
+	name, _ := util.GetTemplateNameAndVersion(opts.Name)
+	instance, err := helpers.GetLatestInstance(ctx, client, name, opts.Bootstrap)
+	if err != nil {
+		return err
+	}
+	if instance == nil && opts.Bootstrap {
+		return fmt.Errorf("no bootstrap task found for %s", name)
+	} else if instance == nil {
+		return fmt.Errorf("dev space %s has no instance", name)
+	}
+
+	stream := &logStream{w: opts.Output}
+	for {
+		if identityKey != "" && isReachable(instance, 22) {
+			err = tailLog(ctx, *instance.PublicIpAddress, identityKey, stream, opts.Follow)
+			// following the log ends when it is interrupted
+			if err == nil || ctx.Err() == context.Canceled {
+				return nil
+			}
+			if ctx.Err() != nil {
+				return err
+			}
+			// the SSH connection is lost when the instance shuts down, what is
+			// left of the log is read from the console output
+			h.Logger.Debug(fmt.Sprintf("Error reading the log over SSH: %s", err))
+		}
+
+		output, err := helpers.GetConsoleOutput(ctx, client, *instance.InstanceId)
+		if err != nil {
+			return err
+		}
+		err = stream.writeConsole(output)
+		if err != nil {
+			return err
+		}
+
+		if !opts.Follow || !isActive(instance) {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			if ctx.Err() == context.Canceled {
+				return nil
+			}
+			return ctx.Err()
+		case <-time.After(logsPollInterval):
+		}
+
+		instance, err = helpers.GetInstanceData(ctx, client, *instance.InstanceId)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// tailLog streams the user-data log over SSH, skipping the lines already written
+func tailLog(ctx context.Context, ip, identityKey string, stream *logStream, follow bool) error {
+	sshClient, err := connectHost(ip, identityKey)
+	if err != nil {
+		return err
+	}
+	defer sshClient.Close()
+
+	cmd := fmt.Sprintf("tail -n +%d", stream.lines+1)
+	if follow {
+		cmd += " -F"
+	}
+	cmd += " " + UserDataLog
+	// the log is only readable by root
+	cmd = fmt.Sprintf(`if [ "$(id -u)" -eq 0 ]; then %s; else sudo %s; fi`, cmd, cmd)
+
+	status, err := sshClient.Exec(ctx, cmd, ssh.ExecOptions{
+		Stdout: stream,
+	})
+	if err != nil {
+		return err
+	}
+	if status != 0 {
+		return fmt.Errorf("tail exited with status %d", status)
+	}
+
+	return nil
+}
+
+// logStream writes the log once, whether it is read from the console output
+// or over SSH
+type logStream struct {
+	w io.Writer
+	// lines is the number of lines written
+	lines int
+	// last is the last line written
+	last string
+	// partial is a line written over SSH without its newline yet
+	partial string
+	// consoleLines is the number of lines of the last console output written
+	consoleLines int
+}
+
+func (s *logStream) Write(p []byte) (int, error) {
+	n, err := s.w.Write(p)
+
+	lines := strings.Split(s.partial+string(p[:n]), "\n")
+	s.lines += len(lines) - 1
+	if len(lines) > 1 {
+		s.last = lines[len(lines)-2]
+	}
+	s.partial = lines[len(lines)-1]
+
+	return n, err
+}
+
+// writeConsole writes the lines of the console output that were not written yet
+func (s *logStream) writeConsole(output string) error {
+	lines := userDataLines(output)
+
+	start := s.consoleLines
+	if start > len(lines) || (start > 0 && lines[start-1] != s.last) || (start == 0 && s.lines > 0) {
+		// the console output only keeps its latest 64 KB, and the log may
+		// have been read over SSH, so resume after the last line written
+		start = len(lines)
+		for i := len(lines) - 1; i >= 0; i-- {
+			if lines[i] == s.last {
+				start = i + 1
+				break
+			}
+		}
+	}
+	s.consoleLines = len(lines)
+
+	for _, line := range lines[start:] {
+		_, err := fmt.Fprintln(s, line)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// userDataLines returns the lines of the user-data log in a console output
+func userDataLines(output string) []string {
+	var lines []string
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if i := strings.Index(line, userDataTag); i >= 0 {
+			lines = append(lines, line[i+len(userDataTag):])
+		} else if strings.HasPrefix(line, bootstrapStatusPrefix) {
+			lines = append(lines, line)
+		}
+	}
+
+	return lines
+}
+
+// isActive reports whether the instance is booting or running
+func isActive(instance *types.Instance) bool {
+	return instance.State.Name == types.InstanceStateNamePending || instance.State.Name == types.InstanceStateNameRunning
+}
+
+// isReachable reports whether the port of a running instance accepts connections
+func isReachable(instance *types.Instance, port int) bool {
+	if instance.State.Name != types.InstanceStateNameRunning || instance.PublicIpAddress == nil {
+		return false
+	}
+
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(*instance.PublicIpAddress, fmt.Sprint(port)), 2*time.Second)
+	if err != nil {
+		return false
+	}
+	conn.Close()
+
+	return true
+}
@@ -0,0 +1,172 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/felipemarinho97/dev-spaces/core/helpers"
+	"github.com/felipemarinho97/dev-spaces/core/util"
+)
+
+type PriceOptions struct {
+	// Name of the dev space
+	Name string `validate:"required"`
+	// MinMemory is the amount of memory in MiB
+	MinMemory int `validate:"min=0"`
+	// MinCPUs is the amount of cpus
+	MinCPUs int `validate:"min=0"`
+	// MaxPrice is the maximum price for the instance (optional)
+	MaxPrice string
+	// Limit is the maximum number of instance types returned (0 returns all)
+	Limit int `validate:"min=0"`
+	// OnDemand fetches the on-demand price of the returned instance types
+	OnDemand bool
+}
+
+type PriceItem struct {
+	// InstanceType is the type of the instance
+	InstanceType string `json:"instance_type" yaml:"instance_type"`
+	// VCPUs is the number of vCPUs of the instance type
+	VCPUs int32 `json:"vcpus" yaml:"vcpus"`
+	// MemoryMiB is the memory of the instance type
+	MemoryMiB int64 `json:"memory_mib" yaml:"memory_mib"`
+	// SpotPrice is the current hourly spot price in the dev space zone
+	SpotPrice float64 `json:"spot_price" yaml:"spot_price"`
+	// OnDemandPrice is the hourly on-demand price in the region, 0 if unknown
+	OnDemandPrice float64 `json:"on_demand_price" yaml:"on_demand_price"`
+	// Savings is the spot discount over the on-demand price (0-1), 0 if unknown
+	Savings float64 `json:"savings" yaml:"savings"`
+	// WithinMaxPrice is true when the spot price is under the max price
+	WithinMaxPrice bool `json:"within_max_price" yaml:"within_max_price"`
+}
+
+type PriceOutput struct {
+	// Zone of the dev space
+	Zone string `json:"zone" yaml:"zone"`
+	// MaxPrice is the max price the items were compared with
+	MaxPrice float64 `json:"max_price" yaml:"max_price"`
+	// Fulfillable is true when at least one instance type is within the max price
+	Fulfillable bool `json:"fulfillable" yaml:"fulfillable"`
+	// Items are the matching instance types, from the cheapest spot price
+	Items []PriceItem `json:"items" yaml:"items"`
+}
+
+// Price lists the instance types that would be requested by Start for the
+// given requirements, ranked by their current spot price in the dev space zone
+func (h *Handler) Price(ctx context.Context, opts PriceOptions) (PriceOutput, error) {
+	log := h.Logger
+	err := util.Validator.Struct(opts)
+	if err != nil {
+		return PriceOutput{}, err
+	}
+
+	var maxPrice float64
+	if opts.MaxPrice != "" {
+		maxPrice, err = strconv.ParseFloat(opts.MaxPrice, 64)
+		if err != nil {
+			return PriceOutput{}, fmt.Errorf("invalid max price: %s", opts.MaxPrice)
+		}
+	}
+
+	client := h.EC2Client
+	name, _ := util.GetTemplateNameAndVersion(opts.Name)
+	template, err := helpers.GetLaunchTemplateByName(ctx, client, name)
+	if err != nil {
+		return PriceOutput{}, err
+	}
+	zone := util.GetTag(template.Tags, "dev-spaces:zone")
+
+	instanceTypes, err := h.candidateInstanceTypes(ctx, template, opts.MinCPUs, opts.MinMemory)
+	if err != nil {
+		return PriceOutput{}, err
+	}
+
+	spotPrices, err := helpers.GetSpotPrices(ctx, client, zone, instanceTypes)
+	if err != nil {
+		return PriceOutput{}, err
+	}
+
+	infos, err := helpers.GetInstanceTypesInfo(ctx, client, instanceTypes)
+	if err != nil {
+		return PriceOutput{}, err
+	}
+
+	out := PriceOutput{
+		Zone:     zone,
+		MaxPrice: maxPrice,
+	}
+	for instanceType, spotPrice := range spotPrices {
+		item := PriceItem{
+			InstanceType:   instanceType,
+			SpotPrice:      spotPrice,
+			WithinMaxPrice: maxPrice == 0 || spotPrice <= maxPrice,
+		}
+		if info, ok := infos[instanceType]; ok {
+			if info.VCpuInfo != nil {
+				item.VCPUs = aws.Int32Value(info.VCpuInfo.DefaultVCpus)
+			}
+			if info.MemoryInfo != nil {
+				item.MemoryMiB = aws.Int64Value(info.MemoryInfo.SizeInMiB)
+			}
+		}
+		out.Fulfillable = out.Fulfillable || item.WithinMaxPrice
+		out.Items = append(out.Items, item)
+	}
+
+	sort.Slice(out.Items, func(i, j int) bool {
+		if out.Items[i].SpotPrice == out.Items[j].SpotPrice {
+			return out.Items[i].InstanceType < out.Items[j].InstanceType
+		}
+		return out.Items[i].SpotPrice < out.Items[j].SpotPrice
+	})
+	if opts.Limit > 0 && len(out.Items) > opts.Limit {
+		out.Items = out.Items[:opts.Limit]
+	}
+
+	if opts.OnDemand {
+		for i := range out.Items {
+			item := &out.Items[i]
+			item.OnDemandPrice, err = helpers.GetOnDemandPrice(ctx, h.PricingClient, h.Config.DefaultRegion, item.InstanceType)
+			if err != nil {
+				log.Warn(fmt.Sprintf("Error getting on-demand price of %s: %s", item.InstanceType, err))
+				continue
+			}
+			if item.OnDemandPrice > 0 {
+				item.Savings = 1 - item.SpotPrice/item.OnDemandPrice
+			}
+		}
+	}
+
+	return out, nil
+}
+
+// candidateInstanceTypes returns the instance types a fleet request of the dev
+// space could launch with the given requirements, restricted by the
+// architecture of its host AMI
+func (h *Handler) candidateInstanceTypes(ctx context.Context, template *types.LaunchTemplate, minCPUs, minMemory int) ([]string, error) {
+	client := h.EC2Client
+	defaultVersion, err := helpers.GetDefaultLaunchTemplateVersion(ctx, client, *template.LaunchTemplateId)
+	if err != nil {
+		return nil, err
+	}
+	image, err := helpers.GetImage(ctx, client, *defaultVersion.LaunchTemplateData.ImageId)
+	if err != nil {
+		return nil, err
+	}
+
+	h.Logger.Debug(fmt.Sprintf("Finding %s instance types with %d vCPUs and %d MiB...", image.Architecture, minCPUs, minMemory))
+	instanceTypes, err := helpers.GetInstanceTypesFromRequirements(ctx, client, minCPUs, minMemory, types.ArchitectureType(image.Architecture))
+	if err != nil {
+		return nil, err
+	}
+	if len(instanceTypes) == 0 {
+		return nil, errors.New("no instance types match the requirements")
+	}
+
+	return instanceTypes, nil
+}
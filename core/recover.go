@@ -0,0 +1,141 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/felipemarinho97/dev-spaces/core/helpers"
+	"github.com/felipemarinho97/dev-spaces/core/util"
+)
+
+type RecoverOptions struct {
+	// Name of the dev space (empty means all dev spaces)
+	Name string
+}
+
+type RecoverOutput struct {
+	// Name of the recovered dev space
+	Name string `json:"name" yaml:"name"`
+	// InterruptedFleetID is the ID of the fleet request that was interrupted
+	InterruptedFleetID string `json:"interrupted_fleet_id" yaml:"interrupted_fleet_id"`
+	// Start is the result of the relaunch
+	Start StartOutput `json:"start" yaml:"start"`
+}
+
+// Recover looks for dev spaces whose spot instance was interrupted and
+// relaunches them with the same specs as the interrupted fleet request
+func (h *Handler) Recover(ctx context.Context, opts RecoverOptions) ([]RecoverOutput, error) {
+	client := h.EC2Client
+	log := h.Logger
+
+	fleets, err := helpers.GetInterruptedFleets(ctx, client, opts.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	var recovered []RecoverOutput
+	for _, fleet := range fleets {
+		name := util.GetTag(fleet.Tags, "dev-spaces:name")
+		fleetID := *fleet.FleetId
+		log.Info(fmt.Sprintf("Spot interruption detected for %s (fleet %s)", name, fleetID))
+
+		startOptions, err := startOptionsFromFleet(name, fleet)
+		if err != nil {
+			log.Warn(fmt.Sprintf("Unable to recover %s: %s", name, err))
+			continue
+		}
+
+		// the interrupted request is kept until the relaunch succeeds, so a
+		// failed relaunch is retried on the next call
+		out, err := h.Start(ctx, startOptions)
+		if err != nil {
+			log.Error(fmt.Sprintf("Error relaunching %s: %s", name, err))
+			continue
+		}
+		log.Info(fmt.Sprintf("Recovered %s on instance %s", name, out.InstanceID))
+
+		// cancel the interrupted request so it is not recovered twice
+		err = helpers.CancelFleetRequests(ctx, client, []string{fleetID})
+		if err != nil {
+			log.Error(fmt.Sprintf("Error cancelling fleet %s: %s", fleetID, err))
+		}
+
+		recovered = append(recovered, RecoverOutput{
+			Name:               name,
+			InterruptedFleetID: fleetID,
+			Start:              out,
+		})
+	}
+
+	return recovered, nil
+}
+
+// startOptionsFromFleet rebuilds the start options used to place a fleet request
+func startOptionsFromFleet(name string, fleet types.FleetData) (StartOptions, error) {
+	if len(fleet.LaunchTemplateConfigs) == 0 || len(fleet.LaunchTemplateConfigs[0].Overrides) == 0 {
+		return StartOptions{}, fmt.Errorf("fleet %s has no launch specs", *fleet.FleetId)
+	}
+
+	// the relaunch gets the same time to be fulfilled as the original request
+	timeout := time.Hour
+	if fleet.ValidUntil != nil {
+		from := fleet.ValidFrom
+		if from == nil {
+			from = fleet.CreateTime
+		}
+		if from != nil && fleet.ValidUntil.After(*from) {
+			timeout = fleet.ValidUntil.Sub(*from)
+		}
+	}
+
+	config := fleet.LaunchTemplateConfigs[0]
+	overrides := config.Overrides[0]
+	opts := StartOptions{
+		Name:         name,
+		MaxPrice:     util.GetValue(overrides.MaxPrice),
+		Timeout:      timeout,
+		CapacityMode: CapacityModeSpot,
+	}
+
+	if config.LaunchTemplateSpecification != nil {
+		version := util.GetValue(config.LaunchTemplateSpecification.Version)
+		if version != "" && version != "$Default" {
+			opts.Name = fmt.Sprintf("%s/%s", name, version)
+		}
+	}
+
+	if requirements := overrides.InstanceRequirements; requirements != nil {
+		if requirements.VCpuCount != nil && requirements.VCpuCount.Min != nil {
+			opts.MinCPUs = int(*requirements.VCpuCount.Min)
+		}
+		if requirements.MemoryMiB != nil && requirements.MemoryMiB.Min != nil {
+			opts.MinMemory = int(*requirements.MemoryMiB.Min)
+		}
+	}
+
+	if fleet.TargetCapacitySpecification != nil &&
+		fleet.TargetCapacitySpecification.DefaultTargetCapacityType == types.DefaultTargetCapacityTypeOnDemand {
+		opts.CapacityMode = CapacityModeOnDemand
+	}
+	if mode := util.GetTag(fleet.Tags, capacityModeTag); mode != "" {
+		opts.CapacityMode = CapacityMode(mode)
+	}
+	if opts.CapacityMode == CapacityModeSpotThenOnDemand {
+		fallbackAfter, err := time.ParseDuration(util.GetTag(fleet.Tags, fallbackAfterTag))
+		if err != nil {
+			return StartOptions{}, fmt.Errorf("fleet %s has an invalid fallback: %w", *fleet.FleetId, err)
+		}
+		opts.FallbackAfter = fallbackAfter
+	}
+
+	if opts.MaxPrice == "" && fleet.SpotOptions != nil {
+		opts.MaxPrice = util.GetValue(fleet.SpotOptions.MaxTotalPrice)
+	}
+	if opts.MaxPrice == "" && fleet.OnDemandOptions != nil {
+		opts.MaxPrice = util.GetValue(fleet.OnDemandOptions.MaxTotalPrice)
+	}
+
+	return opts, nil
+}
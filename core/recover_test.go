@@ -0,0 +1,125 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+func TestStartOptionsFromFleet(t *testing.T) {
+	validFrom := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	fleet := func(capacityType types.DefaultTargetCapacityType, version string, tags ...types.Tag) types.FleetData {
+		return types.FleetData{
+			FleetId:    aws.String("fleet-1"),
+			CreateTime: aws.Time(validFrom),
+			ValidFrom:  aws.Time(validFrom),
+			ValidUntil: aws.Time(validFrom.Add(30 * time.Minute)),
+			LaunchTemplateConfigs: []types.FleetLaunchTemplateConfig{
+				{
+					LaunchTemplateSpecification: &types.FleetLaunchTemplateSpecification{
+						Version: aws.String(version),
+					},
+					Overrides: []types.FleetLaunchTemplateOverrides{
+						{
+							MaxPrice: aws.String("0.05"),
+							InstanceRequirements: &types.InstanceRequirements{
+								VCpuCount: &types.VCpuCountRange{Min: aws.Int32(2)},
+								MemoryMiB: &types.MemoryMiB{Min: aws.Int32(4096)},
+							},
+						},
+					},
+				},
+			},
+			TargetCapacitySpecification: &types.TargetCapacitySpecification{
+				DefaultTargetCapacityType: capacityType,
+			},
+			Tags: tags,
+		}
+	}
+
+	tests := []struct {
+		name    string
+		fleet   types.FleetData
+		want    StartOptions
+		wantErr bool
+	}{
+		{
+			name:  "rebuilds the specs of a spot fleet",
+			fleet: fleet(types.DefaultTargetCapacityTypeSpot, "$Default"),
+			want: StartOptions{
+				Name:         "myspace",
+				MinCPUs:      2,
+				MinMemory:    4096,
+				MaxPrice:     "0.05",
+				Timeout:      30 * time.Minute,
+				CapacityMode: CapacityModeSpot,
+			},
+		},
+		{
+			name:  "keeps the launch template version",
+			fleet: fleet(types.DefaultTargetCapacityTypeSpot, "3"),
+			want: StartOptions{
+				Name:         "myspace/3",
+				MinCPUs:      2,
+				MinMemory:    4096,
+				MaxPrice:     "0.05",
+				Timeout:      30 * time.Minute,
+				CapacityMode: CapacityModeSpot,
+			},
+		},
+		{
+			name: "keeps the capacity mode and the fallback",
+			fleet: fleet(types.DefaultTargetCapacityTypeSpot, "$Default",
+				types.Tag{Key: aws.String(capacityModeTag), Value: aws.String("spot-then-on-demand")},
+				types.Tag{Key: aws.String(fallbackAfterTag), Value: aws.String("2m0s")},
+			),
+			want: StartOptions{
+				Name:          "myspace",
+				MinCPUs:       2,
+				MinMemory:     4096,
+				MaxPrice:      "0.05",
+				Timeout:       30 * time.Minute,
+				CapacityMode:  CapacityModeSpotThenOnDemand,
+				FallbackAfter: 2 * time.Minute,
+			},
+		},
+		{
+			name:  "uses the on-demand capacity of untagged fleets",
+			fleet: fleet(types.DefaultTargetCapacityTypeOnDemand, "$Default"),
+			want: StartOptions{
+				Name:         "myspace",
+				MinCPUs:      2,
+				MinMemory:    4096,
+				MaxPrice:     "0.05",
+				Timeout:      30 * time.Minute,
+				CapacityMode: CapacityModeOnDemand,
+			},
+		},
+		{
+			name: "fails on an invalid fallback",
+			fleet: fleet(types.DefaultTargetCapacityTypeSpot, "$Default",
+				types.Tag{Key: aws.String(capacityModeTag), Value: aws.String("spot-then-on-demand")},
+			),
+			wantErr: true,
+		},
+		{
+			name:    "fails without launch specs",
+			fleet:   types.FleetData{FleetId: aws.String("fleet-1")},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := startOptionsFromFleet("myspace", tt.fleet)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("startOptionsFromFleet() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("startOptionsFromFleet() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
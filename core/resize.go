@@ -0,0 +1,179 @@
+package core
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/felipemarinho97/dev-spaces/core/helpers"
+	"github.com/felipemarinho97/dev-spaces/core/util"
+)
+
+// growFSScript grows the partition (when there is one) and the filesystem of
+// the devspace volume attached to the host as the given device, and prints the
+// new usage
+const growFSScript = `sudo bash -c '
+set -e
+disk=$(readlink -f %s)
+mountpoint=""
+while read -r name mnt; do
+	if [ -n "$mnt" ]; then device=$name; mountpoint=$mnt; break; fi
+done < <(lsblk -n -l -p -o NAME,MOUNTPOINT "$disk")
+if [ -z "$mountpoint" ]; then echo "no filesystem of $disk is mounted" >&2; exit 1; fi
+fstype=$(findmnt -n -o FSTYPE "$mountpoint")
+parent=$(lsblk -n -d -o PKNAME "$device")
+if [ -n "$parent" ]; then
+	command -v growpart >/dev/null || yum install -y -q cloud-utils-growpart
+	# growpart exits with 1 when there is nothing to grow
+	growpart /dev/$parent $(cat /sys/class/block/$(basename $device)/partition) || [ $? -eq 1 ]
+fi
+case "$fstype" in
+	xfs) xfs_growfs "$mountpoint" ;;
+	ext*) resize2fs "$device" ;;
+	*) echo "unsupported filesystem: $fstype" >&2; exit 1 ;;
+esac
+df -h --output=size,used,avail "$mountpoint" | tail -1
+'`
+
+// growsFilesystem returns whether a startup script grows the filesystem of
+// the devspace volume, so a volume resized while stopped is grown on start
+func growsFilesystem(startupScript string) bool {
+	return strings.Contains(startupScript, "xfs_growfs") || strings.Contains(startupScript, "resize2fs")
+}
+
+type ResizeOptions struct {
+	// Name of the dev space
+	Name string `validate:"required"`
+	// Size is the new size of the volume in GB
+	Size int32 `validate:"required,min=1"`
+	// SSHKey is the path of the SSH key, required to grow the filesystem
+	// when the dev space is running
+	SSHKey string
+}
+
+type ResizeOutput struct {
+	// VolumeID of the resized volume
+	VolumeID string `json:"volume_id" yaml:"volume_id"`
+	// OldSize is the previous size of the volume in GB
+	OldSize int32 `json:"old_size" yaml:"old_size"`
+	// NewSize is the new size of the volume in GB
+	NewSize int32 `json:"new_size" yaml:"new_size"`
+	// Usage is the size, used and available space of the grown filesystem,
+	// empty when the dev space is not running
+	Usage string `json:"usage" yaml:"usage"`
+}
+
+// Resize grows the volume of a dev space. When the dev space is running, the
+// partition and filesystem are grown over SSH. Otherwise only the startup
+// script of the launch templates created by dev-spaces grows them on the next
+// start, for the others Resize warns to resize again once the dev space runs
+func (h *Handler) Resize(ctx context.Context, opts ResizeOptions) (_ ResizeOutput, err error) {
+	err = util.Validator.Struct(opts)
+	if err != nil {
+		return ResizeOutput{}, err
+	}
+
+	client := h.EC2Client
+	name, _ := util.GetTemplateNameAndVersion(opts.Name)
+	t := h.track("resize", name)
+	defer func() { t.Done(err) }()
+
+	t.Phase("prepare", "Checking the dev space...")
+	template, err := helpers.GetLaunchTemplateByName(ctx, client, name)
+	if err != nil {
+		return ResizeOutput{}, err
+	}
+
+	if util.GetTag(template.Tags, snapshotIDTag) != "" {
+		return ResizeOutput{}, fmt.Errorf("dev space %s is archived, unarchive it first", name)
+	}
+
+	volumeID := util.GetTag(template.Tags, "dev-spaces:volume-id")
+	if volumeID == "" {
+		return ResizeOutput{}, errors.New("unable to find volume ID")
+	}
+
+	volume, err := helpers.GetEBSVolume(ctx, client, volumeID)
+	if err != nil {
+		return ResizeOutput{}, err
+	}
+
+	oldSize := *volume.Size
+	running := len(volume.Attachments) > 0
+	if opts.Size < oldSize || (opts.Size == oldSize && !running) {
+		return ResizeOutput{}, fmt.Errorf("volumes can only grow, the current size is %d GB", oldSize)
+	}
+	if running && opts.SSHKey == "" {
+		return ResizeOutput{}, errors.New("the dev space is running, an SSH key is required to grow the filesystem")
+	}
+
+	// the same size only grows the filesystem, e.g. after a failed attempt
+	if opts.Size > oldSize {
+		t.Phase("resize-volume", fmt.Sprintf("Resizing volume %s from %d GB to %d GB...", volumeID, oldSize, opts.Size))
+		err = helpers.ModifyEBSVolumeSize(ctx, client, volumeID, opts.Size)
+		if err != nil {
+			return ResizeOutput{}, err
+		}
+
+		t.Waiting(ResourceVolume, volumeID, fmt.Sprintf("Waiting for the modification of volume %s...", volumeID))
+
+		err = helpers.WaitForEBSVolumeModification(ctx, client, volumeID)
+		if err != nil {
+			return ResizeOutput{}, err
+		}
+	}
+
+	out := ResizeOutput{
+		VolumeID: volumeID,
+		OldSize:  oldSize,
+		NewSize:  opts.Size,
+	}
+
+	if !running {
+		err = h.checkGrowOnStart(ctx, t, template, opts.Size)
+		return out, err
+	}
+
+	t.Phase("grow-filesystem", "Growing the filesystem...")
+	sshClient, err := h.Connect(ctx, ConnectOptions{
+		Name:   name,
+		SSHKey: opts.SSHKey,
+		Host:   true,
+	})
+	if err != nil {
+		return out, err
+	}
+	defer sshClient.Close()
+
+	usage, err := sshClient.Run(fmt.Sprintf(growFSScript, util.GetValue(volume.Attachments[0].Device)), 5*time.Minute)
+	if err != nil {
+		return out, fmt.Errorf("volume resized but growing the filesystem failed: %w", err)
+	}
+	out.Usage = strings.TrimSpace(usage)
+
+	return out, nil
+}
+
+// checkGrowOnStart warns when the startup script of the dev space does not
+// grow the filesystem of the resized volume on the next start
+func (h *Handler) checkGrowOnStart(ctx context.Context, t *tracker, template *types.LaunchTemplate, size int32) error {
+	defaultVersion, err := helpers.GetDefaultLaunchTemplateVersion(ctx, h.EC2Client, *template.LaunchTemplateId)
+	if err != nil {
+		return err
+	}
+	startupScript, err := base64.StdEncoding.DecodeString(util.GetValue(defaultVersion.LaunchTemplateData.UserData))
+	if err != nil {
+		return err
+	}
+
+	if !growsFilesystem(string(startupScript)) {
+		name := util.GetValue(template.LaunchTemplateName)
+		t.Warn(fmt.Sprintf("The startup script of %s does not grow the filesystem, run 'tools resize -n %s -s %d' again once it is running", name, name, size), nil)
+	}
+
+	return nil
+}
@@ -0,0 +1,23 @@
+package core
+
+import "testing"
+
+func TestGrowsFilesystem(t *testing.T) {
+	tests := []struct {
+		name          string
+		startupScript string
+		want          bool
+	}{
+		{"default startup script", DEFAULT_STARTUP_SCRIPT, true},
+		{"ext4 startup script", "mount /dev/sdf1 /devspace\nresize2fs /dev/sdf1\n", true},
+		{"startup script without the grow step", "mkdir -p /arch\nmount -t ext4 /dev/sdf1 /arch\n", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := growsFilesystem(tt.startupScript); got != tt.want {
+				t.Errorf("growsFilesystem() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,435 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/felipemarinho97/dev-spaces/core/helpers"
+	"github.com/felipemarinho97/dev-spaces/core/util"
+)
+
+const scheduleTagPrefix = "dev-spaces:schedule:"
+
+// Schedule is a weekly window in which the dev space should be running
+type Schedule struct {
+	// ID of the schedule entry
+	ID int `json:"id" yaml:"id"`
+	// Days are the weekdays on which the window opens (0 is Sunday)
+	Days []time.Weekday `json:"days" yaml:"days" validate:"required,min=1"`
+	// Start is the time of day (HH:MM) the window opens
+	Start string `json:"start" yaml:"start" validate:"required"`
+	// Stop is the time of day (HH:MM) the window closes
+	Stop string `json:"stop" yaml:"stop" validate:"required"`
+	// Location is the time zone of the window (empty means local time)
+	Location string `json:"location" yaml:"location"`
+	// MinMemory is the amount of memory in MiB
+	MinMemory int `json:"min_memory" yaml:"min_memory" validate:"min=0"`
+	// MinCPUs is the amount of cpus
+	MinCPUs int `json:"min_cpus" yaml:"min_cpus" validate:"min=0"`
+	// MaxPrice is the maximum price for the instance, required to add the entry
+	MaxPrice string `json:"max_price" yaml:"max_price"`
+}
+
+type AddScheduleOptions struct {
+	// Name of the dev space
+	Name string `validate:"required"`
+	// Schedule to add, the ID is assigned automatically
+	Schedule Schedule
+}
+
+type ListSchedulesOptions struct {
+	// Name of the dev space (empty means all dev spaces)
+	Name string
+}
+
+type RemoveScheduleOptions struct {
+	// Name of the dev space
+	Name string `validate:"required"`
+	// ID of the schedule entry
+	ID int `validate:"min=1"`
+}
+
+type ScheduleItem struct {
+	// Name of the dev space
+	Name string `json:"name" yaml:"name"`
+	// Schedule entry
+	Schedule Schedule `json:"schedule" yaml:"schedule"`
+}
+
+type RunSchedulesOptions struct {
+	// Name of the dev space (empty means all dev spaces)
+	Name string
+	// Since is the time of the previous run, windows that opened or closed
+	// after it are applied
+	Since time.Time
+	// Now is the time of this run (defaults to the current time), to be
+	// passed as Since to the next run
+	Now time.Time
+}
+
+type ScheduleAction struct {
+	// Name of the dev space
+	Name string `json:"name" yaml:"name"`
+	// ScheduleID is the schedule entry that triggered the action
+	ScheduleID int `json:"schedule_id" yaml:"schedule_id"`
+	// Action is either "start" or "stop"
+	Action string `json:"action" yaml:"action"`
+	// Start is the result of the start action
+	Start StartOutput `json:"start" yaml:"start"`
+}
+
+// AddSchedule stores a schedule entry as a tag on the dev space launch template
+func (h *Handler) AddSchedule(ctx context.Context, opts AddScheduleOptions) (Schedule, error) {
+	err := util.Validator.Struct(opts)
+	if err != nil {
+		return Schedule{}, err
+	}
+	schedule := opts.Schedule
+	err = schedule.validate()
+	if err != nil {
+		return Schedule{}, err
+	}
+	// the dev space is started with the max price of the entry
+	if _, err := strconv.ParseFloat(schedule.MaxPrice, 64); err != nil {
+		return Schedule{}, fmt.Errorf("invalid max price: %q", schedule.MaxPrice)
+	}
+
+	client := h.EC2Client
+	name, _ := util.GetTemplateNameAndVersion(opts.Name)
+	template, err := helpers.GetLaunchTemplateByName(ctx, client, name)
+	if err != nil {
+		return Schedule{}, err
+	}
+
+	schedules := getSchedules(template.Tags)
+	schedule.ID = 1
+	for _, s := range schedules {
+		if s.ID >= schedule.ID {
+			schedule.ID = s.ID + 1
+		}
+	}
+
+	err = helpers.SetLaunchTemplateTags(ctx, client, *template.LaunchTemplateId, map[string]string{
+		fmt.Sprintf("%s%d", scheduleTagPrefix, schedule.ID): schedule.encode(),
+	})
+	if err != nil {
+		return Schedule{}, err
+	}
+
+	return schedule, nil
+}
+
+// ListSchedules returns the schedule entries of the dev spaces
+func (h *Handler) ListSchedules(ctx context.Context, opts ListSchedulesOptions) ([]ScheduleItem, error) {
+	launchTemplates, err := helpers.GetLaunchTemplates(ctx, h.EC2Client)
+	if err != nil {
+		return nil, err
+	}
+
+	name, _ := util.GetTemplateNameAndVersion(opts.Name)
+	items := []ScheduleItem{}
+	for _, template := range launchTemplates.LaunchTemplates {
+		if name != "" && *template.LaunchTemplateName != name {
+			continue
+		}
+
+		for _, schedule := range getSchedules(template.Tags) {
+			items = append(items, ScheduleItem{
+				Name:     *template.LaunchTemplateName,
+				Schedule: schedule,
+			})
+		}
+	}
+
+	return items, nil
+}
+
+// RemoveSchedule deletes a schedule entry from the dev space launch template
+func (h *Handler) RemoveSchedule(ctx context.Context, opts RemoveScheduleOptions) error {
+	err := util.Validator.Struct(opts)
+	if err != nil {
+		return err
+	}
+
+	client := h.EC2Client
+	name, _ := util.GetTemplateNameAndVersion(opts.Name)
+	template, err := helpers.GetLaunchTemplateByName(ctx, client, name)
+	if err != nil {
+		return err
+	}
+
+	key := fmt.Sprintf("%s%d", scheduleTagPrefix, opts.ID)
+	if util.GetTag(template.Tags, key) == "" {
+		return fmt.Errorf("schedule %d not found for %s", opts.ID, name)
+	}
+
+	return helpers.RemoveLaunchTemplateTags(ctx, client, *template.LaunchTemplateId, key)
+}
+
+// RunSchedules starts the dev spaces whose windows opened and stops the ones
+// whose windows closed since the previous run
+func (h *Handler) RunSchedules(ctx context.Context, opts RunSchedulesOptions) ([]ScheduleAction, error) {
+	log := h.Logger
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	items, err := h.ListSchedules(ctx, ListSchedulesOptions{Name: opts.Name})
+	if err != nil {
+		return nil, err
+	}
+
+	// group the schedule entries by dev space
+	schedules := map[string][]Schedule{}
+	for _, item := range items {
+		schedules[item.Name] = append(schedules[item.Name], item.Schedule)
+	}
+
+	var actions []ScheduleAction
+	for name, entries := range schedules {
+		var open, opened, closed *Schedule
+		for i := range entries {
+			entry := &entries[i]
+			if entry.IsOpen(now) {
+				open = entry
+			}
+			if entry.opensBetween(opts.Since, now) {
+				opened = entry
+			}
+			if entry.closesBetween(opts.Since, now) {
+				closed = entry
+			}
+		}
+
+		running, err := h.isRunning(ctx, name)
+		if err != nil {
+			log.Warn(fmt.Sprintf("Error checking if %s is running: %s", name, err))
+			continue
+		}
+
+		switch {
+		case opened != nil && !running:
+			log.Info(fmt.Sprintf("Schedule %d of %s opened, starting...", opened.ID, name))
+			out, err := h.Start(ctx, StartOptions{
+				Name:      name,
+				MinCPUs:   opened.MinCPUs,
+				MinMemory: opened.MinMemory,
+				MaxPrice:  opened.MaxPrice,
+				Timeout:   opened.closesAt(now).Sub(now).Round(time.Second),
+			})
+			if err != nil {
+				log.Error(fmt.Sprintf("Error starting %s: %s", name, err))
+				continue
+			}
+			actions = append(actions, ScheduleAction{Name: name, ScheduleID: opened.ID, Action: "start", Start: out})
+		case closed != nil && open == nil && running:
+			log.Info(fmt.Sprintf("Schedule %d of %s closed, stopping...", closed.ID, name))
+			_, err := h.Stop(ctx, StopOptions{
+				Name:   name,
+				Reason: fmt.Sprintf("schedule %d closed", closed.ID),
+			})
+			if err != nil {
+				log.Error(fmt.Sprintf("Error stopping %s: %s", name, err))
+				continue
+			}
+			actions = append(actions, ScheduleAction{Name: name, ScheduleID: closed.ID, Action: "stop"})
+		}
+	}
+
+	return actions, nil
+}
+
+func (h *Handler) isRunning(ctx context.Context, name string) (bool, error) {
+	requests, err := helpers.GetFleetStatus(ctx, h.EC2Client, name)
+	if err != nil {
+		return false, err
+	}
+
+	for _, request := range requests {
+		if request.FleetState == types.FleetStateCodeActive || request.FleetState == types.FleetStateCodeSubmitted {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func getSchedules(tags []types.Tag) []Schedule {
+	schedules := []Schedule{}
+	for _, tag := range tags {
+		if !strings.HasPrefix(*tag.Key, scheduleTagPrefix) {
+			continue
+		}
+
+		id, err := strconv.Atoi(strings.TrimPrefix(*tag.Key, scheduleTagPrefix))
+		if err != nil {
+			continue
+		}
+
+		schedule, err := decodeSchedule(id, *tag.Value)
+		if err != nil {
+			continue
+		}
+		schedules = append(schedules, schedule)
+	}
+
+	sort.Slice(schedules, func(i, j int) bool {
+		return schedules[i].ID < schedules[j].ID
+	})
+
+	return schedules
+}
+
+// encode serializes the schedule entry as a tag value, e.g.
+// "days=1,2,3,4,5;start=09:00;stop=19:00;tz=Europe/Berlin;cpus=2;mem=4096;price=0.05"
+func (s Schedule) encode() string {
+	days := util.Map(s.Days, func(d time.Weekday) string {
+		return strconv.Itoa(int(d))
+	})
+
+	return fmt.Sprintf("days=%s;start=%s;stop=%s;tz=%s;cpus=%d;mem=%d;price=%s",
+		strings.Join(days, ","), s.Start, s.Stop, s.Location, s.MinCPUs, s.MinMemory, s.MaxPrice)
+}
+
+func decodeSchedule(id int, value string) (Schedule, error) {
+	schedule := Schedule{ID: id}
+	for _, part := range strings.Split(value, ";") {
+		key, val, _ := strings.Cut(part, "=")
+		switch key {
+		case "days":
+			for _, d := range strings.Split(val, ",") {
+				day, err := strconv.Atoi(d)
+				if err != nil {
+					return Schedule{}, err
+				}
+				schedule.Days = append(schedule.Days, time.Weekday(day))
+			}
+		case "start":
+			schedule.Start = val
+		case "stop":
+			schedule.Stop = val
+		case "tz":
+			schedule.Location = val
+		case "cpus":
+			schedule.MinCPUs, _ = strconv.Atoi(val)
+		case "mem":
+			schedule.MinMemory, _ = strconv.Atoi(val)
+		case "price":
+			schedule.MaxPrice = val
+		}
+	}
+
+	return schedule, schedule.validate()
+}
+
+func (s Schedule) validate() error {
+	err := util.Validator.Struct(s)
+	if err != nil {
+		return err
+	}
+
+	if _, err := time.Parse("15:04", s.Start); err != nil {
+		return fmt.Errorf("invalid start time %q, expected HH:MM", s.Start)
+	}
+	if _, err := time.Parse("15:04", s.Stop); err != nil {
+		return fmt.Errorf("invalid stop time %q, expected HH:MM", s.Stop)
+	}
+	if s.Start == s.Stop {
+		return fmt.Errorf("start and stop times must be different")
+	}
+	if _, err := s.location(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s Schedule) location() (*time.Location, error) {
+	if s.Location == "" {
+		return time.Local, nil
+	}
+
+	return time.LoadLocation(s.Location)
+}
+
+// windows returns the windows (open and close times) of the days between from and to
+func (s Schedule) windows(from, to time.Time) [][2]time.Time {
+	loc, err := s.location()
+	if err != nil {
+		loc = time.Local
+	}
+	start, _ := time.Parse("15:04", s.Start)
+	stop, _ := time.Parse("15:04", s.Stop)
+
+	from = from.In(loc)
+	to = to.In(loc)
+
+	var windows [][2]time.Time
+	// start a day earlier to include windows that cross midnight
+	day := time.Date(from.Year(), from.Month(), from.Day()-1, 0, 0, 0, 0, loc)
+	for !day.After(to) {
+		for _, d := range s.Days {
+			if day.Weekday() != d {
+				continue
+			}
+
+			opens := time.Date(day.Year(), day.Month(), day.Day(), start.Hour(), start.Minute(), 0, 0, loc)
+			closes := time.Date(day.Year(), day.Month(), day.Day(), stop.Hour(), stop.Minute(), 0, 0, loc)
+			if !closes.After(opens) {
+				closes = closes.AddDate(0, 0, 1)
+			}
+			windows = append(windows, [2]time.Time{opens, closes})
+		}
+		day = day.AddDate(0, 0, 1)
+	}
+
+	return windows
+}
+
+// IsOpen reports whether the schedule window is open at the given time
+func (s Schedule) IsOpen(t time.Time) bool {
+	for _, w := range s.windows(t, t) {
+		if !t.Before(w[0]) && t.Before(w[1]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s Schedule) opensBetween(from, to time.Time) bool {
+	for _, w := range s.windows(from, to) {
+		if w[0].After(from) && !w[0].After(to) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s Schedule) closesBetween(from, to time.Time) bool {
+	for _, w := range s.windows(from, to) {
+		if w[1].After(from) && !w[1].After(to) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// closesAt returns the closing time of the window open at the given time
+func (s Schedule) closesAt(t time.Time) time.Time {
+	for _, w := range s.windows(t, t) {
+		if !t.Before(w[0]) && t.Before(w[1]) {
+			return w[1]
+		}
+	}
+
+	return t
+}
@@ -0,0 +1,195 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+// date returns the given time in UTC, 2023-09-01 is a Friday
+func date(day, hour, min int) time.Time {
+	return time.Date(2023, time.September, day, hour, min, 0, 0, time.UTC)
+}
+
+var (
+	weekdays = Schedule{
+		Days:     []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+		Start:    "09:00",
+		Stop:     "19:00",
+		Location: "UTC",
+	}
+	// nights opens on Friday and Saturday evening and closes the next morning
+	nights = Schedule{
+		Days:     []time.Weekday{time.Friday, time.Saturday},
+		Start:    "22:00",
+		Stop:     "02:00",
+		Location: "UTC",
+	}
+)
+
+func TestSchedule_IsOpen(t *testing.T) {
+	tests := []struct {
+		name     string
+		schedule Schedule
+		t        time.Time
+		want     bool
+	}{
+		{"weekday inside the window", weekdays, date(1, 12, 0), true},
+		{"weekday at the opening", weekdays, date(1, 9, 0), true},
+		{"weekday at the closing", weekdays, date(1, 19, 0), false},
+		{"weekday before the window", weekdays, date(1, 8, 59), false},
+		{"day not in the set", weekdays, date(2, 12, 0), false},
+		{"window crossing midnight before midnight", nights, date(1, 23, 0), true},
+		{"window crossing midnight after midnight", nights, date(2, 1, 0), true},
+		{"window of the day before crossing midnight", nights, date(3, 1, 59), true},
+		{"window crossing midnight after its closing", nights, date(3, 2, 0), false},
+		{"after midnight following a day not in the set", nights, date(1, 1, 0), false},
+		{"evening of a day not in the set", nights, date(3, 23, 0), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.schedule.IsOpen(tt.t); got != tt.want {
+				t.Errorf("IsOpen(%s) = %v, want %v", tt.t, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSchedule_IsOpen_location(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("time zone database not available")
+	}
+	schedule := weekdays
+	schedule.Location = "Europe/Berlin"
+
+	// 08:30 UTC is 10:30 in Berlin (CEST)
+	if !schedule.IsOpen(date(1, 8, 30)) {
+		t.Errorf("IsOpen(%s) = false, want true", date(1, 8, 30).In(berlin))
+	}
+	// 17:30 UTC is 19:30 in Berlin
+	if schedule.IsOpen(date(1, 17, 30)) {
+		t.Errorf("IsOpen(%s) = true, want false", date(1, 17, 30).In(berlin))
+	}
+}
+
+func TestSchedule_opensBetween(t *testing.T) {
+	tests := []struct {
+		name     string
+		schedule Schedule
+		from, to time.Time
+		want     bool
+	}{
+		{"opening in the interval", weekdays, date(1, 8, 59), date(1, 9, 0), true},
+		{"opening at the start of the interval", weekdays, date(1, 9, 0), date(1, 9, 1), false},
+		{"open during the whole interval", weekdays, date(1, 10, 0), date(1, 11, 0), false},
+		{"opening on a day not in the set", weekdays, date(2, 8, 59), date(2, 9, 1), false},
+		{"opening over a weekend", weekdays, date(1, 20, 0), date(4, 9, 0), true},
+		{"opening before midnight", nights, date(1, 21, 59), date(1, 22, 1), true},
+		{"interval crossing midnight", nights, date(1, 23, 0), date(2, 1, 0), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.schedule.opensBetween(tt.from, tt.to); got != tt.want {
+				t.Errorf("opensBetween(%s, %s) = %v, want %v", tt.from, tt.to, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSchedule_closesBetween(t *testing.T) {
+	tests := []struct {
+		name     string
+		schedule Schedule
+		from, to time.Time
+		want     bool
+	}{
+		{"closing in the interval", weekdays, date(1, 18, 59), date(1, 19, 0), true},
+		{"closed during the whole interval", weekdays, date(1, 20, 0), date(1, 21, 0), false},
+		{"closing on a day not in the set", weekdays, date(2, 18, 59), date(2, 19, 1), false},
+		{"closing after midnight", nights, date(2, 1, 59), date(2, 2, 1), true},
+		{"closing after midnight of the last day of the set", nights, date(3, 1, 59), date(3, 2, 1), true},
+		{"closing after midnight of a day not in the set", nights, date(1, 1, 59), date(1, 2, 1), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.schedule.closesBetween(tt.from, tt.to); got != tt.want {
+				t.Errorf("closesBetween(%s, %s) = %v, want %v", tt.from, tt.to, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSchedule_closesAt(t *testing.T) {
+	tests := []struct {
+		name     string
+		schedule Schedule
+		t        time.Time
+		want     time.Time
+	}{
+		{"weekday window", weekdays, date(1, 12, 0), date(1, 19, 0)},
+		{"window crossing midnight before midnight", nights, date(1, 23, 0), date(2, 2, 0)},
+		{"window crossing midnight after midnight", nights, date(2, 1, 0), date(2, 2, 0)},
+		{"closed window", weekdays, date(2, 12, 0), date(2, 12, 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.schedule.closesAt(tt.t); !got.Equal(tt.want) {
+				t.Errorf("closesAt(%s) = %s, want %s", tt.t, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeSchedule(t *testing.T) {
+	schedule := nights
+	schedule.ID = 2
+	schedule.MinCPUs = 2
+	schedule.MinMemory = 4096
+	schedule.MaxPrice = "0.05"
+
+	got, err := decodeSchedule(2, schedule.encode())
+	if err != nil {
+		t.Fatalf("decodeSchedule() error = %v", err)
+	}
+	if got.encode() != schedule.encode() || got.ID != schedule.ID {
+		t.Errorf("decodeSchedule() = %+v, want %+v", got, schedule)
+	}
+
+	_, err = decodeSchedule(3, "days=1;start=09:00;stop=09:00")
+	if err == nil {
+		t.Errorf("decodeSchedule() with equal start and stop times succeeded, want an error")
+	}
+}
+
+func TestHandler_AddSchedule_maxPrice(t *testing.T) {
+	tests := []struct {
+		name     string
+		maxPrice string
+		wantErr  bool
+	}{
+		{"max price", "0.05", false},
+		{"no max price", "", true},
+		{"invalid max price", "cheap", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &fakeEC2Client{templates: []types.LaunchTemplate{devSpaceTemplate("MySpace", nil)}}
+			h := NewHandler(Config{}, client, nil, nopLogger{})
+			schedule := weekdays
+			schedule.MaxPrice = tt.maxPrice
+
+			_, err := h.AddSchedule(context.Background(), AddScheduleOptions{Name: "MySpace", Schedule: schedule})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("AddSchedule() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
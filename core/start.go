@@ -2,9 +2,11 @@ package core
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
 	"github.com/felipemarinho97/dev-spaces/core/helpers"
@@ -13,6 +15,23 @@ import (
 	"github.com/felipemarinho97/invest-path/clients"
 )
 
+type CapacityMode string
+
+const (
+	// CapacityModeSpot only requests spot capacity
+	CapacityModeSpot CapacityMode = "spot"
+	// CapacityModeSpotThenOnDemand requests spot capacity and falls back to
+	// on-demand if no spot instance is obtained within FallbackAfter
+	CapacityModeSpotThenOnDemand CapacityMode = "spot-then-on-demand"
+	// CapacityModeOnDemand only requests on-demand capacity
+	CapacityModeOnDemand CapacityMode = "on-demand"
+
+	// capacityModeTag and fallbackAfterTag keep the capacity options on the
+	// fleet requests, so interrupted dev spaces are relaunched with them
+	capacityModeTag  = "dev-spaces:capacity-mode"
+	fallbackAfterTag = "dev-spaces:fallback-after"
+)
+
 type StartOptions struct {
 	// Name of the Dev Space
 	Name string `validate:"required"`
@@ -24,25 +43,46 @@ type StartOptions struct {
 	MaxPrice string `validate:"required"`
 	// Timeout is the time in minutes to wait for the instance to be running
 	Timeout time.Duration `validate:"min=0"`
+	// CapacityMode is the capacity to request (defaults to spot)
+	CapacityMode CapacityMode `validate:"omitempty,oneof=spot spot-then-on-demand on-demand"`
+	// FallbackAfter is the time to wait for spot capacity before falling back
+	// to on-demand when using CapacityModeSpotThenOnDemand
+	FallbackAfter time.Duration `validate:"min=0"`
+	// IgnoreBudget places the fleet request even if it could exceed a budget
+	IgnoreBudget bool
 }
 
 type StartOutput struct {
 	// InstanceID is the instance id
-	InstanceID string
+	InstanceID string `json:"instance_id" yaml:"instance_id"`
 	// Type is the instance type
-	Type string
+	Type string `json:"instance_type" yaml:"instance_type"`
 	// PublicIP is the public PublicIP of the instance
-	PublicIP string
+	PublicIP string `json:"public_ip" yaml:"public_ip"`
 	// DNS is the DNS name of the instance
-	DNS string
+	DNS string `json:"public_dns" yaml:"public_dns"`
 	// Port is the port to connect to the instance
-	Port int
+	Port int `json:"port" yaml:"port"`
+	// CapacityType is the capacity type obtained (spot or on-demand)
+	CapacityType string `json:"capacity_type" yaml:"capacity_type"`
+	// Hostname is the stable hostname of the dev space when dynamic DNS is enabled
+	Hostname string `json:"hostname" yaml:"hostname"`
 }
 
-func (h *Handler) Start(ctx context.Context, startOptions StartOptions) (StartOutput, error) {
+// Host returns the stable hostname of the dev space, or its public IP when
+// dynamic DNS is disabled
+func (o StartOutput) Host() string {
+	if o.Hostname != "" {
+		return o.Hostname
+	}
+
+	return o.PublicIP
+}
+
+func (h *Handler) Start(ctx context.Context, startOptions StartOptions) (_ StartOutput, err error) {
 	log := h.Logger
 
-	err := util.Validator.Struct(startOptions)
+	err = util.Validator.Struct(startOptions)
 	if err != nil {
 		return StartOutput{}, err
 	}
@@ -54,90 +94,233 @@ func (h *Handler) Start(ctx context.Context, startOptions StartOptions) (StartOu
 	maxPrice := startOptions.MaxPrice
 	timeout := startOptions.Timeout
 	tName, tVersion := util.GetTemplateNameAndVersion(name)
+	t := h.track("start", tName)
+	defer func() { t.Done(err) }()
 
+	t.Phase("prepare", "Checking the dev space...")
 	template, err := helpers.GetLaunchTemplateByName(ctx, client, tName)
 	if err != nil {
 		return StartOutput{}, err
 	}
 
+	// check the budget before restoring the volume of an archived dev space
+	if !startOptions.IgnoreBudget {
+		err = h.checkBudget(ctx, template, startOptions.CapacityMode, cpusSpec, minMemory, maxPrice, timeout)
+		if err != nil {
+			return StartOutput{}, err
+		}
+	}
+
+	// restore the volume of archived dev spaces
+	if util.GetTag(template.Tags, snapshotIDTag) != "" {
+		_, err = h.unarchive(ctx, t, template, false)
+		if err != nil {
+			return StartOutput{}, err
+		}
+
+		template, err = helpers.GetLaunchTemplateByName(ctx, client, tName)
+		if err != nil {
+			return StartOutput{}, err
+		}
+	}
+
 	// get volume id from template tags
 	volumeID := util.GetTag(template.Tags, "dev-spaces:volume-id")
 
 	// wait until ebs volume is detached
-	log.Info("Waiting for EBS volume to be available...")
+	t.Waiting(ResourceVolume, volumeID, "Waiting for EBS volume to be available...")
 	err = helpers.WaitUntilEBSUnattached(ctx, client, volumeID)
 	if err != nil {
 		return StartOutput{}, err
 	}
 
-	out, err := helpers.CreateSpotRequest(ctx, client, tName, tVersion, cpusSpec, minMemory, maxPrice, template, timeout)
+	t.Phase("fleet-request", "Requesting instance...")
+
+	capacityType := types.DefaultTargetCapacityTypeSpot
+	if startOptions.CapacityMode == CapacityModeOnDemand {
+		capacityType = types.DefaultTargetCapacityTypeOnDemand
+	}
+
+	fleetTags := capacityTags(startOptions)
+	out, err := helpers.CreateFleetRequest(ctx, client, tName, tVersion, cpusSpec, minMemory, maxPrice, template, timeout, capacityType, fleetTags...)
 	if err != nil {
 		return StartOutput{}, err
 	}
 
 	fleetRequestID := out.FleetId
-	log.Debug("Created spot fleet request with id: ", *fleetRequestID)
+	t.Created(ResourceFleetRequest, *fleetRequestID, fmt.Sprintf("Created %s fleet request with id: %s", capacityType, *fleetRequestID))
+
+	if startOptions.CapacityMode == CapacityModeSpotThenOnDemand {
+		t.Waiting(ResourceFleetRequest, *fleetRequestID, fmt.Sprintf("Waiting up to %s for spot capacity...", startOptions.FallbackAfter))
+		fulfilled, err := waitFleetFulfilled(ctx, client, fleetRequestID, startOptions.FallbackAfter)
+		if err != nil {
+			return StartOutput{}, err
+		}
+
+		// the spot instance may be launched right after the wait, and
+		// cancelling the fleet would terminate it
+		if !fulfilled {
+			fulfilled, err = isFleetFulfilled(ctx, client, fleetRequestID)
+			if err != nil {
+				return StartOutput{}, err
+			}
+		}
+
+		if !fulfilled {
+			t.Warn("No spot capacity available, falling back to on-demand...", nil)
+			err = helpers.CancelFleetRequests(ctx, client, []string{*fleetRequestID})
+			if err != nil {
+				return StartOutput{}, err
+			}
+
+			out, err = helpers.CreateFleetRequest(ctx, client, tName, tVersion, cpusSpec, minMemory, maxPrice, template, timeout, types.DefaultTargetCapacityTypeOnDemand, fleetTags...)
+			if err != nil {
+				return StartOutput{}, err
+			}
+			fleetRequestID = out.FleetId
+			t.Created(ResourceFleetRequest, *fleetRequestID, fmt.Sprintf("Created on-demand fleet request with id: %s", *fleetRequestID))
+		}
+	}
 
 	// wait for instance to be running
-	log.Info("Waiting for instance to be running...")
-	instance, err := waitInstance(ctx, client, log, fleetRequestID)
+	t.Phase("instance", "Waiting for instance to be running...")
+	t.Waiting(ResourceFleetRequest, *fleetRequestID, "Waiting for instance to be running...")
+	instance, err := waitInstance(ctx, client, log, fleetRequestID, timeout)
 	if err != nil {
+		// do not leave the fleet request open when the wait is cancelled or times out
+		cancelErr := helpers.CancelFleetRequests(context.WithoutCancel(ctx), client, []string{*fleetRequestID})
+		if cancelErr != nil {
+			t.Warn(fmt.Sprintf("Error cancelling fleet request %s", *fleetRequestID), cancelErr)
+		}
 		return StartOutput{}, err
 	}
 
 	ip := *instance.PublicIpAddress
+	t.Created(ResourceInstance, *instance.InstanceId, fmt.Sprintf("Instance %s is running", *instance.InstanceId))
 
 	// attach ebs volume
+	t.Phase("volume", fmt.Sprintf("Attaching EBS volume %s...", volumeID))
 	err = helpers.AttachEBSVolume(ctx, client, *instance.InstanceId, volumeID)
 	if err != nil {
 		return StartOutput{}, err
 	}
-	log.Info("Attached EBS volume with id: ", volumeID)
+
+	var hostname string
+	if h.Config.DNS.enabled() {
+		t.Phase("dns", "Updating DNS record...")
+		hostname = h.updateDNS(ctx, tName, ip)
+	}
 
 	return StartOutput{
-		InstanceID: *instance.InstanceId,
-		Type:       string(instance.InstanceType),
-		PublicIP:   ip,
-		Port:       2222,
-		DNS:        *instance.PublicDnsName,
+		InstanceID:   *instance.InstanceId,
+		Type:         string(instance.InstanceType),
+		PublicIP:     ip,
+		Port:         2222,
+		DNS:          *instance.PublicDnsName,
+		CapacityType: getCapacityType(instance),
+		Hostname:     hostname,
 	}, nil
 }
 
-func waitInstance(ctx context.Context, client clients.IEC2Client, log log.Logger, id *string) (*types.Instance, error) {
-	for {
-		time.Sleep(time.Second * 1)
-		out2, err := client.DescribeFleetInstances(ctx, &ec2.DescribeFleetInstancesInput{
-			FleetId: id,
+// waitFleetFulfilled waits until the fleet has an active instance or the
+// given duration elapses, returning whether the fleet was fulfilled
+func waitFleetFulfilled(ctx context.Context, client clients.IEC2Client, id *string, within time.Duration) (bool, error) {
+	waiter := util.NewWaiter(fmt.Sprintf("spot capacity for fleet %s", *id), within)
+	err := waiter.Wait(ctx, func(ctx context.Context) (bool, error) {
+		return isFleetFulfilled(ctx, client, id)
+	})
+
+	// only the fallback timeout means the fleet was not fulfilled
+	var timeoutErr *util.TimeoutError
+	if errors.As(err, &timeoutErr) && ctx.Err() == nil {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// capacityTags returns the tags with the capacity options of a fleet request
+func capacityTags(opts StartOptions) []types.Tag {
+	if opts.CapacityMode == "" {
+		return nil
+	}
+
+	tags := []types.Tag{
+		{Key: aws.String(capacityModeTag), Value: aws.String(string(opts.CapacityMode))},
+	}
+	if opts.CapacityMode == CapacityModeSpotThenOnDemand {
+		tags = append(tags, types.Tag{Key: aws.String(fallbackAfterTag), Value: aws.String(opts.FallbackAfter.String())})
+	}
+
+	return tags
+}
+
+// isFleetFulfilled reports whether the fleet has an active instance
+func isFleetFulfilled(ctx context.Context, client clients.IEC2Client, id *string) (bool, error) {
+	out, err := client.DescribeFleetInstances(ctx, &ec2.DescribeFleetInstancesInput{
+		FleetId: id,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return len(out.ActiveInstances) > 0, nil
+}
+
+// getCapacityType returns the capacity type of a running instance
+func getCapacityType(instance *types.Instance) string {
+	if instance.InstanceLifecycle == types.InstanceLifecycleTypeSpot {
+		return string(types.DefaultTargetCapacityTypeSpot)
+	}
+
+	return string(types.DefaultTargetCapacityTypeOnDemand)
+}
+
+// waitInstance waits up to timeout for the instance of a fleet to be running
+func waitInstance(ctx context.Context, client clients.IEC2Client, log log.Logger, id *string, timeout time.Duration) (*types.Instance, error) {
+	var instanceID string
+	var instance *types.Instance
+	waiter := util.NewWaiter(fmt.Sprintf("fleet %s instance to be running", *id), timeout)
+	err := waiter.Wait(ctx, func(ctx context.Context) (bool, error) {
+		if instanceID == "" {
+			out, err := client.DescribeFleetInstances(ctx, &ec2.DescribeFleetInstancesInput{
+				FleetId: id,
+			})
+			if err != nil {
+				return false, err
+			}
+			if len(out.ActiveInstances) == 0 {
+				return false, nil
+			}
+
+			active := out.ActiveInstances[0]
+			instanceID = *active.InstanceId
+			log.Info(fmt.Sprintf("Instance started with id: %s and type: %s", instanceID, *active.InstanceType))
+		}
+
+		out, err := client.DescribeInstances(ctx, &ec2.DescribeInstancesInput{
+			InstanceIds: []string{instanceID},
 		})
 		if err != nil {
-			log.Error(err)
-			return nil, err
+			return false, err
 		}
-
-		if len(out2.ActiveInstances) > 0 {
-			instance := out2.ActiveInstances[0]
-			log.Info(fmt.Sprintf("Instance started with id: %s and type: %s", *instance.InstanceId, *instance.InstanceType))
-
-			for {
-				time.Sleep(time.Second * 1)
-				out3, err := client.DescribeInstances(ctx, &ec2.DescribeInstancesInput{
-					InstanceIds: []string{*instance.InstanceId},
-				})
-				if err != nil {
-					log.Error(err)
-					return nil, err
-				}
-				if len(out3.Reservations) > 0 {
-					reservation := out3.Reservations[0]
-					if len(reservation.Instances) > 0 {
-						instance := reservation.Instances[0]
-						if instance.State.Name == types.InstanceStateNameRunning {
-							return &instance, nil
-						}
-					}
-				}
+		if len(out.Reservations) > 0 && len(out.Reservations[0].Instances) > 0 {
+			i := out.Reservations[0].Instances[0]
+			if i.State.Name == types.InstanceStateNameRunning {
+				instance = &i
+				return true, nil
 			}
 		}
 
+		return false, nil
+	})
+	if err != nil {
+		log.Error(err)
+		return nil, err
 	}
+
+	return instance, nil
 }
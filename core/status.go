@@ -27,11 +27,11 @@ type StatusOptions struct {
 }
 
 type StatusItem struct {
-	Name         string
-	Status       Status
-	RequestId    string
-	CreateTime   string
-	ActivityStat string
+	Name         string `json:"name" yaml:"name"`
+	Status       Status `json:"status" yaml:"status"`
+	RequestId    string `json:"request_id" yaml:"request_id"`
+	CreateTime   string `json:"create_time" yaml:"create_time"`
+	ActivityStat string `json:"activity_status" yaml:"activity_status"`
 }
 
 func (h *Handler) Status(ctx context.Context, opts StatusOptions) ([]StatusItem, error) {
@@ -2,30 +2,109 @@ package core
 
 import (
 	"context"
+	"fmt"
+	"time"
 
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
 	"github.com/felipemarinho97/dev-spaces/core/helpers"
+	"github.com/felipemarinho97/dev-spaces/core/util"
 )
 
 type StopOptions struct {
 	// Name of the dev space
 	Name string
+	// Reason is recorded on the dev space launch template (optional)
+	Reason string
 }
 
 type StopOutput struct {
 	// Quantity of instances that were stopped
-	Quantity int
+	Quantity int `json:"quantity" yaml:"quantity"`
 }
 
-func (h *Handler) Stop(ctx context.Context, opts StopOptions) (StopOutput, error) {
+func (h *Handler) Stop(ctx context.Context, opts StopOptions) (_ StopOutput, err error) {
 	name := opts.Name
 
 	client := h.EC2Client
 	log := h.Logger
+	t := h.track("stop", name)
+	defer func() { t.Done(err) }()
 
+	// dev spaces to back up once stopped, according to their backup policy
+	t.Phase("prepare", "Checking the backup policies...")
+	toBackup, err := h.getRunningWithBackupPolicy(ctx, name)
+	if err != nil {
+		t.Warn("Error checking backup policies", err)
+	}
+
+	t.Phase("fleet-requests", "Cancelling fleet requests...")
 	qnt, err := helpers.CancelSpotRequests(ctx, client, log, name)
 	if err != nil {
 		return StopOutput{}, err
 	}
 
+	if h.Config.DNS.enabled() {
+		t.Phase("dns", "Removing DNS record...")
+		h.removeDNS(ctx, name)
+	}
+
+	for _, template := range toBackup {
+		err = h.backupOnStop(ctx, t, template)
+		if err != nil {
+			t.Warn(fmt.Sprintf("Error backing up %s", *template.LaunchTemplateName), err)
+		}
+	}
+
+	if opts.Reason != "" && name != "" {
+		tName, _ := util.GetTemplateNameAndVersion(name)
+		template, err := helpers.GetLaunchTemplateByName(ctx, client, tName)
+		if err != nil {
+			return StopOutput{}, err
+		}
+
+		err = helpers.SetLaunchTemplateTags(ctx, client, *template.LaunchTemplateId, map[string]string{
+			"dev-spaces:stop-reason": opts.Reason,
+			"dev-spaces:stopped-at":  time.Now().UTC().Format(time.RFC3339),
+		})
+		if err != nil {
+			t.Warn("Error recording stop reason", err)
+		}
+	}
+
 	return StopOutput{Quantity: qnt}, nil
 }
+
+// getRunningWithBackupPolicy returns the launch templates of the running dev
+// spaces with a backup policy, or only of the named one
+func (h *Handler) getRunningWithBackupPolicy(ctx context.Context, name string) ([]*types.LaunchTemplate, error) {
+	client := h.EC2Client
+	name, _ = util.GetTemplateNameAndVersion(name)
+
+	launchTemplates, err := helpers.GetLaunchTemplates(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+
+	var templates []*types.LaunchTemplate
+	for i := range launchTemplates.LaunchTemplates {
+		template := &launchTemplates.LaunchTemplates[i]
+		if name != "" && *template.LaunchTemplateName != name {
+			continue
+		}
+
+		volumeID := util.GetTag(template.Tags, "dev-spaces:volume-id")
+		if getBackupPolicy(template) == 0 || volumeID == "" {
+			continue
+		}
+
+		isAttached, err := helpers.IsEBSAttached(ctx, client, volumeID)
+		if err != nil {
+			return nil, err
+		}
+		if isAttached {
+			templates = append(templates, template)
+		}
+	}
+
+	return templates, nil
+}
@@ -0,0 +1,37 @@
+// Package templates embeds the built-in bootstrap templates
+package templates
+
+import (
+	"embed"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+//go:embed *.yaml
+var files embed.FS
+
+const extension = ".yaml"
+
+// Names returns the names of the built-in templates, sorted
+func Names() []string {
+	entries, _ := files.ReadDir(".")
+
+	var names []string
+	for _, entry := range entries {
+		names = append(names, strings.TrimSuffix(entry.Name(), extension))
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Read returns the YAML of a built-in template
+func Read(name string) ([]byte, error) {
+	data, err := files.ReadFile(name + extension)
+	if err != nil {
+		return nil, fmt.Errorf("no built-in template named %s, available: %s", name, strings.Join(Names(), ", "))
+	}
+
+	return data, nil
+}
@@ -0,0 +1,241 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/felipemarinho97/dev-spaces/core/util"
+	"github.com/pkg/sftp"
+)
+
+type TransferOptions struct {
+	// Name of the dev space
+	Name string `validate:"required"`
+	// SSHKey is the path of the SSH key
+	SSHKey string `validate:"required"`
+	// Host transfers to the host (port 22) instead of the dev space (port 2222)
+	Host bool
+	// User is the dev space user (defaults to root), ignored for the host
+	User string
+	// Source is the path of the file or directory to copy
+	Source string `validate:"required"`
+	// Destination is the path to copy to. If it is an existing directory,
+	// the source is copied into it
+	Destination string `validate:"required"`
+	// Download copies from the dev space to the local machine, otherwise
+	// the local Source is uploaded to the dev space
+	Download bool
+	// Recursive allows copying directories
+	Recursive bool
+	// Progress is called before each file is copied and returns a writer
+	// that receives the copied bytes (optional)
+	Progress func(path string, size int64) io.Writer
+}
+
+type TransferOutput struct {
+	// Files is the number of copied files
+	Files int `json:"files" yaml:"files"`
+	// Bytes is the number of copied bytes
+	Bytes int64 `json:"bytes" yaml:"bytes"`
+}
+
+// transferFS is the subset of file system operations needed to copy files
+// between the local machine and the dev space
+type transferFS interface {
+	Stat(name string) (fs.FileInfo, error)
+	ReadDir(name string) ([]fs.FileInfo, error)
+	Open(name string) (io.ReadCloser, error)
+	Create(name string) (io.WriteCloser, error)
+	Mkdir(name string) error
+	Chmod(name string, mode fs.FileMode) error
+	Join(elem ...string) string
+	Base(name string) string
+}
+
+type transfer struct {
+	ctx      context.Context
+	src, dst transferFS
+	progress func(path string, size int64) io.Writer
+	out      TransferOutput
+}
+
+// Transfer copies files between the local machine and the running dev space over SFTP
+func (h *Handler) Transfer(ctx context.Context, opts TransferOptions) (TransferOutput, error) {
+	err := util.Validator.Struct(opts)
+	if err != nil {
+		return TransferOutput{}, err
+	}
+
+	sshClient, err := h.Connect(ctx, ConnectOptions{
+		Name:   opts.Name,
+		SSHKey: opts.SSHKey,
+		Host:   opts.Host,
+		User:   opts.User,
+	})
+	if err != nil {
+		return TransferOutput{}, err
+	}
+	defer sshClient.Close()
+
+	sftpClient, err := sshClient.SFTP()
+	if err != nil {
+		return TransferOutput{}, fmt.Errorf("error starting sftp session: %w", err)
+	}
+	defer sftpClient.Close()
+
+	t := &transfer{
+		ctx:      ctx,
+		src:      localFS{},
+		dst:      remoteFS{sftpClient},
+		progress: opts.Progress,
+	}
+	if opts.Download {
+		t.src, t.dst = t.dst, t.src
+	}
+
+	info, err := t.src.Stat(opts.Source)
+	if err != nil {
+		return TransferOutput{}, err
+	}
+	if info.IsDir() && !opts.Recursive {
+		return TransferOutput{}, fmt.Errorf("%s is a directory, use recursive to copy it", opts.Source)
+	}
+
+	// copy into the destination when it is an existing directory, like cp
+	dst := opts.Destination
+	if dstInfo, err := t.dst.Stat(dst); err == nil && dstInfo.IsDir() {
+		dst = t.dst.Join(dst, t.src.Base(opts.Source))
+	}
+
+	err = t.copy(opts.Source, dst, info)
+
+	return t.out, err
+}
+
+func (t *transfer) copy(src, dst string, info fs.FileInfo) error {
+	if err := t.ctx.Err(); err != nil {
+		return err
+	}
+
+	if !info.IsDir() {
+		return t.copyFile(src, dst, info)
+	}
+
+	err := t.dst.Mkdir(dst)
+	if err != nil {
+		if dstInfo, statErr := t.dst.Stat(dst); statErr != nil || !dstInfo.IsDir() {
+			return err
+		}
+	}
+
+	entries, err := t.src.ReadDir(src)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() && !entry.Mode().IsRegular() {
+			// skip symlinks, sockets, devices, etc.
+			continue
+		}
+
+		err = t.copy(t.src.Join(src, entry.Name()), t.dst.Join(dst, entry.Name()), entry)
+		if err != nil {
+			return err
+		}
+	}
+
+	return t.dst.Chmod(dst, info.Mode().Perm())
+}
+
+func (t *transfer) copyFile(src, dst string, info fs.FileInfo) error {
+	r, err := t.src.Open(src)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	w, err := t.dst.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+
+	var reader io.Reader = r
+	if t.progress != nil {
+		reader = io.TeeReader(r, t.progress(src, info.Size()))
+	}
+
+	n, err := io.Copy(w, &contextReader{ctx: t.ctx, r: reader})
+	t.out.Bytes += n
+	if err != nil {
+		return err
+	}
+
+	err = w.Close()
+	if err != nil {
+		return err
+	}
+	t.out.Files++
+
+	return t.dst.Chmod(dst, info.Mode().Perm())
+}
+
+// contextReader stops reading when ctx is done
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (c *contextReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.r.Read(p)
+}
+
+type localFS struct{}
+
+func (localFS) Stat(name string) (fs.FileInfo, error)      { return os.Stat(name) }
+func (localFS) Open(name string) (io.ReadCloser, error)    { return os.Open(name) }
+func (localFS) Create(name string) (io.WriteCloser, error) { return os.Create(name) }
+func (localFS) Mkdir(name string) error                    { return os.Mkdir(name, 0755) }
+func (localFS) Chmod(name string, mode fs.FileMode) error  { return os.Chmod(name, mode) }
+func (localFS) Join(elem ...string) string                 { return filepath.Join(elem...) }
+func (localFS) Base(name string) string                    { return filepath.Base(name) }
+
+func (localFS) ReadDir(name string) ([]fs.FileInfo, error) {
+	entries, err := os.ReadDir(name)
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]fs.FileInfo, 0, len(entries))
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+		infos = append(infos, info)
+	}
+
+	return infos, nil
+}
+
+type remoteFS struct {
+	client *sftp.Client
+}
+
+func (r remoteFS) Stat(name string) (fs.FileInfo, error)      { return r.client.Stat(name) }
+func (r remoteFS) ReadDir(name string) ([]fs.FileInfo, error) { return r.client.ReadDir(name) }
+func (r remoteFS) Open(name string) (io.ReadCloser, error)    { return r.client.Open(name) }
+func (r remoteFS) Create(name string) (io.WriteCloser, error) { return r.client.Create(name) }
+func (r remoteFS) Mkdir(name string) error                    { return r.client.Mkdir(name) }
+func (r remoteFS) Chmod(name string, mode fs.FileMode) error  { return r.client.Chmod(name, mode) }
+func (r remoteFS) Join(elem ...string) string                 { return path.Join(elem...) }
+func (r remoteFS) Base(name string) string                    { return path.Base(name) }
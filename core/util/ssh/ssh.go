@@ -1,10 +1,17 @@
 package ssh
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"net"
+	"sort"
+	"strings"
 	"time"
 
+	"github.com/pkg/sftp"
 	"golang.org/x/crypto/ssh"
 )
 
@@ -33,7 +40,7 @@ func NewSSHClient(host string, port int, user string, identityKey string) (*SSHC
 		Timeout:   60 * time.Second,
 		KeepAlive: 5 * time.Second,
 	}
-	conn, err := agentDialer.Dial("tcp", fmt.Sprintf("%s:%d", host, port))
+	conn, err := agentDialer.Dial("tcp", net.JoinHostPort(host, fmt.Sprint(port)))
 	if err != nil {
 		return nil, err
 	}
@@ -46,6 +53,14 @@ func NewSSHClient(host string, port int, user string, identityKey string) (*SSHC
 	// connect ot ssh server
 	clientConn, channelCh, reqCh, err := ssh.NewClientConn(conn, "tcp", config)
 	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	// the deadline only applies to the handshake
+	err = conn.SetDeadline(time.Time{})
+	if err != nil {
+		clientConn.Close()
 		return nil, err
 	}
 
@@ -58,30 +73,217 @@ func NewSSHClient(host string, port int, user string, identityKey string) (*SSHC
 	}, nil
 }
 
+// Run executes cmd and returns its output, an error is returned if the command
+// fails or does not finish within timeout
 func (c *SSHClient) Run(cmd string, timeout time.Duration) (string, error) {
-	outCh := make(chan string)
-	errCh := make(chan error)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	status, err := c.Exec(ctx, cmd, ExecOptions{
+		Stdout: &stdout,
+		Stderr: &stderr,
+	})
+	if errors.Is(err, context.DeadlineExceeded) {
+		return "", fmt.Errorf("Timeout after %s", timeout)
+	}
+	if err != nil {
+		return "", err
+	}
+	if status != 0 {
+		return stdout.String(), fmt.Errorf("command exited with status %d: %s", status, strings.TrimSpace(stderr.String()))
+	}
+
+	return stdout.String(), nil
+}
+
+type ExecOptions struct {
+	// Env are environment variables exported before running the command
+	Env map[string]string
+	// Stdin is the input of the command (optional)
+	Stdin io.Reader
+	// Stdout receives the standard output of the command (optional)
+	Stdout io.Writer
+	// Stderr receives the standard error of the command (optional)
+	Stderr io.Writer
+}
+
+// Exec runs cmd streaming its input and output, and returns its exit status.
+// The command is terminated if ctx is done before it exits
+func (c *SSHClient) Exec(ctx context.Context, cmd string, opts ExecOptions) (int, error) {
+	session, err := c.conn.NewSession()
+	if err != nil {
+		return -1, err
+	}
+	defer session.Close()
+
+	session.Stdin = opts.Stdin
+	session.Stdout = opts.Stdout
+	session.Stderr = opts.Stderr
+
+	err = session.Start(withEnv(cmd, opts.Env))
+	if err != nil {
+		return -1, err
+	}
+
+	done := make(chan error, 1)
 	go func() {
-		session, err := c.conn.NewSession()
-		if err != nil {
-			errCh <- err
-			return
-		}
-		out, err := session.Output(cmd)
-		if err != nil {
-			errCh <- err
-			return
-		}
+		done <- session.Wait()
+	}()
+
+	select {
+	case <-ctx.Done():
+		session.Signal(ssh.SIGTERM)
+		session.Close()
+		return -1, ctx.Err()
+	case err = <-done:
+	}
+
+	var exitErr *ssh.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitStatus(), nil
+	}
+	if err != nil {
+		return -1, err
+	}
+
+	return 0, nil
+}
+
+// withEnv prefixes cmd with the export of the given environment variables
+func withEnv(cmd string, env map[string]string) string {
+	if len(env) == 0 {
+		return cmd
+	}
+
+	keys := make([]string, 0, len(env))
+	for key := range env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	exports := make([]string, 0, len(env))
+	for _, key := range keys {
+		exports = append(exports, fmt.Sprintf("export %s=%s;", key, Quote(env[key])))
+	}
+
+	return fmt.Sprintf("%s %s", strings.Join(exports, " "), cmd)
+}
 
-		outCh <- string(out)
+// Quote quotes s to be used as a single word in a POSIX shell
+func Quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// Dial opens a connection to addr from the remote host
+func (c *SSHClient) Dial(network, addr string) (net.Conn, error) {
+	return c.conn.Dial(network, addr)
+}
+
+// IsRejected reports whether a Dial error was returned by the remote host
+// (e.g. nothing listens on the address), so the SSH connection itself works
+func IsRejected(err error) bool {
+	var openErr *ssh.OpenChannelError
+	return errors.As(err, &openErr)
+}
+
+// Alive checks if the connection answers a keepalive within timeout
+func (c *SSHClient) Alive(timeout time.Duration) bool {
+	// a half-open connection never answers, so the request is only waited
+	// for up to timeout
+	alive := make(chan bool, 1)
+	go func() {
+		_, _, err := c.conn.SendRequest("keepalive@openssh.com", true, nil)
+		alive <- err == nil
 	}()
 
 	select {
+	case ok := <-alive:
+		return ok
 	case <-time.After(timeout):
-		return "", fmt.Errorf("Timeout after %s", timeout)
-	case out := <-outCh:
-		return out, nil
-	case err := <-errCh:
-		return "", err
+		return false
 	}
 }
+
+// SFTP opens an SFTP session over the connection
+func (c *SSHClient) SFTP() (*sftp.Client, error) {
+	return sftp.NewClient(c.conn)
+}
+
+func (c *SSHClient) Close() error {
+	return c.conn.Close()
+}
+
+// WindowSize is the size of a terminal in columns (Width) and rows (Height)
+type WindowSize struct {
+	Width  int
+	Height int
+}
+
+type ShellOptions struct {
+	// Term is the terminal type (e.g. xterm-256color)
+	Term string
+	// Size is the initial size of the terminal
+	Size WindowSize
+	// Resize receives the new terminal size when it changes
+	Resize <-chan WindowSize
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+// Shell opens an interactive login shell on a PTY, blocks until it exits and
+// returns its exit status
+func (c *SSHClient) Shell(opts ShellOptions) (int, error) {
+	session, err := c.conn.NewSession()
+	if err != nil {
+		return -1, err
+	}
+	defer session.Close()
+
+	session.Stdin = opts.Stdin
+	session.Stdout = opts.Stdout
+	session.Stderr = opts.Stderr
+
+	modes := ssh.TerminalModes{
+		ssh.ECHO:          1,
+		ssh.TTY_OP_ISPEED: 14400,
+		ssh.TTY_OP_OSPEED: 14400,
+	}
+	err = session.RequestPty(opts.Term, opts.Size.Height, opts.Size.Width, modes)
+	if err != nil {
+		return -1, err
+	}
+
+	err = session.Shell()
+	if err != nil {
+		return -1, err
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case size, ok := <-opts.Resize:
+				if !ok {
+					return
+				}
+				session.WindowChange(size.Height, size.Width)
+			}
+		}
+	}()
+
+	err = session.Wait()
+	var exitErr *ssh.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitStatus(), nil
+	}
+	if err != nil {
+		return -1, err
+	}
+
+	return 0, nil
+}
@@ -1,6 +1,7 @@
 package util
 
 import (
+	"fmt"
 	"io"
 	"net/http"
 	"os"
@@ -105,6 +106,35 @@ func LoadYAML(filename string, config interface{}) (err error) {
 	return
 }
 
+// LoadEnvFile reads the KEY=VALUE lines of an env file, skipping blank lines
+// and comments. Values can be quoted
+func LoadEnvFile(filename string) (map[string]string, error) {
+	file, err := loadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	env := map[string]string{}
+	for i, line := range strings.Split(string(file), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		key, value, ok := strings.Cut(strings.TrimPrefix(line, "export "), "=")
+		if !ok {
+			return nil, fmt.Errorf("%s:%d: expected KEY=VALUE", filename, i+1)
+		}
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		env[strings.TrimSpace(key)] = value
+	}
+
+	return env, nil
+}
+
 func GetTemplateNameAndVersion(name string) (string, string) {
 	if name == "" {
 		return "", ""
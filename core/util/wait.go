@@ -0,0 +1,162 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+const (
+	DefaultMinInterval = 1 * time.Second
+	DefaultMaxInterval = 15 * time.Second
+)
+
+// TimeoutError is returned when a wait does not finish before its timeout or
+// the deadline of its context. It matches context.DeadlineExceeded with errors.Is
+type TimeoutError struct {
+	// Operation is what was being waited for
+	Operation string
+	// Elapsed is the time spent waiting
+	Elapsed time.Duration
+	// LastErr is the last error returned by a retried check, if any
+	LastErr error
+}
+
+func (e *TimeoutError) Error() string {
+	msg := fmt.Sprintf("timed out after %s waiting for %s", e.Elapsed.Round(time.Second), e.Operation)
+	if e.LastErr != nil {
+		msg += fmt.Sprintf(" (last error: %s)", e.LastErr)
+	}
+
+	return msg
+}
+
+func (e *TimeoutError) Unwrap() error {
+	return context.DeadlineExceeded
+}
+
+// RetryableError marks an error of a check as transient, so the wait goes on
+// instead of failing
+type RetryableError struct {
+	Err error
+}
+
+func (e *RetryableError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RetryableError) Unwrap() error {
+	return e.Err
+}
+
+// Retryable wraps err in a RetryableError
+func Retryable(err error) error {
+	return &RetryableError{Err: err}
+}
+
+// Waiter polls a condition with exponential backoff and jitter until it is
+// done, fails, times out or its context is cancelled
+type Waiter struct {
+	// Operation describes what is waited for, used in errors
+	Operation string
+	// Timeout bounds the wait, 0 means until the context is done
+	Timeout time.Duration
+	// MinInterval is the delay before the second check (defaults to DefaultMinInterval)
+	MinInterval time.Duration
+	// MaxInterval caps the delay between checks (defaults to DefaultMaxInterval)
+	MaxInterval time.Duration
+}
+
+// WaitFunc checks whether the wait is done. A RetryableError keeps waiting,
+// any other error stops the wait
+type WaitFunc func(ctx context.Context) (bool, error)
+
+// NewWaiter returns a Waiter with the default intervals
+func NewWaiter(operation string, timeout time.Duration) Waiter {
+	return Waiter{
+		Operation:   operation,
+		Timeout:     timeout,
+		MinInterval: DefaultMinInterval,
+		MaxInterval: DefaultMaxInterval,
+	}
+}
+
+// Wait calls check right away and then with growing delays until it is done.
+// It returns a TimeoutError when the timeout or the context deadline is
+// reached, and the context error when the context is cancelled
+func (w Waiter) Wait(ctx context.Context, check WaitFunc) error {
+	if w.MinInterval <= 0 {
+		w.MinInterval = DefaultMinInterval
+	}
+	if w.MaxInterval < w.MinInterval {
+		w.MaxInterval = w.MinInterval
+	}
+
+	start := time.Now()
+	if w.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, w.Timeout)
+		defer cancel()
+	}
+
+	var lastErr error
+	interval := w.MinInterval
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return w.contextError(ctx, start, lastErr)
+		case <-timer.C:
+		}
+
+		done, err := check(ctx)
+		if err != nil {
+			var retryable *RetryableError
+			if !errors.As(err, &retryable) {
+				// a check interrupted by the context fails with the context error
+				if ctx.Err() != nil {
+					return w.contextError(ctx, start, err)
+				}
+				return err
+			}
+			lastErr = retryable.Err
+		}
+		if done {
+			return nil
+		}
+
+		timer.Reset(jitter(interval))
+		interval = backoff(interval, w.MaxInterval)
+	}
+}
+
+func (w Waiter) contextError(ctx context.Context, start time.Time, lastErr error) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return &TimeoutError{
+			Operation: w.Operation,
+			Elapsed:   time.Since(start),
+			LastErr:   lastErr,
+		}
+	}
+
+	return fmt.Errorf("waiting for %s: %w", w.Operation, ctx.Err())
+}
+
+// backoff doubles the interval up to max
+func backoff(interval, max time.Duration) time.Duration {
+	interval *= 2
+	if interval > max {
+		return max
+	}
+
+	return interval
+}
+
+// jitter returns a random duration between d/2 and d
+func jitter(d time.Duration) time.Duration {
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
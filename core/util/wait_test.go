@@ -0,0 +1,166 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		name     string
+		interval time.Duration
+		max      time.Duration
+		want     time.Duration
+	}{
+		{"doubles the interval", time.Second, 15 * time.Second, 2 * time.Second},
+		{"caps at the max interval", 10 * time.Second, 15 * time.Second, 15 * time.Second},
+		{"stays at the max interval", 15 * time.Second, 15 * time.Second, 15 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := backoff(tt.interval, tt.max); got != tt.want {
+				t.Errorf("backoff(%s, %s) = %s, want %s", tt.interval, tt.max, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJitter(t *testing.T) {
+	for _, d := range []time.Duration{0, time.Nanosecond, time.Millisecond, 15 * time.Second} {
+		for i := 0; i < 100; i++ {
+			if got := jitter(d); got < d/2 || got > d {
+				t.Fatalf("jitter(%s) = %s, want between %s and %s", d, got, d/2, d)
+			}
+		}
+	}
+}
+
+func TestWaiter_Wait(t *testing.T) {
+	transient := errors.New("transient")
+	fatal := errors.New("fatal")
+
+	tests := []struct {
+		name      string
+		results   []error
+		doneAfter int
+		wantErr   error
+		wantCalls int
+	}{
+		{"done on the first check", nil, 1, nil, 1},
+		{"retries a retryable error", []error{Retryable(transient), Retryable(transient)}, 3, nil, 3},
+		{"stops on a non retryable error", []error{Retryable(transient), fatal}, 5, fatal, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := Waiter{Operation: "test", MinInterval: time.Millisecond, MaxInterval: 2 * time.Millisecond}
+			calls := 0
+			err := w.Wait(context.Background(), func(ctx context.Context) (bool, error) {
+				calls++
+				if calls <= len(tt.results) && tt.results[calls-1] != nil {
+					return false, tt.results[calls-1]
+				}
+
+				return calls >= tt.doneAfter, nil
+			})
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && err != nil) {
+				t.Errorf("Wait() = %v, want %v", err, tt.wantErr)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("Wait() checked %d times, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestWaiter_Wait_timeout(t *testing.T) {
+	transient := errors.New("transient")
+	w := Waiter{Operation: "test", Timeout: 20 * time.Millisecond, MinInterval: time.Millisecond, MaxInterval: 2 * time.Millisecond}
+
+	err := w.Wait(context.Background(), func(ctx context.Context) (bool, error) {
+		return false, Retryable(transient)
+	})
+
+	var timeoutErr *TimeoutError
+	if !errors.As(err, &timeoutErr) {
+		t.Fatalf("Wait() = %v, want a *TimeoutError", err)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Wait() = %v, want it to match context.DeadlineExceeded", err)
+	}
+	if timeoutErr.LastErr != transient {
+		t.Errorf("TimeoutError.LastErr = %v, want %v", timeoutErr.LastErr, transient)
+	}
+	if timeoutErr.Elapsed < w.Timeout {
+		t.Errorf("TimeoutError.Elapsed = %s, want at least %s", timeoutErr.Elapsed, w.Timeout)
+	}
+	if !strings.Contains(err.Error(), "waiting for test (last error: transient)") {
+		t.Errorf("Wait() error = %q, want the operation and the last error", err)
+	}
+}
+
+func TestWaiter_Wait_contextDeadline(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	w := Waiter{Operation: "test", MinInterval: time.Millisecond, MaxInterval: 2 * time.Millisecond}
+
+	err := w.Wait(ctx, func(ctx context.Context) (bool, error) {
+		return false, nil
+	})
+
+	var timeoutErr *TimeoutError
+	if !errors.As(err, &timeoutErr) {
+		t.Fatalf("Wait() = %v, want a *TimeoutError", err)
+	}
+	if timeoutErr.LastErr != nil {
+		t.Errorf("TimeoutError.LastErr = %v, want nil", timeoutErr.LastErr)
+	}
+}
+
+func TestWaiter_Wait_cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	w := Waiter{Operation: "test", MinInterval: time.Millisecond, MaxInterval: 2 * time.Millisecond}
+
+	calls := 0
+	err := w.Wait(ctx, func(ctx context.Context) (bool, error) {
+		calls++
+		if calls == 2 {
+			cancel()
+		}
+
+		return false, nil
+	})
+
+	var timeoutErr *TimeoutError
+	if errors.As(err, &timeoutErr) {
+		t.Errorf("Wait() = %v, want a cancellation error", err)
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Wait() = %v, want it to match context.Canceled", err)
+	}
+}
+
+func TestWaiter_Wait_backoff(t *testing.T) {
+	w := Waiter{Operation: "test", MinInterval: 10 * time.Millisecond, MaxInterval: 20 * time.Millisecond}
+
+	var checks []time.Time
+	err := w.Wait(context.Background(), func(ctx context.Context) (bool, error) {
+		checks = append(checks, time.Now())
+		return len(checks) == 4, nil
+	})
+	if err != nil {
+		t.Fatalf("Wait() = %v, want nil", err)
+	}
+
+	// the delays are jittered between half and all of 10ms, 20ms and 20ms
+	mins := []time.Duration{5 * time.Millisecond, 10 * time.Millisecond, 10 * time.Millisecond}
+	for i, min := range mins {
+		if gap := checks[i+1].Sub(checks[i]); gap < min {
+			t.Errorf("delay before check %d = %s, want at least %s", i+2, gap, min)
+		}
+	}
+}
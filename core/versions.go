@@ -0,0 +1,391 @@
+package core
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/felipemarinho97/dev-spaces/core/helpers"
+	"github.com/felipemarinho97/dev-spaces/core/util"
+)
+
+// TemplateVersion is a version of the launch template of a dev space
+type TemplateVersion struct {
+	// Name of the dev space
+	Name string `json:"name" yaml:"name"`
+	// Version number
+	Version int64 `json:"version" yaml:"version"`
+	// Default is true for the version used when the dev space is started without a version
+	Default bool `json:"default" yaml:"default"`
+	// Description of the version
+	Description string `json:"description" yaml:"description"`
+	// CreatedAt is when the version was created
+	CreatedAt time.Time `json:"created_at" yaml:"created_at"`
+	// CreatedBy is the ARN of the principal that created the version
+	CreatedBy string `json:"created_by" yaml:"created_by"`
+	// HostAMI is the image of the host
+	HostAMI string `json:"host_ami" yaml:"host_ami"`
+	// HostDevice is the root device of the host, e.g. "/dev/xvda 8 GB gp3"
+	HostDevice string `json:"host_device" yaml:"host_device"`
+	// KeyName is the name of the key pair
+	KeyName string `json:"key_name" yaml:"key_name"`
+	// SecurityGroupIds of the instances
+	SecurityGroupIds []string `json:"security_group_ids" yaml:"security_group_ids"`
+	// InstanceProfileArn of the instances
+	InstanceProfileArn string `json:"instance_profile_arn" yaml:"instance_profile_arn"`
+	// AvailabilityZone of the instances, the one of the volume
+	AvailabilityZone string `json:"availability_zone" yaml:"availability_zone"`
+	// Tags of the instances, without the ones managed by dev-spaces
+	Tags map[string]string `json:"tags" yaml:"tags"`
+	// StartupScript of the instances, decoded from the user data
+	StartupScript string `json:"startup_script" yaml:"startup_script"`
+}
+
+// fields returns the fields compared by DiffTemplateVersions, in order
+func (v TemplateVersion) fields() [][2]string {
+	return [][2]string{
+		{"host_ami", v.HostAMI},
+		{"host_device", v.HostDevice},
+		{"key_name", v.KeyName},
+		{"security_group_ids", joinSorted(v.SecurityGroupIds)},
+		{"instance_profile_arn", v.InstanceProfileArn},
+		{"availability_zone", v.AvailabilityZone},
+		{"startup_script", v.StartupScript},
+	}
+}
+
+type ListTemplateVersionsOptions struct {
+	// Name of the dev space
+	Name string `validate:"required"`
+}
+
+// ListTemplateVersions returns the launch template versions of a dev space,
+// newest first
+func (h *Handler) ListTemplateVersions(ctx context.Context, opts ListTemplateVersionsOptions) ([]TemplateVersion, error) {
+	err := util.Validator.Struct(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	name, _ := util.GetTemplateNameAndVersion(opts.Name)
+	template, err := helpers.GetLaunchTemplateByName(ctx, h.EC2Client, name)
+	if err != nil {
+		return nil, err
+	}
+
+	versions, err := helpers.GetLaunchTemplateVersions(ctx, h.EC2Client, *template.LaunchTemplateId)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []TemplateVersion
+	for _, version := range versions {
+		item, err := newTemplateVersion(name, version)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, item)
+	}
+
+	return out, nil
+}
+
+type ShowTemplateVersionOptions struct {
+	// Name of the dev space, with an optional version e.g. "my-space/3"
+	// (defaults to the default version)
+	Name string `validate:"required"`
+}
+
+// ShowTemplateVersion returns a launch template version of a dev space
+func (h *Handler) ShowTemplateVersion(ctx context.Context, opts ShowTemplateVersionOptions) (TemplateVersion, error) {
+	err := util.Validator.Struct(opts)
+	if err != nil {
+		return TemplateVersion{}, err
+	}
+
+	name, version := util.GetTemplateNameAndVersion(opts.Name)
+	template, err := helpers.GetLaunchTemplateByName(ctx, h.EC2Client, name)
+	if err != nil {
+		return TemplateVersion{}, err
+	}
+
+	return h.getTemplateVersion(ctx, template, version)
+}
+
+type DiffTemplateVersionsOptions struct {
+	// Name of the dev space
+	Name string `validate:"required"`
+	// From is the old version (defaults to the version before To)
+	From string
+	// To is the new version (defaults to the default version)
+	To string
+}
+
+type DiffTemplateVersionsOutput struct {
+	// Name of the dev space
+	Name string `json:"name" yaml:"name"`
+	// From is the old version
+	From int64 `json:"from" yaml:"from"`
+	// To is the new version
+	To int64 `json:"to" yaml:"to"`
+	// Changes from the old version to the new one
+	Changes []SpecChange `json:"changes" yaml:"changes"`
+}
+
+// DiffTemplateVersions compares two launch template versions of a dev space,
+// decoding their startup scripts
+func (h *Handler) DiffTemplateVersions(ctx context.Context, opts DiffTemplateVersionsOptions) (DiffTemplateVersionsOutput, error) {
+	err := util.Validator.Struct(opts)
+	if err != nil {
+		return DiffTemplateVersionsOutput{}, err
+	}
+
+	name, _ := util.GetTemplateNameAndVersion(opts.Name)
+	template, err := helpers.GetLaunchTemplateByName(ctx, h.EC2Client, name)
+	if err != nil {
+		return DiffTemplateVersionsOutput{}, err
+	}
+
+	to := opts.To
+	if to == "" {
+		to = "$Default"
+	}
+	newVersion, err := h.getTemplateVersion(ctx, template, to)
+	if err != nil {
+		return DiffTemplateVersionsOutput{}, err
+	}
+
+	var oldVersion TemplateVersion
+	if opts.From == "" {
+		oldVersion, err = h.previousTemplateVersion(ctx, template, newVersion.Version)
+	} else {
+		oldVersion, err = h.getTemplateVersion(ctx, template, opts.From)
+	}
+	if err != nil {
+		return DiffTemplateVersionsOutput{}, err
+	}
+
+	var changes []SpecChange
+	diff := func(field, old, new string) {
+		if old != new {
+			changes = append(changes, SpecChange{Field: field, Old: old, New: new})
+		}
+	}
+	newFields := newVersion.fields()
+	for i, field := range oldVersion.fields() {
+		diff(field[0], field[1], newFields[i][1])
+	}
+	for _, key := range sortedKeys(oldVersion.Tags, newVersion.Tags) {
+		diff("tags."+key, oldVersion.Tags[key], newVersion.Tags[key])
+	}
+
+	return DiffTemplateVersionsOutput{
+		Name:    name,
+		From:    oldVersion.Version,
+		To:      newVersion.Version,
+		Changes: changes,
+	}, nil
+}
+
+type SetDefaultTemplateVersionOptions struct {
+	// Name of the dev space, with the version e.g. "my-space/3" or "my-space/$Latest"
+	Name string `validate:"required"`
+}
+
+// SetDefaultTemplateVersion sets the launch template version used when the
+// dev space is started without a version
+func (h *Handler) SetDefaultTemplateVersion(ctx context.Context, opts SetDefaultTemplateVersionOptions) (TemplateVersion, error) {
+	err := util.Validator.Struct(opts)
+	if err != nil {
+		return TemplateVersion{}, err
+	}
+
+	name, version := util.GetTemplateNameAndVersion(opts.Name)
+	if version == "" || version == "$Default" {
+		return TemplateVersion{}, fmt.Errorf("missing version, e.g. %s/2", name)
+	}
+	template, err := helpers.GetLaunchTemplateByName(ctx, h.EC2Client, name)
+	if err != nil {
+		return TemplateVersion{}, err
+	}
+
+	item, err := h.getTemplateVersion(ctx, template, version)
+	if err != nil {
+		return TemplateVersion{}, err
+	}
+
+	return h.setDefaultTemplateVersion(ctx, template, item)
+}
+
+type RollbackTemplateVersionOptions struct {
+	// Name of the dev space
+	Name string `validate:"required"`
+}
+
+// RollbackTemplateVersion sets the version before the default one as the
+// default launch template version
+func (h *Handler) RollbackTemplateVersion(ctx context.Context, opts RollbackTemplateVersionOptions) (TemplateVersion, error) {
+	err := util.Validator.Struct(opts)
+	if err != nil {
+		return TemplateVersion{}, err
+	}
+
+	name, _ := util.GetTemplateNameAndVersion(opts.Name)
+	template, err := helpers.GetLaunchTemplateByName(ctx, h.EC2Client, name)
+	if err != nil {
+		return TemplateVersion{}, err
+	}
+
+	previous, err := h.previousTemplateVersion(ctx, template, *template.DefaultVersionNumber)
+	if err != nil {
+		return TemplateVersion{}, err
+	}
+
+	return h.setDefaultTemplateVersion(ctx, template, previous)
+}
+
+type PruneTemplateVersionsOptions struct {
+	// Name of the dev space
+	Name string `validate:"required"`
+	// Keep is the number of most recent versions to keep, besides the default one
+	Keep int `validate:"min=0"`
+}
+
+// PruneTemplateVersions deletes all but the most recent Keep launch template
+// versions of a dev space, and returns the deleted versions. The default
+// version is always kept
+func (h *Handler) PruneTemplateVersions(ctx context.Context, opts PruneTemplateVersionsOptions) ([]TemplateVersion, error) {
+	err := util.Validator.Struct(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	versions, err := h.ListTemplateVersions(ctx, ListTemplateVersionsOptions{Name: opts.Name})
+	if err != nil {
+		return nil, err
+	}
+	if len(versions) <= opts.Keep {
+		return nil, nil
+	}
+
+	var pruned []TemplateVersion
+	var numbers []int64
+	for _, version := range versions[opts.Keep:] {
+		if version.Default {
+			continue
+		}
+		pruned = append(pruned, version)
+		numbers = append(numbers, version.Version)
+	}
+	if len(pruned) == 0 {
+		return nil, nil
+	}
+
+	name, _ := util.GetTemplateNameAndVersion(opts.Name)
+	template, err := helpers.GetLaunchTemplateByName(ctx, h.EC2Client, name)
+	if err != nil {
+		return nil, err
+	}
+
+	h.Logger.Info(fmt.Sprintf("Deleting %d launch template versions...", len(numbers)))
+	err = helpers.DeleteLaunchTemplateVersions(ctx, h.EC2Client, *template.LaunchTemplateId, numbers)
+	if err != nil {
+		return nil, err
+	}
+
+	return pruned, nil
+}
+
+// getTemplateVersion returns a launch template version, version can be a
+// number, $Latest or $Default
+func (h *Handler) getTemplateVersion(ctx context.Context, template *types.LaunchTemplate, version string) (TemplateVersion, error) {
+	versions, err := helpers.GetLaunchTemplateVersions(ctx, h.EC2Client, *template.LaunchTemplateId, version)
+	if err != nil {
+		return TemplateVersion{}, err
+	}
+	if len(versions) == 0 {
+		return TemplateVersion{}, fmt.Errorf("version %s of %s not found", version, *template.LaunchTemplateName)
+	}
+
+	return newTemplateVersion(*template.LaunchTemplateName, versions[0])
+}
+
+// previousTemplateVersion returns the newest launch template version older
+// than version
+func (h *Handler) previousTemplateVersion(ctx context.Context, template *types.LaunchTemplate, version int64) (TemplateVersion, error) {
+	versions, err := helpers.GetLaunchTemplateVersions(ctx, h.EC2Client, *template.LaunchTemplateId)
+	if err != nil {
+		return TemplateVersion{}, err
+	}
+
+	// versions are sorted newest first
+	for _, v := range versions {
+		if *v.VersionNumber < version {
+			return newTemplateVersion(*template.LaunchTemplateName, v)
+		}
+	}
+
+	return TemplateVersion{}, fmt.Errorf("no version of %s before version %d", *template.LaunchTemplateName, version)
+}
+
+func (h *Handler) setDefaultTemplateVersion(ctx context.Context, template *types.LaunchTemplate, version TemplateVersion) (TemplateVersion, error) {
+	if version.Default {
+		h.Logger.Info(fmt.Sprintf("Version %d is already the default version of %s", version.Version, version.Name))
+		return version, nil
+	}
+
+	err := helpers.SetDefaultLaunchTemplateVersion(ctx, h.EC2Client, *template.LaunchTemplateId, version.Version)
+	if err != nil {
+		return TemplateVersion{}, err
+	}
+	version.Default = true
+
+	return version, nil
+}
+
+func newTemplateVersion(name string, version types.LaunchTemplateVersion) (TemplateVersion, error) {
+	item := TemplateVersion{
+		Name:        name,
+		Version:     aws.ToInt64(version.VersionNumber),
+		Default:     aws.ToBool(version.DefaultVersion),
+		Description: util.GetValue(version.VersionDescription),
+		CreatedAt:   aws.ToTime(version.CreateTime),
+		CreatedBy:   util.GetValue(version.CreatedBy),
+		Tags:        map[string]string{},
+	}
+
+	data := version.LaunchTemplateData
+	if data == nil {
+		return item, nil
+	}
+
+	item.HostAMI = util.GetValue(data.ImageId)
+	item.KeyName = util.GetValue(data.KeyName)
+	item.SecurityGroupIds = data.SecurityGroupIds
+	if data.IamInstanceProfile != nil {
+		item.InstanceProfileArn = util.GetValue(data.IamInstanceProfile.Arn)
+	}
+	if data.Placement != nil {
+		item.AvailabilityZone = util.GetValue(data.Placement.AvailabilityZone)
+	}
+	if len(data.BlockDeviceMappings) > 0 && data.BlockDeviceMappings[0].Ebs != nil {
+		mapping := data.BlockDeviceMappings[0]
+		item.HostDevice = fmt.Sprintf("%s %d GB %s", util.GetValue(mapping.DeviceName), aws.ToInt32(mapping.Ebs.VolumeSize), mapping.Ebs.VolumeType)
+	}
+	for _, spec := range data.TagSpecifications {
+		if spec.ResourceType == types.ResourceTypeInstance {
+			item.Tags = specTags(spec.Tags)
+		}
+	}
+
+	script, err := base64.StdEncoding.DecodeString(util.GetValue(data.UserData))
+	if err != nil {
+		return TemplateVersion{}, fmt.Errorf("error decoding the user data of version %d: %v", item.Version, err)
+	}
+	item.StartupScript = string(script)
+
+	return item, nil
+}